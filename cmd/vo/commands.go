@@ -0,0 +1,318 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/victorops/go-victorops/internal/apierror"
+	"github.com/victorops/go-victorops/victorops"
+)
+
+var commands = []command{
+	{name: "users list", help: "list the users of the org", run: usersList},
+	{name: "users get", args: "<username>", help: "show a user", minArgs: 1, maxArgs: 1, run: usersGet},
+	{name: "users teams", args: "<username>", help: "list the teams of a user", minArgs: 1, maxArgs: 1, run: usersTeams},
+	{name: "teams list", help: "list the teams of the org", run: teamsList},
+	{name: "teams members", args: "<slug>", help: "list the members of a team", minArgs: 1, maxArgs: 1, run: teamsMembers},
+	{name: "teams admins", args: "<slug>", help: "list the admins of a team", minArgs: 1, maxArgs: 1, run: teamsAdmins},
+	{name: "teams policies", args: "<slug>", help: "list the escalation policies of a team", minArgs: 1, maxArgs: 1, run: teamsPolicies},
+	{name: "oncall who", args: "<team>", help: "show who is on call for a team", minArgs: 1, maxArgs: 1, run: oncallWho},
+	{name: "top", args: "[team...]", help: "show a live dashboard of incidents and on-call", maxArgs: -1, run: top},
+	{name: "incidents list", help: "list open and recently resolved incidents", run: incidentsList},
+	{name: "incidents get", args: "<n>", help: "show an incident", minArgs: 1, maxArgs: 1, run: incidentsGet},
+	{name: "incidents ack", args: "<n>...", help: "acknowledge incidents", minArgs: 1, maxArgs: -1, run: incidentsAck},
+	{name: "incidents resolve", args: "<n>...", help: "resolve incidents", minArgs: 1, maxArgs: -1, run: incidentsResolve},
+	{name: "policies list", help: "list the escalation policies of the org", run: policiesList},
+	{name: "policies get", args: "<slug>", help: "show the steps of an escalation policy", minArgs: 1, maxArgs: 1, run: policiesGet},
+	{name: "routing-keys list", help: "list the routing keys of the org", run: routingKeysList},
+	{name: "routing-keys get", args: "<key>", help: "show a routing key", minArgs: 1, maxArgs: 1, run: routingKeysGet},
+	{name: "snapshot export", help: "write a snapshot of the org, to the -f file if set", run: snapshotExport},
+	{name: "diff", args: "<old> [new]", help: "compare two snapshots, or a snapshot and the org", minArgs: 1, maxArgs: 2, offline: true, run: diff},
+	{name: "restore", args: "<snapshot>", help: "recreate what a snapshot has and the org is missing", minArgs: 1, maxArgs: 1, run: restore},
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func usersList(e *env, args []string) (*result, error) {
+	users, details, err := e.client.GetAllUserV2()
+	if err := apierror.Check(details, err); err != nil {
+		return nil, err
+	}
+
+	r := result{value: users, header: []string{"USERNAME", "FIRST NAME", "LAST NAME", "EMAIL", "ADMIN"}}
+	for _, user := range users.Users {
+		r.row(user.Username, user.FirstName, user.LastName, user.Email, strconv.FormatBool(user.Admin))
+	}
+	return &r, nil
+}
+
+func usersGet(e *env, args []string) (*result, error) {
+	user, details, err := e.client.GetUser(args[0])
+	if err := apierror.Check(details, err); err != nil {
+		return nil, err
+	}
+
+	r := result{value: user, header: []string{"USERNAME", "FIRST NAME", "LAST NAME", "EMAIL", "ADMIN", "CREATED"}}
+	r.row(user.Username, user.FirstName, user.LastName, user.Email, strconv.FormatBool(user.Admin), user.CreatedAt)
+	return &r, nil
+}
+
+func teamsResult(teams *[]victorops.Team) *result {
+	r := result{value: teams, header: []string{"NAME", "SLUG", "MEMBERS", "DEFAULT"}}
+	for _, team := range *teams {
+		r.row(team.Name, team.Slug, strconv.Itoa(team.MemberCount), strconv.FormatBool(team.IsDefaultTeam))
+	}
+	return &r
+}
+
+func usersTeams(e *env, args []string) (*result, error) {
+	teams, details, err := e.client.GetUserTeams(args[0])
+	if err := apierror.Check(details, err); err != nil {
+		return nil, err
+	}
+	return teamsResult(teams), nil
+}
+
+func teamsList(e *env, args []string) (*result, error) {
+	teams, details, err := e.client.GetAllTeams()
+	if err := apierror.Check(details, err); err != nil {
+		return nil, err
+	}
+	return teamsResult(teams), nil
+}
+
+func teamsMembers(e *env, args []string) (*result, error) {
+	members, details, err := e.client.GetTeamMembers(args[0])
+	if err := apierror.Check(details, err); err != nil {
+		return nil, err
+	}
+
+	r := result{value: members, header: []string{"USERNAME", "FIRST NAME", "LAST NAME"}}
+	for _, member := range members.Members {
+		r.row(member.Username, member.FirstName, member.LastName)
+	}
+	return &r, nil
+}
+
+func teamsAdmins(e *env, args []string) (*result, error) {
+	admins, details, err := e.client.GetTeamAdmins(args[0])
+	if err := apierror.Check(details, err); err != nil {
+		return nil, err
+	}
+
+	r := result{value: admins, header: []string{"USERNAME", "FIRST NAME", "LAST NAME"}}
+	for _, admin := range admins.TeamAdmins {
+		r.row(admin.Username, admin.FirstName, admin.LastName)
+	}
+	return &r, nil
+}
+
+func teamsPolicies(e *env, args []string) (*result, error) {
+	policies, details, err := e.client.GetTeamEscalationPolicies(args[0])
+	if err := apierror.Check(details, err); err != nil {
+		return nil, err
+	}
+
+	r := result{value: policies, header: []string{"NAME", "SLUG"}}
+	for _, policy := range policies.Policies {
+		r.row(policy.Name, policy.Slug)
+	}
+	return &r, nil
+}
+
+func oncallWho(e *env, args []string) (*result, error) {
+	schedule, details, err := e.client.GetApiTeamSchedule(args[0], 0, 0, 0)
+	if err := apierror.Check(details, err); err != nil {
+		return nil, err
+	}
+
+	r := result{value: schedule, header: []string{"POLICY", "ROTATION", "SHIFT", "ON CALL", "UNTIL"}}
+	for _, policy := range schedule.Schedules {
+		for _, entry := range policy.Schedule {
+			user := entry.OnCallUser.Username
+			if entry.OverrideOnCallUser.Username != "" {
+				user = fmt.Sprintf("%s (overriding %s)", entry.OverrideOnCallUser.Username, user)
+			}
+			r.row(policy.Policy.Name, entry.RotationName, entry.ShiftName, user, formatTime(entry.ShiftRoll))
+		}
+	}
+	return &r, nil
+}
+
+func incidentsList(e *env, args []string) (*result, error) {
+	incidents, details, err := e.client.GetIncidents()
+	if err := apierror.Check(details, err); err != nil {
+		return nil, err
+	}
+
+	r := result{value: incidents, header: []string{"NUMBER", "PHASE", "STARTED", "SERVICE", "TEAMS"}}
+	for _, incident := range incidents.Incidents {
+		var teams []string
+		for _, policy := range incident.PagedPolicies {
+			teams = append(teams, policy.Team.Name)
+		}
+		r.row(incident.IncidentNumber, incident.CurrentPhase, formatTime(incident.StartTime), incident.Service, strings.Join(teams, ", "))
+	}
+	return &r, nil
+}
+
+func parseIncidentNumbers(args []string) ([]int, error) {
+	var numbers []int
+	for _, arg := range args {
+		number, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid incident number %q", arg)
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers, nil
+}
+
+func incidentsGet(e *env, args []string) (*result, error) {
+	numbers, err := parseIncidentNumbers(args)
+	if err != nil {
+		return nil, err
+	}
+	incident, details, err := e.client.GetIncident(numbers[0])
+	if err := apierror.Check(details, err); err != nil {
+		return nil, err
+	}
+
+	r := result{value: incident, header: []string{"TIME", "TRANSITION", "BY", "MESSAGE"}}
+	for _, transition := range incident.Transitions {
+		r.row(formatTime(transition.At), transition.Name, transition.By, transition.Message)
+	}
+	return &r, nil
+}
+
+// actingUser returns the user incidents are acknowledged and resolved as
+func (e *env) actingUser() (string, error) {
+	if e.user != "" {
+		return e.user, nil
+	}
+	if e.profile.Username != "" {
+		return e.profile.Username, nil
+	}
+	return "", fmt.Errorf("no user to act as: set username in the profile or pass -user")
+}
+
+type updateIncidents func(username string, incidentNumbers []int, message string) (*victorops.IncidentUpdateResponse, *victorops.RequestDetails, error)
+
+func runIncidentUpdate(e *env, args []string, update updateIncidents) (*result, error) {
+	numbers, err := parseIncidentNumbers(args)
+	if err != nil {
+		return nil, err
+	}
+	username, err := e.actingUser()
+	if err != nil {
+		return nil, err
+	}
+
+	response, details, err := update(username, numbers, e.message)
+	if err := apierror.Check(details, err); err != nil {
+		return nil, err
+	}
+
+	r := result{value: response, header: []string{"NUMBER", "ACCEPTED", "MESSAGE"}}
+	for _, res := range response.Results {
+		r.row(res.IncidentNumber, strconv.FormatBool(res.CmdAccepted), res.Message)
+	}
+	return &r, nil
+}
+
+func incidentsAck(e *env, args []string) (*result, error) {
+	return runIncidentUpdate(e, args, e.client.AckIncidents)
+}
+
+func incidentsResolve(e *env, args []string) (*result, error) {
+	return runIncidentUpdate(e, args, e.client.ResolveIncidents)
+}
+
+func policiesList(e *env, args []string) (*result, error) {
+	policies, details, err := e.client.GetAllEscalationPolicies()
+	if err := apierror.Check(details, err); err != nil {
+		return nil, err
+	}
+
+	r := result{value: policies, header: []string{"TEAM", "NAME", "SLUG"}}
+	for _, element := range policies.Policies {
+		r.row(element.Team.Name, element.Policy.Name, element.Policy.Slug)
+	}
+	return &r, nil
+}
+
+func describeEntry(entry victorops.EscalationPolicyStepEntry) string {
+	switch {
+	case entry.User != nil:
+		return entry.User.Username
+	case entry.RotationGroup != nil:
+		if entry.RotationGroup.Label != "" {
+			return entry.RotationGroup.Label
+		}
+		return entry.RotationGroup.Slug
+	case entry.Webhook != nil:
+		return entry.Webhook.Slug
+	case entry.Email != nil:
+		return entry.Email.Address
+	case entry.TargetPolicy != nil:
+		return entry.TargetPolicy.PolicySlug
+	}
+	return ""
+}
+
+func policiesGet(e *env, args []string) (*result, error) {
+	policy, details, err := e.client.GetEscalationPolicy(args[0])
+	if err := apierror.Check(details, err); err != nil {
+		return nil, err
+	}
+
+	r := result{value: policy, header: []string{"STEP", "AFTER", "TYPE", "TARGET"}}
+	for i, step := range policy.Steps {
+		for _, entry := range step.Entries {
+			r.row(strconv.Itoa(i+1), fmt.Sprintf("%dm", step.Timeout), string(entry.ExecutionType), describeEntry(entry))
+		}
+	}
+	return &r, nil
+}
+
+func routingKeysResult(value interface{}, keys []victorops.RoutingKey) *result {
+	r := result{value: value, header: []string{"ROUTING KEY", "TARGETS", "DEFAULT"}}
+	for _, key := range keys {
+		var targets []string
+		for _, target := range key.Targets {
+			if target.PolicyName != "" {
+				targets = append(targets, target.PolicyName)
+			} else {
+				targets = append(targets, target.PolicySlug)
+			}
+		}
+		r.row(key.RoutingKey, strings.Join(targets, ", "), strconv.FormatBool(key.IsDefault))
+	}
+	return &r
+}
+
+func routingKeysList(e *env, args []string) (*result, error) {
+	keys, details, err := e.client.GetAllRoutingKeys()
+	if err := apierror.Check(details, err); err != nil {
+		return nil, err
+	}
+	return routingKeysResult(keys, keys.RoutingKeys), nil
+}
+
+func routingKeysGet(e *env, args []string) (*result, error) {
+	key, details, err := e.client.GetRoutingKey(args[0])
+	if err := apierror.Check(details, err); err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, fmt.Errorf("routing key %s not found", args[0])
+	}
+	return routingKeysResult(key, []victorops.RoutingKey{*key}), nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/victorops/go-victorops/internal/yamljson"
+)
+
+const defaultAPIURL = "https://api.victorops.com"
+
+// Profile holds the credentials of an org
+type Profile struct {
+	APIID  string `json:"apiId"`
+	APIKey string `json:"apiKey"`
+	URL    string `json:"url,omitempty"`
+	// Username is the user incidents are acknowledged and resolved as
+	Username string `json:"username,omitempty"`
+}
+
+// Config is the vo configuration file, by default ~/.config/vo/config.yaml:
+//
+//	default: prod
+//	profiles:
+//	  prod:
+//	    apiId: ...
+//	    apiKey: ...
+//	    username: janedoe
+//	  staging:
+//	    apiId: ...
+//	    apiKey: ...
+//	    url: https://api.staging.example.com
+type Config struct {
+	Default  string             `json:"default,omitempty"`
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+}
+
+// configPath returns the path of the configuration file, which VO_CONFIG overrides
+func configPath() (string, error) {
+	if path := os.Getenv("VO_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "vo", "config.yaml"), nil
+}
+
+func loadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	err = yamljson.Unmarshal(data, &config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// resolveProfile returns the named profile, or VO_PROFILE, or the default one. The
+// VO_API_ID, VO_API_KEY, VO_API_URL and VO_USERNAME environment variables override the
+// profile, and are enough on their own when there is no configuration file.
+func resolveProfile(config *Config, name string) (*Profile, error) {
+	if name == "" {
+		name = os.Getenv("VO_PROFILE")
+	}
+	if name == "" {
+		name = config.Default
+	}
+
+	var profile Profile
+	if name != "" {
+		p, ok := config.Profiles[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %q", name)
+		}
+		profile = p
+	}
+
+	overrides := map[string]*string{
+		"VO_API_ID":   &profile.APIID,
+		"VO_API_KEY":  &profile.APIKey,
+		"VO_API_URL":  &profile.URL,
+		"VO_USERNAME": &profile.Username,
+	}
+	for env, field := range overrides {
+		if value := os.Getenv(env); value != "" {
+			*field = value
+		}
+	}
+
+	if profile.APIID == "" || profile.APIKey == "" {
+		return nil, fmt.Errorf("no credentials: add a profile to the configuration file or set VO_API_ID and VO_API_KEY")
+	}
+	if profile.URL == "" {
+		profile.URL = defaultAPIURL
+	}
+	return &profile, nil
+}
@@ -0,0 +1,183 @@
+// Command vo is a command-line client for the VictorOps public API.
+//
+//	vo [flags] <command> [args]
+//
+// Run vo without arguments for the list of commands. Credentials are read from a profile
+// of the configuration file (see Config) or from the VO_API_ID and VO_API_KEY environment
+// variables.
+//
+// vo snapshot export writes the snapshots of the org that vo diff compares and vo restore
+// recreates, e.g. vo snapshot export -f org.yaml.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/victorops/go-victorops/victorops"
+)
+
+// env is what commands run with
+type env struct {
+	client      *victorops.Client
+	profile     *Profile
+	profileName string
+	stdin       io.Reader
+	stdout      io.Writer
+	output      string
+	// status, when set, is the exit status of the command instead of 0 on success and 1
+	// on errors
+	status int
+
+	// Flags used by some commands
+	message  string
+	user     string
+	interval time.Duration
+	dryRun   bool
+	file     string
+}
+
+// connect creates the client from the selected profile of the configuration file
+func (e *env) connect() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	config, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+	e.profile, err = resolveProfile(config, e.profileName)
+	if err != nil {
+		return err
+	}
+	e.client = victorops.NewClient(e.profile.APIID, e.profile.APIKey, e.profile.URL)
+	return nil
+}
+
+// command is a subcommand of vo, named by one or more words such as "users list"
+type command struct {
+	name    string
+	args    string
+	help    string
+	minArgs int
+	// maxArgs is -1 for commands taking any number of arguments
+	maxArgs int
+	// offline commands are run without a client and call env.connect if they need one
+	offline bool
+	// run returns nil for commands that write to stdout themselves. The result is written
+	// even when there is an error.
+	run func(e *env, args []string) (*result, error)
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// parseArgs parses flags wherever they appear, returning the other arguments in order
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		err := fs.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// findCommand returns the command named by the first words of args, and the remaining args
+func findCommand(args []string) (*command, []string) {
+	for i := range commands {
+		words := strings.Fields(commands[i].name)
+		if len(args) < len(words) {
+			continue
+		}
+		if strings.Join(args[:len(words)], " ") == commands[i].name {
+			return &commands[i], args[len(words):]
+		}
+	}
+	return nil, nil
+}
+
+func usage(w io.Writer, fs *flag.FlagSet) {
+	fmt.Fprintln(w, "usage: vo [flags] <command> [args]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-36s %s\n", strings.TrimSpace(c.name+" "+c.args), c.help)
+	}
+	fmt.Fprintln(w, "\nFlags:")
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	e := env{stdin: stdin, stdout: stdout}
+	fs := flag.NewFlagSet("vo", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&e.profileName, "profile", "", "profile of the configuration file to use")
+	fs.StringVar(&e.output, "output", outputTable, "output format: table, json or yaml")
+	fs.StringVar(&e.output, "o", outputTable, "shorthand for -output")
+	fs.StringVar(&e.message, "message", "", "message recorded when acknowledging or resolving incidents")
+	fs.StringVar(&e.user, "user", "", "user to acknowledge or resolve incidents as, instead of the profile's")
+	fs.DurationVar(&e.interval, "interval", 5*time.Second, "how often top refreshes")
+	fs.BoolVar(&e.dryRun, "dry-run", false, "print what restore would do without changing the org")
+	fs.StringVar(&e.file, "f", "", "file snapshot export writes to instead of stdout")
+	fs.Usage = func() { usage(stderr, fs) }
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return 2
+	}
+
+	if e.output != outputTable && e.output != outputJSON && e.output != outputYAML {
+		fmt.Fprintf(stderr, "unknown output format %q, expected table, json or yaml\n", e.output)
+		return 2
+	}
+
+	c, cmdArgs := findCommand(positional)
+	if c == nil {
+		if len(positional) > 0 {
+			fmt.Fprintf(stderr, "unknown command %q\n\n", strings.Join(positional, " "))
+		}
+		usage(stderr, fs)
+		return 2
+	}
+	if len(cmdArgs) < c.minArgs || (c.maxArgs >= 0 && len(cmdArgs) > c.maxArgs) {
+		fmt.Fprintf(stderr, "usage: vo %s %s\n", c.name, c.args)
+		return 2
+	}
+
+	if !c.offline {
+		err = e.connect()
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	}
+
+	// Commands that fail part way may still return what they did
+	res, err := c.run(&e, cmdArgs)
+	if res != nil {
+		writeErr := res.write(stdout, e.output)
+		if err == nil {
+			err = writeErr
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		if e.status != 0 {
+			return e.status
+		}
+		return 1
+	}
+	return e.status
+}
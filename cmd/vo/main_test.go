@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupServer starts a fake API and points vo at it through the environment
+func setupServer(t *testing.T, handler http.Handler) {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	dir, err := ioutil.TempDir("", "vo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	env := map[string]string{
+		"VO_CONFIG":   filepath.Join(dir, "config.yaml"),
+		"VO_API_ID":   "id",
+		"VO_API_KEY":  "key",
+		"VO_API_URL":  server.URL,
+		"VO_USERNAME": "",
+		"VO_PROFILE":  "",
+	}
+	for name, value := range env {
+		previous, set := os.LookupEnv(name)
+		os.Setenv(name, value)
+		name := name
+		t.Cleanup(func() {
+			if set {
+				os.Setenv(name, previous)
+			} else {
+				os.Unsetenv(name)
+			}
+		})
+	}
+}
+
+func runVo(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(""), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func usersHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api-public/v2/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-VO-Api-Id") != "id" || r.Header.Get("X-VO-Api-Key") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"users":[{"username":"alice","firstName":"Alice","lastName":"Smith","email":"alice@example.com","admin":true}]}`)
+	})
+	mux.HandleFunc("/api-public/v1/user/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":"User not found"}`)
+	})
+	return mux
+}
+
+func TestUsersList(t *testing.T) {
+	setupServer(t, usersHandler())
+
+	code, stdout, stderr := runVo("users", "list")
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	want := "USERNAME  FIRST NAME  LAST NAME  EMAIL              ADMIN\n" +
+		"alice     Alice       Smith      alice@example.com  true\n"
+	if stdout != want {
+		t.Errorf("got\n%s\nwant\n%s", stdout, want)
+	}
+
+	code, stdout, _ = runVo("users", "list", "-o", "json")
+	var users struct {
+		Users []struct {
+			Username string `json:"username"`
+		} `json:"users"`
+	}
+	if code != 0 || json.Unmarshal([]byte(stdout), &users) != nil || len(users.Users) != 1 || users.Users[0].Username != "alice" {
+		t.Errorf("unexpected json output %q", stdout)
+	}
+
+	code, stdout, _ = runVo("-output", "yaml", "users", "list")
+	if code != 0 || !strings.Contains(stdout, "username: alice") {
+		t.Errorf("unexpected yaml output %q", stdout)
+	}
+}
+
+func TestIncidentsAck(t *testing.T) {
+	var body map[string]interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api-public/v1/incidents/ack", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" {
+			t.Errorf("request method %s, want PATCH", r.Method)
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprint(w, `{"results":[{"incidentNumber":"12","entityId":"e","cmdAccepted":true,"message":"ok"},{"incidentNumber":"13","entityId":"f","cmdAccepted":false,"message":"already acked"}]}`)
+	})
+	setupServer(t, mux)
+
+	code, _, stderr := runVo("incidents", "ack", "12")
+	if code != 1 || !strings.Contains(stderr, "no user to act as") {
+		t.Errorf("acking without a user exited %d: %s", code, stderr)
+	}
+
+	code, stdout, stderr := runVo("incidents", "ack", "12", "13", "-user", "alice", "-message", "on it")
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	if body["userName"] != "alice" || body["message"] != "on it" || fmt.Sprint(body["incidentNames"]) != "[12 13]" {
+		t.Errorf("unexpected request %v", body)
+	}
+	want := "NUMBER  ACCEPTED  MESSAGE\n" +
+		"12      true      ok\n" +
+		"13      false     already acked\n"
+	if stdout != want {
+		t.Errorf("got\n%s\nwant\n%s", stdout, want)
+	}
+}
+
+func TestRoutingKeysGet(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api-public/v1/org/routing-keys", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"routingKeys":[{"routingKey":"ops","targets":[{"policyName":"Primary","policySlug":"pol-1"}],"isDefault":false}]}`)
+	})
+	setupServer(t, mux)
+
+	code, stdout, stderr := runVo("routing-keys", "get", "ops")
+	want := "ROUTING KEY  TARGETS  DEFAULT\n" +
+		"ops          Primary  false\n"
+	if code != 0 || stdout != want {
+		t.Errorf("exited %d with\n%s\nwant\n%s%s", code, stdout, want, stderr)
+	}
+
+	code, _, stderr = runVo("routing-keys", "get", "missing")
+	if code != 1 || !strings.Contains(stderr, "routing key missing not found") {
+		t.Errorf("getting a missing routing key exited %d: %s", code, stderr)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	setupServer(t, usersHandler())
+
+	for _, test := range []struct {
+		args []string
+		code int
+		err  string
+	}{
+		{[]string{"users", "frobnicate"}, 2, `unknown command "users frobnicate"`},
+		{[]string{"teams", "members"}, 2, "usage: vo teams members <slug>"},
+		{[]string{"users", "list", "-o", "xml"}, 2, `unknown output format "xml"`},
+		{[]string{"incidents", "get", "abc"}, 1, `invalid incident number "abc"`},
+		{[]string{"users", "list", "-profile", "prod"}, 1, `unknown profile "prod"`},
+		{[]string{"users", "get", "bob"}, 1, "API returned 404"},
+	} {
+		code, _, stderr := runVo(test.args...)
+		if code != test.code || !strings.Contains(stderr, test.err) {
+			t.Errorf("vo %s exited %d with %q, want %d with %q", strings.Join(test.args, " "), code, stderr, test.code, test.err)
+		}
+	}
+}
+
+func TestResolveProfile(t *testing.T) {
+	setupServer(t, http.NotFoundHandler())
+	os.Unsetenv("VO_API_ID")
+	os.Unsetenv("VO_API_KEY")
+	os.Unsetenv("VO_API_URL")
+
+	config := &Config{
+		Default: "prod",
+		Profiles: map[string]Profile{
+			"prod":    {APIID: "prod-id", APIKey: "prod-key", Username: "alice"},
+			"staging": {APIID: "staging-id", APIKey: "staging-key", URL: "https://staging.example.com"},
+		},
+	}
+
+	profile, err := resolveProfile(config, "")
+	if err != nil || profile.APIID != "prod-id" || profile.URL != defaultAPIURL {
+		t.Errorf("default profile %+v, %v", profile, err)
+	}
+
+	os.Setenv("VO_PROFILE", "staging")
+	profile, err = resolveProfile(config, "")
+	if err != nil || profile.APIID != "staging-id" || profile.URL != "https://staging.example.com" {
+		t.Errorf("VO_PROFILE profile %+v, %v", profile, err)
+	}
+
+	os.Setenv("VO_API_KEY", "other-key")
+	profile, err = resolveProfile(config, "prod")
+	if err != nil || profile.APIID != "prod-id" || profile.APIKey != "other-key" || profile.Username != "alice" {
+		t.Errorf("overridden profile %+v, %v", profile, err)
+	}
+
+	_, err = resolveProfile(&Config{}, "")
+	if err == nil {
+		t.Errorf("resolved a profile without credentials")
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/victorops/go-victorops/internal/yamljson"
+)
+
+// The output formats of commands
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// result is what a command prints: the API value for json and yaml output, and a
+// summary of it for table output
+type result struct {
+	value  interface{}
+	header []string
+	rows   [][]string
+	// text, when set, is printed instead of a table for summaries that aren't tabular
+	text string
+}
+
+func (r *result) row(columns ...string) {
+	r.rows = append(r.rows, columns)
+}
+
+func (r result) write(w io.Writer, format string) error {
+	switch format {
+	case outputTable:
+		if r.text != "" {
+			_, err := io.WriteString(w, r.text)
+			return err
+		}
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(r.header, "\t"))
+		for _, row := range r.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r.value)
+	case outputYAML:
+		data, err := yamljson.Marshal(r.value)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	return fmt.Errorf("unknown output format %q, expected table, json or yaml", format)
+}
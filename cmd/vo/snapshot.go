@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/victorops/go-victorops/snapshot"
+)
+
+func readSnapshot(path string) (*snapshot.Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s, err := snapshot.Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// snapshotExport writes a snapshot of the org for diff and restore, as yaml unless json
+// output is asked for
+func snapshotExport(e *env, args []string) (*result, error) {
+	s, err := snapshot.Export(e.client, snapshot.ExportOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	format := snapshot.FormatYAML
+	if e.output == outputJSON {
+		format = snapshot.FormatJSON
+	}
+	if e.file == "" {
+		return nil, s.Write(e.stdout, format)
+	}
+
+	f, err := os.Create(e.file)
+	if err != nil {
+		return nil, err
+	}
+	err = s.Write(f, format)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return nil, err
+}
+
+// diff compares two snapshots, or a snapshot and the live org when only one is given. Like
+// diff(1) it exits 0 when nothing changed, 1 when something did and 2 on errors.
+func diff(e *env, args []string) (*result, error) {
+	e.status = 2
+
+	before, err := readSnapshot(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var after *snapshot.Snapshot
+	if len(args) == 2 {
+		after, err = readSnapshot(args[1])
+	} else {
+		err = e.connect()
+		if err == nil {
+			after, err = snapshot.Export(e.client, snapshot.ExportOptions{})
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := snapshot.Diff(before, after)
+	if err != nil {
+		return nil, err
+	}
+
+	e.status = 0
+	if report.HasChanges() {
+		e.status = 1
+	}
+	return &result{value: report, text: report.String()}, nil
+}
+
+// restore recreates what the snapshot has and the org is missing. Its json and yaml
+// output is the slug translation report.
+func restore(e *env, args []string) (*result, error) {
+	s, err := readSnapshot(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := snapshot.Restore(e.client, s, snapshot.RestoreOptions{DryRun: e.dryRun})
+	if report == nil {
+		return nil, err
+	}
+	return &result{value: report, text: report.String()}, err
+}
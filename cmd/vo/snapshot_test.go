@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/victorops/go-victorops/snapshot"
+	"github.com/victorops/go-victorops/victorops"
+	"github.com/victorops/go-victorops/victoropstest"
+)
+
+// setupOrg points vo at a fake org with a team, and writes a snapshot of it to a file
+func setupOrg(t *testing.T) (*victoropstest.Server, string) {
+	server := victoropstest.NewServer()
+	t.Cleanup(server.Close)
+	server.APIID, server.APIKey = "id", "key"
+	setupServer(t, server.Config.Handler)
+
+	client := server.Client()
+	_, _, err := client.CreateUser(&victorops.User{Username: "alice", FirstName: "Alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = client.CreateTeam(&victorops.Team{Name: "Ops"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(filepath.Dir(os.Getenv("VO_CONFIG")), "org.yaml")
+	code, stdout, stderr := runVo("snapshot", "export", "-f", path)
+	if code != 0 || stdout != "" {
+		t.Fatalf("snapshot export exited %d with %q: %s", code, stdout, stderr)
+	}
+	return server, path
+}
+
+func TestSnapshotExport(t *testing.T) {
+	_, path := setupOrg(t)
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	s, err := snapshot.Read(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Users) != 1 || s.Users[0].Username != "alice" || len(s.Teams) != 1 || s.Teams[0].Name != "Ops" {
+		t.Errorf("unexpected snapshot %+v", s)
+	}
+
+	code, stdout, stderr := runVo("snapshot", "export", "-o", "json")
+	var exported snapshot.Snapshot
+	if code != 0 || json.Unmarshal([]byte(stdout), &exported) != nil || len(exported.Teams) != 1 {
+		t.Errorf("json snapshot export exited %d with %q: %s", code, stdout, stderr)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	server, path := setupOrg(t)
+
+	code, stdout, stderr := runVo("diff", path)
+	if code != 0 || stdout != "No changes.\n" {
+		t.Errorf("diff of an unchanged org exited %d with %q: %s", code, stdout, stderr)
+	}
+
+	_, _, err := server.Client().CreateTeam(&victorops.Team{Name: "Dev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, stdout, stderr = runVo("diff", path, "-o", "json")
+	var report snapshot.Report
+	if code != 1 || json.Unmarshal([]byte(stdout), &report) != nil || len(report.Changes) != 1 || report.Changes[0].Kind != snapshot.KindTeam {
+		t.Errorf("diff of a changed org exited %d with %q: %s", code, stdout, stderr)
+	}
+
+	// Comparing files needs no credentials
+	os.Unsetenv("VO_API_ID")
+	code, stdout, stderr = runVo("diff", path, path)
+	if code != 0 || stdout != "No changes.\n" {
+		t.Errorf("diff of two files exited %d with %q: %s", code, stdout, stderr)
+	}
+
+	code, _, stderr = runVo("diff", path)
+	if code != 2 || !strings.Contains(stderr, "no credentials") {
+		t.Errorf("diff with the org without credentials exited %d: %s", code, stderr)
+	}
+	code, _, stderr = runVo("diff", filepath.Join(filepath.Dir(path), "missing.yaml"))
+	if code != 2 || !strings.Contains(stderr, "missing.yaml") {
+		t.Errorf("diff of a missing file exited %d: %s", code, stderr)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	server, path := setupOrg(t)
+	client := server.Client()
+	teams, _, err := client.GetAllTeams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	details, err := client.DeleteTeam((*teams)[0].Slug)
+	if err != nil || details.StatusCode != http.StatusNoContent {
+		t.Fatalf("failed to remove team: %v %+v", err, details)
+	}
+
+	code, stdout, stderr := runVo("restore", path, "-dry-run")
+	if code != 0 || !strings.Contains(stdout, "+ create team Ops") {
+		t.Errorf("dry run exited %d with %q: %s", code, stdout, stderr)
+	}
+	teams, _, _ = client.GetAllTeams()
+	if len(*teams) != 0 {
+		t.Errorf("dry run restored teams %+v", *teams)
+	}
+
+	code, stdout, stderr = runVo("restore", path, "-o", "json")
+	var report snapshot.RestoreReport
+	if code != 0 || json.Unmarshal([]byte(stdout), &report) != nil || len(report.Teams) != 1 || !report.Teams[0].Created {
+		t.Errorf("restore exited %d with %q: %s", code, stdout, stderr)
+	}
+	teams, _, _ = client.GetAllTeams()
+	if len(*teams) != 1 || (*teams)[0].Name != "Ops" || (*teams)[0].Slug != report.Teams[0].NewSlug {
+		t.Errorf("restored teams %+v, report %+v", *teams, report.Teams)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ANSI escape sequences used to draw the dashboard
+const (
+	enterAltScreen = "\x1b[?1049h\x1b[?25l"
+	leaveAltScreen = "\x1b[?25h\x1b[?1049l"
+	clearScreen    = "\x1b[H\x1b[2J"
+)
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// terminal is a terminal switched to reading unbuffered keys. The settings are changed with
+// stty, which keeps vo free of platform-specific system calls.
+type terminal struct {
+	in    *os.File
+	out   io.Writer
+	saved string
+}
+
+func stty(in *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = in
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run stty %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func openTerminal(in *os.File, out io.Writer) (*terminal, error) {
+	saved, err := stty(in, "-g")
+	if err != nil {
+		return nil, err
+	}
+	_, err = stty(in, "-icanon", "-echo", "-isig", "min", "1")
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprint(out, enterAltScreen)
+	return &terminal{in: in, out: out, saved: saved}, nil
+}
+
+func (t *terminal) restore() {
+	fmt.Fprint(t.out, leaveAltScreen)
+	stty(t.in, t.saved)
+}
+
+// size returns the width and height of the terminal, or 80x24 if unknown
+func (t *terminal) size() (int, int) {
+	var height, width int
+	output, err := stty(t.in, "size")
+	if err == nil {
+		_, err = fmt.Sscan(output, &height, &width)
+	}
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// draw clears the screen and renders a frame sized to the terminal
+func (t *terminal) draw(render func(w io.Writer, width int, height int) error) error {
+	width, height := t.size()
+	var frame bytes.Buffer
+	frame.WriteString(clearScreen)
+	err := render(&frame, width, height)
+	if err != nil {
+		return err
+	}
+	// Leave the cursor on the last line rather than scrolling past it
+	_, err = t.out.Write(bytes.TrimSuffix(frame.Bytes(), []byte("\n")))
+	return err
+}
+
+// readKeys sends the keys read from r, naming arrow keys and ctrl-c, until r fails
+func readKeys(r io.Reader, keys chan<- string) {
+	defer close(keys)
+	reader := bufio.NewReader(r)
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return
+		}
+		switch b {
+		case 3:
+			keys <- keyCtrlC
+		case 0x1b:
+			// Arrow keys are ESC [ A to ESC [ D
+			if reader.Buffered() >= 2 {
+				sequence := make([]byte, 2)
+				reader.Read(sequence)
+				switch string(sequence) {
+				case "[A":
+					keys <- keyUp
+				case "[B":
+					keys <- keyDown
+				}
+			}
+		default:
+			keys <- string(b)
+		}
+	}
+}
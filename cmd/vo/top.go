@@ -0,0 +1,347 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/victorops/go-victorops/internal/apierror"
+	"github.com/victorops/go-victorops/victorops"
+)
+
+// Keys the dashboard responds to, as read by readKeys
+const (
+	keyUp      = "up"
+	keyDown    = "down"
+	keyAck     = "a"
+	keyResolve = "r"
+	keyRefresh = " "
+	keyQuit    = "q"
+	keyCtrlC   = "ctrl-c"
+)
+
+const topHelp = "j/k select  a ack  r resolve  space refresh  q quit"
+
+// topClient is what the dashboard reads and acts through
+type topClient interface {
+	GetIncidents() (*victorops.IncidentResponse, *victorops.RequestDetails, error)
+	GetAllTeams() (*[]victorops.Team, *victorops.RequestDetails, error)
+	GetApiTeamSchedule(teamSlug string, daysForward int, daysSkip int, step int) (*victorops.ApiTeamSchedule, *victorops.RequestDetails, error)
+	AckIncidents(username string, incidentNumbers []int, message string) (*victorops.IncidentUpdateResponse, *victorops.RequestDetails, error)
+	ResolveIncidents(username string, incidentNumbers []int, message string) (*victorops.IncidentUpdateResponse, *victorops.RequestDetails, error)
+}
+
+// teamOnCall is who is on call for a team, overrides included
+type teamOnCall struct {
+	team  string
+	users []string
+}
+
+// dashboard is the state of vo top. It knows nothing of terminals: keys are fed to
+// handleKey and frames drawn by render, so it can run headless.
+type dashboard struct {
+	client  topClient
+	user    string
+	message string
+	// teams are the slugs of the teams to show, all of them when empty
+	teams []string
+	now   func() time.Time
+
+	incidents []victorops.Incident
+	oncall    []teamOnCall
+	// selected is the number of the selected incident
+	selected string
+	status   string
+	updated  time.Time
+}
+
+// phaseOrder sorts unacknowledged incidents before acknowledged ones
+var phaseOrder = map[string]int{"UNACKED": 0, "ACKED": 1}
+
+// refresh fetches the open incidents and who is on call
+func (d *dashboard) refresh() error {
+	response, details, err := d.client.GetIncidents()
+	if err := apierror.Check(details, err); err != nil {
+		return err
+	}
+	var incidents []victorops.Incident
+	for _, incident := range response.Incidents {
+		if incident.CurrentPhase != "RESOLVED" {
+			incidents = append(incidents, incident)
+		}
+	}
+	sort.SliceStable(incidents, func(i, j int) bool {
+		if incidents[i].CurrentPhase != incidents[j].CurrentPhase {
+			return phaseOrder[incidents[i].CurrentPhase] < phaseOrder[incidents[j].CurrentPhase]
+		}
+		return incidents[i].StartTime.After(incidents[j].StartTime)
+	})
+
+	if len(d.teams) == 0 {
+		teams, details, err := d.client.GetAllTeams()
+		if err := apierror.Check(details, err); err != nil {
+			return err
+		}
+		sort.Slice(*teams, func(i, j int) bool { return (*teams)[i].Name < (*teams)[j].Name })
+		for _, team := range *teams {
+			d.teams = append(d.teams, team.Slug)
+		}
+	}
+
+	var oncall []teamOnCall
+	for _, slug := range d.teams {
+		schedule, details, err := d.client.GetApiTeamSchedule(slug, 0, 0, 0)
+		if err := apierror.Check(details, err); err != nil {
+			return fmt.Errorf("failed to get the on-call schedule of %s: %w", slug, err)
+		}
+		entry := teamOnCall{team: schedule.Team.Name}
+		if entry.team == "" {
+			entry.team = slug
+		}
+		seen := map[string]bool{}
+		for _, policy := range schedule.Schedules {
+			for _, shift := range policy.Schedule {
+				user := shift.OnCallUser.Username
+				if shift.OverrideOnCallUser.Username != "" {
+					user = shift.OverrideOnCallUser.Username
+				}
+				if user != "" && !seen[user] {
+					seen[user] = true
+					entry.users = append(entry.users, user)
+				}
+			}
+		}
+		oncall = append(oncall, entry)
+	}
+
+	// When the selected incident is resolved, select the one that took its place
+	previous := d.selectedIndex()
+	d.incidents = incidents
+	d.oncall = oncall
+	d.updated = d.now()
+	if d.selectedIndex() < 0 {
+		d.selected = ""
+		d.move(previous)
+	}
+	return nil
+}
+
+// update refreshes, reporting failures in the status line rather than stopping
+func (d *dashboard) update() {
+	err := d.refresh()
+	if err != nil {
+		d.status = "error: " + err.Error()
+	}
+}
+
+func (d *dashboard) selectedIndex() int {
+	for i, incident := range d.incidents {
+		if incident.IncidentNumber == d.selected {
+			return i
+		}
+	}
+	return -1
+}
+
+// move selects the incident delta rows away, or the delta-th one when none is selected
+func (d *dashboard) move(delta int) {
+	if len(d.incidents) == 0 {
+		return
+	}
+	i := delta
+	if current := d.selectedIndex(); current >= 0 {
+		i += current
+	}
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(d.incidents) {
+		i = len(d.incidents) - 1
+	}
+	d.selected = d.incidents[i].IncidentNumber
+}
+
+// act acknowledges or resolves the selected incident
+func (d *dashboard) act(verb string, update updateIncidents) {
+	if d.selectedIndex() < 0 {
+		d.status = "no incident selected"
+		return
+	}
+	if d.user == "" {
+		d.status = "error: no user to act as: set username in the profile or pass -user"
+		return
+	}
+	number, err := strconv.Atoi(d.selected)
+	if err != nil {
+		d.status = fmt.Sprintf("error: invalid incident number %q", d.selected)
+		return
+	}
+
+	response, details, err := update(d.user, []int{number}, d.message)
+	if err := apierror.Check(details, err); err != nil {
+		d.status = fmt.Sprintf("error: failed to %s incident %d: %v", verb, number, err)
+		return
+	}
+	d.status = fmt.Sprintf("%s incident %d", verb, number)
+	for _, result := range response.Results {
+		if !result.CmdAccepted {
+			d.status = fmt.Sprintf("could not %s incident %d: %s", verb, number, result.Message)
+		}
+	}
+	d.update()
+}
+
+// handleKey applies a key press, returning whether to quit
+func (d *dashboard) handleKey(key string) bool {
+	switch key {
+	case keyQuit, keyCtrlC:
+		return true
+	case keyUp, "k":
+		d.move(-1)
+	case keyDown, "j":
+		d.move(1)
+	case keyAck:
+		d.act("acknowledge", d.client.AckIncidents)
+	case keyResolve:
+		d.act("resolve", d.client.ResolveIncidents)
+	case keyRefresh:
+		d.status = ""
+		d.update()
+	}
+	return false
+}
+
+// render draws a frame of at most width columns and height lines, where zero means no limit
+func (d *dashboard) render(w io.Writer, width int, height int) error {
+	var incidents bytes.Buffer
+	tw := tabwriter.NewWriter(&incidents, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "  NUMBER\tPHASE\tSTARTED\tSERVICE\tTEAMS")
+	for _, incident := range d.incidents {
+		marker := " "
+		if incident.IncidentNumber == d.selected {
+			marker = ">"
+		}
+		var teams []string
+		for _, policy := range incident.PagedPolicies {
+			teams = append(teams, policy.Team.Name)
+		}
+		fmt.Fprintf(tw, "%s %s\t%s\t%s\t%s\t%s\n", marker, incident.IncidentNumber, incident.CurrentPhase,
+			formatTime(incident.StartTime), incident.Service, strings.Join(teams, ", "))
+	}
+	tw.Flush()
+
+	var oncall bytes.Buffer
+	tw = tabwriter.NewWriter(&oncall, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "  TEAM\tON CALL")
+	for _, entry := range d.oncall {
+		fmt.Fprintf(tw, "  %s\t%s\n", entry.team, strings.Join(entry.users, ", "))
+	}
+	tw.Flush()
+
+	title := fmt.Sprintf("vo top: %d open incidents", len(d.incidents))
+	if !d.updated.IsZero() {
+		title += ", updated " + d.updated.Local().Format("15:04:05")
+	}
+	incidentLines := strings.Split(strings.TrimSuffix(incidents.String(), "\n"), "\n")
+	oncallLines := strings.Split(strings.TrimSuffix(oncall.String(), "\n"), "\n")
+	footer := []string{"", d.status, topHelp}
+
+	// Keep the selected incident in view when they don't all fit
+	if height > 0 {
+		room := height - 2 - len(oncallLines) - 1 - len(footer)
+		if room < 2 {
+			room = 2
+		}
+		if len(incidentLines) > room {
+			first := d.selectedIndex() + 2 - room
+			if first < 0 {
+				first = 0
+			}
+			incidentLines = append(incidentLines[:1], incidentLines[1+first:first+room]...)
+		}
+	}
+
+	lines := []string{title, ""}
+	lines = append(lines, incidentLines...)
+	lines = append(lines, "")
+	lines = append(lines, oncallLines...)
+	lines = append(lines, footer...)
+	// Cut the lines above the footer, and the footer too on the smallest terminals
+	if height > 0 && len(lines) > height {
+		if height > len(footer) {
+			lines = append(lines[:height-len(footer)], footer...)
+		} else {
+			lines = footer[len(footer)-height:]
+		}
+	}
+
+	for _, line := range lines {
+		// Cut by runes so that names in other scripts aren't left as invalid UTF-8
+		if runes := []rune(line); width > 0 && len(runes) > width {
+			line = string(runes[:width])
+		}
+		_, err := fmt.Fprintln(w, line)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// top runs the dashboard in the terminal, or prints a single frame when stdin isn't one
+func top(e *env, args []string) (*result, error) {
+	d := dashboard{client: e.client, teams: args, message: e.message, now: time.Now}
+	d.user, _ = e.actingUser()
+
+	in, ok := e.stdin.(*os.File)
+	if !ok || !isTerminal(in) {
+		err := d.refresh()
+		if err != nil {
+			return nil, err
+		}
+		return nil, d.render(e.stdout, 0, 0)
+	}
+	if e.interval <= 0 {
+		return nil, fmt.Errorf("invalid interval %v", e.interval)
+	}
+
+	term, err := openTerminal(in, e.stdout)
+	if err != nil {
+		return nil, err
+	}
+	defer term.restore()
+
+	keys := make(chan string)
+	go readKeys(in, keys)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	d.update()
+	for {
+		err := term.draw(d.render)
+		if err != nil {
+			return nil, err
+		}
+		select {
+		case key, ok := <-keys:
+			if !ok || d.handleKey(key) {
+				return nil, nil
+			}
+		case <-ticker.C:
+			d.update()
+		case <-signals:
+			return nil, nil
+		}
+	}
+}
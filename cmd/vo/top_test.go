@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/victorops/go-victorops/victorops"
+)
+
+var (
+	topStart = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	topNow   = func() time.Time { return topStart.Add(time.Hour) }
+)
+
+// fakeOrg serves incidents that acknowledging and resolving change, and on-call schedules
+type fakeOrg struct {
+	mu        sync.Mutex
+	incidents []victorops.Incident
+	updates   []string
+}
+
+func newFakeOrg() *fakeOrg {
+	ops := []victorops.PagedPolicy{{Team: victorops.PagedEntity{Name: "Ops", Slug: "team-ops"}}}
+	return &fakeOrg{incidents: []victorops.Incident{
+		{IncidentNumber: "10", CurrentPhase: "ACKED", Service: "disk full", StartTime: topStart, PagedPolicies: ops},
+		{IncidentNumber: "11", CurrentPhase: "UNACKED", Service: "db down", StartTime: topStart.Add(time.Minute), PagedPolicies: ops},
+		{IncidentNumber: "12", CurrentPhase: "UNACKED", Service: "api 5xx", StartTime: topStart.Add(2 * time.Minute)},
+		{IncidentNumber: "9", CurrentPhase: "RESOLVED", Service: "old", StartTime: topStart},
+	}}
+}
+
+func (f *fakeOrg) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/api-public/v1/incidents":
+		json.NewEncoder(w).Encode(victorops.IncidentResponse{Incidents: f.incidents})
+	case "/api-public/v1/team":
+		fmt.Fprint(w, `[{"name":"Ops","slug":"team-ops"},{"name":"Dev","slug":"team-dev"}]`)
+	case "/api-public/v2/team/team-ops/oncall/schedule":
+		fmt.Fprint(w, `{"team":{"name":"Ops","slug":"team-ops"},"schedules":[{"schedule":[
+			{"onCallUser":{"username":"alice"}},
+			{"onCallUser":{"username":"bob"},"overrideOnCallUser":{"username":"carol"}},
+			{"onCallUser":{"username":"alice"}}]}]}`)
+	case "/api-public/v2/team/team-dev/oncall/schedule":
+		fmt.Fprint(w, `{"team":{"name":"Dev","slug":"team-dev"},"schedules":[]}`)
+	case "/api-public/v1/incidents/ack", "/api-public/v1/incidents/resolve":
+		phase := "ACKED"
+		if strings.HasSuffix(r.URL.Path, "resolve") {
+			phase = "RESOLVED"
+		}
+		var request victorops.IncidentUpdateRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		response := victorops.IncidentUpdateResponse{}
+		for _, number := range request.IncidentNumbers {
+			f.updates = append(f.updates, fmt.Sprintf("%s %s by %s", phase, number, request.UserName))
+			for i := range f.incidents {
+				if f.incidents[i].IncidentNumber == number {
+					f.incidents[i].CurrentPhase = phase
+				}
+			}
+			response.Results = append(response.Results, victorops.IncidentUpdateResult{IncidentNumber: number, CmdAccepted: true})
+		}
+		json.NewEncoder(w).Encode(response)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{}`)
+	}
+}
+
+func newTestDashboard(t *testing.T, org *fakeOrg) *dashboard {
+	server := httptest.NewServer(org)
+	t.Cleanup(server.Close)
+	d := &dashboard{
+		client: victorops.NewClient("id", "key", server.URL),
+		user:   "alice",
+		now:    topNow,
+	}
+	err := d.refresh()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func renderFrame(t *testing.T, d *dashboard, width int, height int) string {
+	var frame bytes.Buffer
+	err := d.render(&frame, width, height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return frame.String()
+}
+
+func TestTopRender(t *testing.T) {
+	d := newTestDashboard(t, newFakeOrg())
+
+	updated := topNow().Local().Format("15:04:05")
+	want := "vo top: 3 open incidents, updated " + updated + "\n" +
+		"\n" +
+		"  NUMBER  PHASE    STARTED           SERVICE    TEAMS\n" +
+		fmt.Sprintf("> 12      UNACKED  %s  api 5xx    \n", formatTime(topStart.Add(2*time.Minute))) +
+		fmt.Sprintf("  11      UNACKED  %s  db down    Ops\n", formatTime(topStart.Add(time.Minute))) +
+		fmt.Sprintf("  10      ACKED    %s  disk full  Ops\n", formatTime(topStart)) +
+		"\n" +
+		"  TEAM  ON CALL\n" +
+		"  Dev   \n" +
+		"  Ops   alice, carol\n" +
+		"\n" +
+		"\n" +
+		topHelp + "\n"
+	if got := renderFrame(t, d, 0, 0); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	for _, line := range strings.Split(renderFrame(t, d, 20, 0), "\n") {
+		if len(line) > 20 {
+			t.Errorf("line %q is wider than 20 columns", line)
+		}
+	}
+
+	// Short terminals keep the footer, or its last lines when even it doesn't fit
+	for height, want := range map[int]string{
+		1: topHelp + "\n",
+		2: "\n" + topHelp + "\n",
+		4: "vo top: 3 open incidents, updated " + updated + "\n\n\n" + topHelp + "\n",
+	} {
+		if got := renderFrame(t, d, 0, height); got != want {
+			t.Errorf("height %d: got\n%s\nwant\n%s", height, got, want)
+		}
+	}
+}
+
+func TestTopRenderTruncatesRunes(t *testing.T) {
+	org := newFakeOrg()
+	org.incidents = append(org.incidents, victorops.Incident{IncidentNumber: "13", CurrentPhase: "UNACKED", Service: "base de données indisponible ☠", StartTime: topStart.Add(3 * time.Minute)})
+	d := newTestDashboard(t, org)
+
+	if !strings.Contains(renderFrame(t, d, 0, 0), "base de données indisponible ☠") {
+		t.Fatalf("incident 13 missing from\n%s", renderFrame(t, d, 0, 0))
+	}
+	for width := 1; width <= 80; width++ {
+		for _, line := range strings.Split(renderFrame(t, d, width, 0), "\n") {
+			if !utf8.ValidString(line) || utf8.RuneCountInString(line) > width {
+				t.Errorf("width %d: line %q is invalid or too wide", width, line)
+			}
+		}
+	}
+}
+
+func TestTopKeepsSelectionInView(t *testing.T) {
+	d := newTestDashboard(t, newFakeOrg())
+	d.handleKey(keyDown)
+	d.handleKey("j")
+
+	frame := renderFrame(t, d, 0, 10)
+	lines := strings.Split(strings.TrimSuffix(frame, "\n"), "\n")
+	if len(lines) != 10 {
+		t.Errorf("frame is %d lines, want 10:\n%s", len(lines), frame)
+	}
+	if !strings.Contains(frame, "> 10") || !strings.HasSuffix(frame, topHelp+"\n") {
+		t.Errorf("selected incident or help missing:\n%s", frame)
+	}
+}
+
+func TestTopAckAndResolve(t *testing.T) {
+	org := newFakeOrg()
+	d := newTestDashboard(t, org)
+
+	d.handleKey(keyDown)
+	if d.handleKey(keyAck) {
+		t.Fatal("acknowledging quit the dashboard")
+	}
+	if d.status != "acknowledge incident 11" {
+		t.Errorf("status is %q", d.status)
+	}
+	// Incident 11 moved below the unacknowledged one but stays selected
+	if d.selected != "11" || d.incidents[0].IncidentNumber != "12" || d.incidents[1].CurrentPhase != "ACKED" {
+		t.Errorf("selected %s of %+v", d.selected, d.incidents)
+	}
+
+	d.handleKey(keyResolve)
+	if len(d.incidents) != 2 || d.selected != "10" {
+		t.Errorf("selected %s of %+v after resolving", d.selected, d.incidents)
+	}
+	d.handleKey(keyUp)
+	if d.selected != "12" {
+		t.Errorf("selected %s after moving up", d.selected)
+	}
+
+	want := []string{"ACKED 11 by alice", "RESOLVED 11 by alice"}
+	if fmt.Sprint(org.updates) != fmt.Sprint(want) {
+		t.Errorf("updates are %v, want %v", org.updates, want)
+	}
+
+	d.user = ""
+	d.handleKey(keyAck)
+	if !strings.Contains(d.status, "no user to act as") {
+		t.Errorf("status is %q", d.status)
+	}
+	if !d.handleKey(keyQuit) || !d.handleKey(keyCtrlC) {
+		t.Error("q and ctrl-c don't quit")
+	}
+}
+
+func TestTopHeadless(t *testing.T) {
+	setupServer(t, newFakeOrg())
+
+	code, stdout, stderr := runVo("top", "team-ops")
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	if !strings.HasPrefix(stdout, "vo top: 3 open incidents") || !strings.Contains(stdout, "Ops   alice, carol") || strings.Contains(stdout, "Dev") {
+		t.Errorf("unexpected output\n%s", stdout)
+	}
+}
+
+func TestReadKeys(t *testing.T) {
+	keys := make(chan string)
+	go readKeys(strings.NewReader("j\x1b[A\x1b[Ba\x03q"), keys)
+
+	var got []string
+	for key := range keys {
+		got = append(got, key)
+	}
+	want := []string{"j", keyUp, keyDown, keyAck, keyCtrlC, keyQuit}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("read keys %q, want %q", got, want)
+	}
+}
@@ -1,3 +1,5 @@
 module github.com/victorops/go-victorops
 
 go 1.14
+
+require gopkg.in/yaml.v3 v3.0.1
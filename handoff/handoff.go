@@ -0,0 +1,211 @@
+// Package handoff sends reminders to users shortly before they go on call.
+package handoff
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/victorops/go-victorops/victorops"
+)
+
+// Handoff is a change of the on-call user for an escalation policy
+type Handoff struct {
+	Team         victorops.ApiTeam             `json:"team"`
+	Policy       victorops.ApiEscalationPolicy `json:"policy"`
+	RotationName string                        `json:"rotationName,omitempty"`
+	ShiftName    string                        `json:"shiftName,omitempty"`
+	At           time.Time                     `json:"at"`
+	FromUser     string                        `json:"fromUser,omitempty"`
+	ToUser       string                        `json:"toUser"`
+	IsOverride   bool                          `json:"isOverride,omitempty"`
+}
+
+func (h Handoff) key() string {
+	return fmt.Sprintf("%s/%s/%s/%s/%d", h.Team.Slug, h.Policy.Slug, h.ShiftName, h.ToUser, h.At.Unix())
+}
+
+// Notifier is notified of every upcoming handoff
+type Notifier interface {
+	Notify(handoff Handoff) error
+}
+
+// Clock is the source of time of the scheduler, so tests can control it
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// ScheduleSource returns the on-call schedule of a team. *victorops.Client implements it.
+type ScheduleSource interface {
+	GetApiTeamSchedule(teamSlug string, daysForward int, daysSkip int, step int) (*victorops.ApiTeamSchedule, *victorops.RequestDetails, error)
+}
+
+// Scheduler polls team schedules and notifies users Lead before they go on call
+type Scheduler struct {
+	Source   ScheduleSource
+	Notifier Notifier
+	Teams    []string
+	// Lead is how long before a handoff the notifier is called
+	Lead time.Duration
+	// PollInterval is how often the schedules are fetched. It should be shorter than Lead.
+	PollInterval time.Duration
+	Clock        Clock
+	Logger       *log.Logger
+
+	mu       sync.Mutex
+	notified map[string]time.Time
+}
+
+// NewScheduler creates a scheduler notifying users lead before their handoffs on the given teams
+func NewScheduler(source ScheduleSource, notifier Notifier, teams []string, lead time.Duration) *Scheduler {
+	pollInterval := lead / 4
+	if pollInterval < time.Minute {
+		pollInterval = time.Minute
+	}
+
+	return &Scheduler{
+		Source:       source,
+		Notifier:     notifier,
+		Teams:        teams,
+		Lead:         lead,
+		PollInterval: pollInterval,
+		Clock:        realClock{},
+		Logger:       log.New(log.Writer(), "handoff: ", log.LstdFlags),
+		notified:     map[string]time.Time{},
+	}
+}
+
+// UpcomingHandoffs returns the handoffs in the schedule happening after from and up to to,
+// including the start and end of overrides
+func UpcomingHandoffs(schedule victorops.ApiTeamSchedule, from time.Time, to time.Time) []Handoff {
+	var handoffs []Handoff
+	within := func(at time.Time) bool {
+		return at.After(from) && !at.After(to)
+	}
+
+	for _, policy := range schedule.Schedules {
+		for _, entry := range policy.Schedule {
+			rolls := append([]victorops.ApiOnCallRoll{}, entry.Rolls...)
+			sort.Slice(rolls, func(a, b int) bool { return rolls[a].Start.Before(rolls[b].Start) })
+
+			for i, roll := range rolls {
+				previous := ""
+				if i > 0 {
+					previous = rolls[i-1].OnCallUser.Username
+				}
+				if previous == roll.OnCallUser.Username || !within(roll.Start) {
+					continue
+				}
+				handoffs = append(handoffs, Handoff{
+					Team:         schedule.Team,
+					Policy:       policy.Policy,
+					RotationName: entry.RotationName,
+					ShiftName:    entry.ShiftName,
+					At:           roll.Start,
+					FromUser:     previous,
+					ToUser:       roll.OnCallUser.Username,
+				})
+			}
+		}
+
+		for _, override := range policy.Overrides {
+			if within(override.Start) {
+				handoffs = append(handoffs, Handoff{
+					Team:       schedule.Team,
+					Policy:     policy.Policy,
+					At:         override.Start,
+					FromUser:   override.OrigOnCallUser.Username,
+					ToUser:     override.OverrideOnCallUser.Username,
+					IsOverride: true,
+				})
+			}
+			if within(override.End) {
+				handoffs = append(handoffs, Handoff{
+					Team:       schedule.Team,
+					Policy:     policy.Policy,
+					At:         override.End,
+					FromUser:   override.OverrideOnCallUser.Username,
+					ToUser:     override.OrigOnCallUser.Username,
+					IsOverride: true,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(handoffs, func(a, b int) bool { return handoffs[a].At.Before(handoffs[b].At) })
+	return handoffs
+}
+
+// Tick fetches the schedules once and notifies every handoff due within Lead that was
+// not notified yet. Errors of single teams or notifications are logged and the first
+// one is returned after all teams were processed.
+func (s *Scheduler) Tick() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.notified == nil {
+		s.notified = map[string]time.Time{}
+	}
+
+	now := s.Clock.Now()
+	daysForward := int(s.Lead.Hours()/24) + 1
+
+	var firstErr error
+	fail := func(err error) {
+		if s.Logger != nil {
+			s.Logger.Println(err)
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	for _, team := range s.Teams {
+		schedule, _, err := s.Source.GetApiTeamSchedule(team, daysForward, 0, 0)
+		if err != nil {
+			fail(fmt.Errorf("failed to fetch schedule of team %s: %w", team, err))
+			continue
+		}
+
+		for _, handoff := range UpcomingHandoffs(*schedule, now, now.Add(s.Lead)) {
+			if _, ok := s.notified[handoff.key()]; ok {
+				continue
+			}
+			if err := s.Notifier.Notify(handoff); err != nil {
+				fail(fmt.Errorf("failed to notify %s of handoff at %s: %w", handoff.ToUser, handoff.At, err))
+				continue
+			}
+			s.notified[handoff.key()] = handoff.At
+		}
+	}
+
+	// Forget handoffs that are in the past
+	for key, at := range s.notified {
+		if at.Before(now) {
+			delete(s.notified, key)
+		}
+	}
+
+	return firstErr
+}
+
+// Run calls Tick every PollInterval until stop is closed
+func (s *Scheduler) Run(stop <-chan struct{}) {
+	for {
+		_ = s.Tick()
+
+		select {
+		case <-stop:
+			return
+		case <-s.Clock.After(s.PollInterval):
+		}
+	}
+}
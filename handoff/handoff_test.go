@@ -0,0 +1,158 @@
+package handoff
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/victorops/go-victorops/victorops"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time                         { return c.now }
+func (c *fakeClock) After(d time.Duration) <-chan time.Time { return make(chan time.Time) }
+
+type fakeSource struct {
+	schedules map[string]victorops.ApiTeamSchedule
+	err       error
+}
+
+func (s fakeSource) GetApiTeamSchedule(teamSlug string, daysForward int, daysSkip int, step int) (*victorops.ApiTeamSchedule, *victorops.RequestDetails, error) {
+	if s.err != nil {
+		return nil, &victorops.RequestDetails{}, s.err
+	}
+	schedule := s.schedules[teamSlug]
+	return &schedule, &victorops.RequestDetails{StatusCode: 200}, nil
+}
+
+func mustParseTime(t *testing.T, value string) time.Time {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func testSchedule(t *testing.T) victorops.ApiTeamSchedule {
+	return victorops.ApiTeamSchedule{
+		Team: victorops.ApiTeam{Name: "Infrastructure", Slug: "team-abcd"},
+		Schedules: []victorops.ApiEscalationPolicySchedule{
+			{
+				Policy: victorops.ApiEscalationPolicy{Name: "High Severity", Slug: "pol-abcd"},
+				Schedule: []victorops.ApiOnCallEntry{
+					{
+						RotationName: "Primary",
+						ShiftName:    "primary",
+						Rolls: []victorops.ApiOnCallRoll{
+							{Start: mustParseTime(t, "2020-04-07T09:00:00Z"), End: mustParseTime(t, "2020-04-14T09:00:00Z"), OnCallUser: victorops.ApiUser{Username: "johndoe"}},
+							{Start: mustParseTime(t, "2020-03-31T09:00:00Z"), End: mustParseTime(t, "2020-04-07T09:00:00Z"), OnCallUser: victorops.ApiUser{Username: "janedoe"}},
+						},
+					},
+				},
+				Overrides: []victorops.ApiOnCallOverride{
+					{
+						OrigOnCallUser:     victorops.ApiUser{Username: "johndoe"},
+						OverrideOnCallUser: victorops.ApiUser{Username: "bobsmith"},
+						Start:              mustParseTime(t, "2020-04-08T09:00:00Z"),
+						End:                mustParseTime(t, "2020-04-08T18:00:00Z"),
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestUpcomingHandoffs(t *testing.T) {
+	handoffs := UpcomingHandoffs(testSchedule(t), mustParseTime(t, "2020-04-01T00:00:00Z"), mustParseTime(t, "2020-04-09T00:00:00Z"))
+	if len(handoffs) != 3 {
+		t.Fatalf("expected 3 handoffs, got %#v", handoffs)
+	}
+
+	want := []struct {
+		from string
+		to   string
+		at   string
+	}{
+		{"janedoe", "johndoe", "2020-04-07T09:00:00Z"},
+		{"johndoe", "bobsmith", "2020-04-08T09:00:00Z"},
+		{"bobsmith", "johndoe", "2020-04-08T18:00:00Z"},
+	}
+	for i, w := range want {
+		if handoffs[i].FromUser != w.from || handoffs[i].ToUser != w.to || !handoffs[i].At.Equal(mustParseTime(t, w.at)) {
+			t.Errorf("handoff %d: got %#v want %v", i, handoffs[i], w)
+		}
+	}
+}
+
+func TestSchedulerTick(t *testing.T) {
+	clock := &fakeClock{now: mustParseTime(t, "2020-04-07T08:00:00Z")}
+	var notified []Handoff
+	notifier := NotifierFunc(func(h Handoff) error {
+		notified = append(notified, h)
+		return nil
+	})
+
+	source := fakeSource{schedules: map[string]victorops.ApiTeamSchedule{"team-abcd": testSchedule(t)}}
+	scheduler := NewScheduler(source, notifier, []string{"team-abcd"}, 30*time.Minute)
+	scheduler.Clock = clock
+	scheduler.Logger = nil
+
+	// Too early for the 09:00 handoff
+	if err := scheduler.Tick(); err != nil {
+		t.Fatal(err)
+	}
+	if len(notified) != 0 {
+		t.Fatalf("expected no notifications, got %#v", notified)
+	}
+
+	clock.now = mustParseTime(t, "2020-04-07T08:35:00Z")
+	if err := scheduler.Tick(); err != nil {
+		t.Fatal(err)
+	}
+	if len(notified) != 1 || notified[0].ToUser != "johndoe" {
+		t.Fatalf("expected johndoe to be notified, got %#v", notified)
+	}
+
+	// The same handoff is not notified twice
+	clock.now = mustParseTime(t, "2020-04-07T08:45:00Z")
+	if err := scheduler.Tick(); err != nil {
+		t.Fatal(err)
+	}
+	if len(notified) != 1 {
+		t.Fatalf("expected a single notification, got %#v", notified)
+	}
+}
+
+func TestSchedulerTickErrors(t *testing.T) {
+	clock := &fakeClock{now: mustParseTime(t, "2020-04-07T08:35:00Z")}
+	notifier := NotifierFunc(func(h Handoff) error { return errors.New("smtp down") })
+
+	scheduler := NewScheduler(fakeSource{schedules: map[string]victorops.ApiTeamSchedule{"team-abcd": testSchedule(t)}}, notifier, []string{"team-abcd"}, 30*time.Minute)
+	scheduler.Clock = clock
+	scheduler.Logger = nil
+
+	if err := scheduler.Tick(); err == nil {
+		t.Error("expected the notifier error to be returned")
+	}
+
+	// A failed notification is retried on the next tick
+	calls := 0
+	scheduler.Notifier = NotifierFunc(func(h Handoff) error {
+		calls++
+		return nil
+	})
+	if err := scheduler.Tick(); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("expected the handoff to be retried, got %d calls", calls)
+	}
+
+	scheduler.Source = fakeSource{err: errors.New("api down")}
+	if err := scheduler.Tick(); err == nil {
+		t.Error("expected the schedule error to be returned")
+	}
+}
@@ -0,0 +1,121 @@
+package handoff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/victorops/go-victorops/victorops"
+)
+
+// NotifierFunc adapts a function to the Notifier interface
+type NotifierFunc func(handoff Handoff) error
+
+// Notify calls f(handoff)
+func (f NotifierFunc) Notify(handoff Handoff) error {
+	return f(handoff)
+}
+
+// AddressLookup returns the email address of a user
+type AddressLookup func(username string) (string, error)
+
+// ClientAddressLookup looks up the email address of users in the VictorOps org
+func ClientAddressLookup(client *victorops.Client) AddressLookup {
+	return func(username string) (string, error) {
+		user, details, err := client.GetUser(username)
+		if err != nil {
+			return "", err
+		}
+		if user == nil || user.Email == "" {
+			return "", fmt.Errorf("no email address for user %s (%d): %s", username, details.StatusCode, details.ResponseBody)
+		}
+		return user.Email, nil
+	}
+}
+
+// SMTPNotifier emails the user going on call
+type SMTPNotifier struct {
+	// Addr is the host:port of the SMTP server
+	Addr string
+	// Auth is optional and used when the server requires authentication
+	Auth smtp.Auth
+	From string
+	// Lookup returns the address to send the reminder for a user to
+	Lookup AddressLookup
+	// Location is used to format the handoff time. Defaults to UTC.
+	Location *time.Location
+}
+
+// Notify sends the reminder email for a handoff
+func (n SMTPNotifier) Notify(handoff Handoff) error {
+	to, err := n.Lookup(handoff.ToUser)
+	if err != nil {
+		return err
+	}
+
+	loc := n.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "From: %s\r\n", n.From)
+	fmt.Fprintf(&body, "To: %s\r\n", to)
+	fmt.Fprintf(&body, "Subject: You're on call next for %s\r\n", handoff.Team.Name)
+	fmt.Fprintf(&body, "\r\n")
+	fmt.Fprintf(&body, "Hi %s,\r\n\r\n", handoff.ToUser)
+	fmt.Fprintf(&body, "you are on call for %s (%s) starting %s", handoff.Policy.Name, handoff.Team.Name, handoff.At.In(loc).Format(time.RFC1123))
+	if handoff.FromUser != "" {
+		fmt.Fprintf(&body, ", taking over from %s", handoff.FromUser)
+	}
+	fmt.Fprintf(&body, ".\r\n")
+
+	return smtp.SendMail(n.Addr, n.Auth, n.From, []string{to}, []byte(body.String()))
+}
+
+// WebhookNotifier posts every handoff as JSON to a URL
+type WebhookNotifier struct {
+	URL        string
+	HTTPClient *http.Client
+	// Header is added to every request, e.g. for authentication
+	Header http.Header
+}
+
+// Notify posts the handoff to the webhook and fails on a non 2xx response
+func (n WebhookNotifier) Notify(handoff Handoff) error {
+	jsonHandoff, err := json.Marshal(handoff)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", n.URL, bytes.NewBuffer(jsonHandoff))
+	if err != nil {
+		return err
+	}
+	for key, values := range n.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := n.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: time.Second * 30}
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s returned %s", n.URL, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package handoff
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/victorops/go-victorops/victorops"
+)
+
+// smtpStandIn is a minimal SMTP server accepting a single message
+type smtpStandIn struct {
+	listener net.Listener
+	messages chan string
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &smtpStandIn{listener: listener, messages: make(chan string, 1)}
+	go s.serve()
+	return s
+}
+
+func (s *smtpStandIn) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP")
+	var data []string
+	inData := false
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if inData {
+			if line == "." {
+				inData = false
+				s.messages <- strings.Join(data, "\n")
+				reply("250 OK")
+				continue
+			}
+			data = append(data, line)
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "EHLO"), strings.HasPrefix(line, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(line, "DATA"):
+			inData = true
+			reply("354 go ahead")
+		case strings.HasPrefix(line, "QUIT"):
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	server := newSMTPStandIn(t)
+	defer server.listener.Close()
+
+	notifier := SMTPNotifier{
+		Addr: server.listener.Addr().String(),
+		From: "oncall@example.com",
+		Lookup: func(username string) (string, error) {
+			return username + "@example.com", nil
+		},
+	}
+
+	err := notifier.Notify(Handoff{
+		Team:     victorops.ApiTeam{Name: "Infrastructure", Slug: "team-abcd"},
+		Policy:   victorops.ApiEscalationPolicy{Name: "High Severity", Slug: "pol-abcd"},
+		At:       mustParseTime(t, "2020-04-07T09:00:00Z"),
+		FromUser: "janedoe",
+		ToUser:   "johndoe",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := <-server.messages
+	for _, want := range []string{"To: johndoe@example.com", "Subject: You're on call next for Infrastructure", "taking over from janedoe"} {
+		if !strings.Contains(message, want) {
+			t.Errorf("expected message to contain %q, got \n\n%s", want, message)
+		}
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var received Handoff
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	notifier := WebhookNotifier{URL: server.URL, Header: http.Header{"Authorization": []string{"Bearer token"}}}
+	err := notifier.Notify(Handoff{ToUser: "johndoe", At: mustParseTime(t, "2020-04-07T09:00:00Z")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if received.ToUser != "johndoe" {
+		t.Errorf("unexpected handoff posted %#v", received)
+	}
+
+	notifier.Header = nil
+	if err := notifier.Notify(Handoff{ToUser: "johndoe"}); err == nil {
+		t.Error("expected an error on a 401 response")
+	}
+}
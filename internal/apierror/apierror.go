@@ -0,0 +1,22 @@
+// Package apierror turns unsuccessful VictorOps API responses into errors. The client
+// methods only return errors for failed requests, leaving the status code to the caller.
+package apierror
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/victorops/go-victorops/victorops"
+)
+
+// Check returns err, or an error holding the status and body of details if the API
+// returned an unsuccessful status
+func Check(details *victorops.RequestDetails, err error) error {
+	if err != nil {
+		return err
+	}
+	if details != nil && details.StatusCode >= 400 {
+		return fmt.Errorf("API returned %d: %s", details.StatusCode, strings.TrimSpace(details.ResponseBody))
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+// Command mockgen generates the victoropsmock package from the service interfaces of the
+// victorops package. Each interface gets a mock struct with a func field per method.
+//
+//	go run ./internal/mockgen -source victorops/services.go -out victoropsmock/mocks.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+func main() {
+	source := flag.String("source", "", "file declaring the interfaces to mock")
+	out := flag.String("out", "", "file to write the mocks to")
+	pkg := flag.String("package", "victoropsmock", "package of the mocks")
+	flag.Parse()
+	if *source == "" || *out == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	src, err := ioutil.ReadFile(*source)
+	if err == nil {
+		var generated []byte
+		generated, err = generate(filepath.Base(*source), src, *pkg)
+		if err == nil {
+			err = ioutil.WriteFile(*out, generated, 0644)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mockgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// mock is an interface to generate a mock of
+type mock struct {
+	Name    string
+	Methods []method
+}
+
+// method is a method of a mocked interface, with its types qualified by the source package
+type method struct {
+	Name     string
+	Params   string
+	Results  string
+	Args     string
+	CallArgs string
+	Returns  bool
+}
+
+// generate returns the formatted source of mocks of the interfaces declared in src
+func generate(filename string, src []byte, pkg string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, 0)
+	if err != nil {
+		return nil, err
+	}
+	sourcePkg := file.Name.Name
+
+	data := struct {
+		Source    string
+		Package   string
+		SourcePkg string
+		Imports   []string
+		Mocks     []mock
+	}{Source: filename, Package: pkg, SourcePkg: sourcePkg}
+
+	for _, imp := range file.Imports {
+		data.Imports = append(data.Imports, imp.Path.Value)
+	}
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			iface, ok := typeSpec.Type.(*ast.InterfaceType)
+			if !ok || !typeSpec.Name.IsExported() {
+				continue
+			}
+			m := mock{Name: typeSpec.Name.Name}
+			for _, field := range iface.Methods.List {
+				fn, ok := field.Type.(*ast.FuncType)
+				if !ok {
+					return nil, fmt.Errorf("%s: embedded interfaces are not supported", m.Name)
+				}
+				m.Methods = append(m.Methods, newMethod(fset, field.Names[0].Name, fn, sourcePkg))
+			}
+			data.Mocks = append(data.Mocks, m)
+		}
+	}
+	if len(data.Mocks) == 0 {
+		return nil, fmt.Errorf("%s declares no interfaces", filename)
+	}
+
+	var buf bytes.Buffer
+	err = mocksTemplate.Execute(&buf, data)
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+func newMethod(fset *token.FileSet, name string, fn *ast.FuncType, pkg string) method {
+	m := method{Name: name}
+
+	var params, args, callArgs []string
+	i := 0
+	for _, field := range fn.Params.List {
+		typ := typeString(fset, qualify(field.Type, pkg))
+		names := []string{}
+		for _, n := range field.Names {
+			names = append(names, n.Name)
+		}
+		if len(names) == 0 {
+			names = append(names, fmt.Sprintf("p%d", i))
+		}
+		for _, n := range names {
+			params = append(params, n+" "+typ)
+			args = append(args, n)
+			if _, variadic := field.Type.(*ast.Ellipsis); variadic {
+				n += "..."
+			}
+			callArgs = append(callArgs, n)
+			i++
+		}
+	}
+	m.Params = strings.Join(params, ", ")
+	m.Args = strings.Join(args, ", ")
+	m.CallArgs = strings.Join(callArgs, ", ")
+
+	if fn.Results != nil {
+		var results []string
+		for _, field := range fn.Results.List {
+			typ := typeString(fset, qualify(field.Type, pkg))
+			for range field.Names {
+				results = append(results, typ)
+			}
+			if len(field.Names) == 0 {
+				results = append(results, typ)
+			}
+		}
+		m.Returns = len(results) > 0
+		m.Results = strings.Join(results, ", ")
+		if len(results) > 1 {
+			m.Results = "(" + m.Results + ")"
+		}
+	}
+	return m
+}
+
+// qualify returns a copy of expr with the exported identifiers of the source package
+// prefixed by its name
+func qualify(expr ast.Expr, pkg string) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if e.IsExported() {
+			return &ast.SelectorExpr{X: ast.NewIdent(pkg), Sel: ast.NewIdent(e.Name)}
+		}
+		return e
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: qualify(e.X, pkg)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: e.Len, Elt: qualify(e.Elt, pkg)}
+	case *ast.MapType:
+		return &ast.MapType{Key: qualify(e.Key, pkg), Value: qualify(e.Value, pkg)}
+	case *ast.ChanType:
+		return &ast.ChanType{Dir: e.Dir, Value: qualify(e.Value, pkg)}
+	case *ast.Ellipsis:
+		return &ast.Ellipsis{Elt: qualify(e.Elt, pkg)}
+	case *ast.FuncType:
+		return &ast.FuncType{Params: qualifyFields(e.Params, pkg), Results: qualifyFields(e.Results, pkg)}
+	}
+	return expr
+}
+
+func qualifyFields(fields *ast.FieldList, pkg string) *ast.FieldList {
+	if fields == nil {
+		return nil
+	}
+	qualified := &ast.FieldList{}
+	for _, field := range fields.List {
+		qualified.List = append(qualified.List, &ast.Field{Names: field.Names, Type: qualify(field.Type, pkg)})
+	}
+	return qualified
+}
+
+func typeString(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	format.Node(&buf, fset, expr)
+	return buf.String()
+}
+
+var mocksTemplate = template.Must(template.New("mocks").Parse(`// Code generated by internal/mockgen from {{.SourcePkg}}/{{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	{{.}}
+{{- end}}
+{{if .Imports}}
+{{end -}}
+	"github.com/victorops/go-victorops/{{.SourcePkg}}"
+)
+{{range $mock := .Mocks}}
+// {{.Name}} is a mock of {{$.SourcePkg}}.{{.Name}}. Calling a method whose func field is
+// nil panics.
+type {{.Name}} struct {
+	recorder
+{{range .Methods}}
+	{{.Name}}Func func({{.Params}}) {{.Results}}
+{{- end}}
+}
+
+var _ {{$.SourcePkg}}.{{.Name}} = (*{{.Name}})(nil)
+{{range .Methods}}
+// {{.Name}} records the call and calls {{.Name}}Func
+func (m *{{$mock.Name}}) {{.Name}}({{.Params}}) {{.Results}} {
+	m.record("{{.Name}}"{{if .Args}}, {{.Args}}{{end}})
+	if m.{{.Name}}Func == nil {
+		panic("{{$.Package}}: unexpected call to {{$mock.Name}}.{{.Name}}")
+	}
+	{{if .Returns}}return {{end}}m.{{.Name}}Func({{.CallArgs}})
+}
+{{end}}{{end}}`))
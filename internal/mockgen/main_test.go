@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestMocksUpToDate(t *testing.T) {
+	src, err := ioutil.ReadFile("../../victorops/services.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := generate("services.go", src, "victoropsmock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadFile("../../victoropsmock/mocks.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("victoropsmock/mocks.go is out of date, run go generate ./victoropsmock")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	src := []byte(`package victorops
+
+type Service interface {
+	Get(id string, opts ...Option) (*Thing, error)
+	Ping()
+	Each(func(Thing) bool) map[string][]Thing
+}
+
+type unexported interface {
+	Hidden()
+}
+`)
+	out, err := generate("service.go", src, "mocks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"type Service struct",
+		"GetFunc  func(id string, opts ...victorops.Option) (*victorops.Thing, error)",
+		"return m.GetFunc(id, opts...)",
+		"\tm.PingFunc()\n",
+		"func (m *Service) Each(p0 func(victorops.Thing) bool) map[string][]victorops.Thing {",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("generated source is missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(string(out), "Hidden") {
+		t.Errorf("generated a mock of an unexported interface:\n%s", out)
+	}
+
+	_, err = generate("service.go", []byte("package victorops\n\ntype Thing struct{}\n"), "mocks")
+	if err == nil {
+		t.Error("expected an error for a file without interfaces")
+	}
+}
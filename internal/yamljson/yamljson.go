@@ -0,0 +1,121 @@
+// Package yamljson reads and writes YAML documents through the JSON tags of Go types, so
+// the API structs of this module can be used in YAML files without duplicating their tags.
+package yamljson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Unmarshal decodes a YAML or JSON document into v using v's JSON tags. Unknown fields
+// are rejected so typos in hand written documents are caught.
+func Unmarshal(data []byte, v interface{}) error {
+	var document interface{}
+	err := yaml.Unmarshal(data, &document)
+	if err != nil {
+		return err
+	}
+
+	document, err = toJSONCompatible(document)
+	if err != nil {
+		return err
+	}
+
+	jsonDocument, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonDocument))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// Marshal encodes v as YAML using its JSON tags. Map keys are sorted, so the output is
+// deterministic.
+func Marshal(v interface{}) ([]byte, error) {
+	jsonDocument, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode numbers as json.Number so large integers aren't written as floats
+	var document interface{}
+	decoder := json.NewDecoder(bytes.NewReader(jsonDocument))
+	decoder.UseNumber()
+	err = decoder.Decode(&document)
+	if err != nil {
+		return nil, err
+	}
+	document = fromJSONNumbers(document)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	err = encoder.Encode(document)
+	if err != nil {
+		return nil, err
+	}
+	err = encoder.Close()
+	return buf.Bytes(), err
+}
+
+// toJSONCompatible converts the map[interface{}]interface{} values YAML may produce for
+// non string keys into map[string]interface{}
+func toJSONCompatible(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			converted, err := toJSONCompatible(item)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = converted
+		}
+		return v, nil
+	case map[interface{}]interface{}:
+		converted := map[string]interface{}{}
+		for key, item := range v {
+			convertedItem, err := toJSONCompatible(item)
+			if err != nil {
+				return nil, err
+			}
+			converted[fmt.Sprint(key)] = convertedItem
+		}
+		return converted, nil
+	case []interface{}:
+		for i, item := range v {
+			converted, err := toJSONCompatible(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+		return v, nil
+	}
+	return value, nil
+}
+
+// fromJSONNumbers converts the json.Number values of a decoded document to int64 or float64
+func fromJSONNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = fromJSONNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = fromJSONNumbers(item)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return value
+}
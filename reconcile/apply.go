@@ -0,0 +1,89 @@
+package reconcile
+
+import (
+	"fmt"
+)
+
+// Result is what was done by applying a plan
+type Result struct {
+	// Applied are the changes that succeeded, in the order they were applied
+	Applied []Change
+	// TeamSlugs maps the names of the created teams to their new slugs
+	TeamSlugs map[string]string
+	// PolicySlugs maps the created escalation policies to their new slugs
+	PolicySlugs map[PolicyRef]string
+}
+
+type applier struct {
+	client   Client
+	opts     Options
+	teams    map[string]string
+	policies map[PolicyRef]string
+	result   *Result
+}
+
+func (a *applier) policySlug(ref PolicyRef) string {
+	if slug, ok := a.policies[ref]; ok {
+		return slug
+	}
+	// Policies outside of the org are referenced by slug, see Live.policyRef
+	return ref.Name
+}
+
+func (a *applier) policySlugList(refs []PolicyRef) []string {
+	slugs := []string{}
+	for _, ref := range refs {
+		slugs = append(slugs, a.policySlug(ref))
+	}
+	return slugs
+}
+
+// Apply makes the changes of the plan in order. It stops at the first change that fails
+// and returns what was applied until then along with the error.
+func (p Plan) Apply(client Client) (*Result, error) {
+	a := applier{
+		client:   client,
+		opts:     p.opts,
+		teams:    map[string]string{},
+		policies: map[PolicyRef]string{},
+		result:   &Result{TeamSlugs: map[string]string{}, PolicySlugs: map[PolicyRef]string{}},
+	}
+	if p.live != nil {
+		for name, slug := range p.live.TeamSlugs {
+			a.teams[name] = slug
+		}
+		for ref, slug := range p.live.PolicySlugs {
+			a.policies[ref] = slug
+		}
+	}
+
+	for _, change := range p.Changes {
+		err := change.apply(&a)
+		if err != nil {
+			return a.result, fmt.Errorf("failed to %s %s %s: %w", change.Action, change.Kind, change.Name, err)
+		}
+		a.result.Applied = append(a.result.Applied, change)
+	}
+
+	return a.result, nil
+}
+
+// Reconcile fetches the live org, plans the changes needed to reach the desired state and
+// applies them unless opts.DryRun is set. The result is nil for dry runs.
+func Reconcile(client Client, desired State, opts Options) (*Plan, *Result, error) {
+	live, err := FetchLive(client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plan, err := NewPlan(desired, live, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.DryRun {
+		return plan, nil, nil
+	}
+
+	result, err := plan.Apply(client)
+	return plan, result, err
+}
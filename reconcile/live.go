@@ -0,0 +1,158 @@
+package reconcile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/victorops/go-victorops/internal/apierror"
+	"github.com/victorops/go-victorops/victorops"
+)
+
+// Client is the part of *victorops.Client used by the reconciler
+type Client interface {
+	GetAllUserV2() (*victorops.UserListV2, *victorops.RequestDetails, error)
+	CreateUser(user *victorops.User) (*victorops.User, *victorops.RequestDetails, error)
+	UpdateUser(user *victorops.User) (*victorops.User, *victorops.RequestDetails, error)
+	DeleteUser(username string, replacementUser string) (*victorops.RequestDetails, error)
+
+	GetAllContacts(username string) (*victorops.AllContactResponse, *victorops.RequestDetails, error)
+	CreateContact(username string, contact *victorops.Contact) (*victorops.Contact, *victorops.RequestDetails, error)
+	DeleteContact(username string, contactExtID string, contactType victorops.ContactType) (*victorops.RequestDetails, error)
+
+	GetAllTeams() (*[]victorops.Team, *victorops.RequestDetails, error)
+	CreateTeam(team *victorops.Team) (*victorops.Team, *victorops.RequestDetails, error)
+	DeleteTeam(teamID string) (*victorops.RequestDetails, error)
+	GetTeamMembers(teamID string) (*victorops.TeamMembers, *victorops.RequestDetails, error)
+	AddTeamMember(teamID string, username string) (*victorops.RequestDetails, error)
+	RemoveTeamMember(teamID string, username string, replacement string) (*victorops.RequestDetails, error)
+
+	GetAllEscalationPolicies() (*victorops.EscalationPolicyList, *victorops.RequestDetails, error)
+	GetEscalationPolicy(escalationPolicyID string) (*victorops.EscalationPolicy, *victorops.RequestDetails, error)
+	CreateEscalationPolicy(escalationPolicy *victorops.EscalationPolicy) (*victorops.EscalationPolicy, *victorops.RequestDetails, error)
+	UpdateEscalationPolicy(escalationPolicy *victorops.EscalationPolicy) (*victorops.EscalationPolicy, *victorops.RequestDetails, error)
+	DeleteEscalationPolicy(escalationPolicyID string) (*victorops.RequestDetails, error)
+
+	GetAllRoutingKeys() (*victorops.RoutingKeyList, *victorops.RequestDetails, error)
+	CreateRoutingKey(routingKey *victorops.RoutingKey) (*victorops.RoutingKey, *victorops.RequestDetails, error)
+	UpdateRoutingKeyTargets(keyname string, policySlugs []string) (*victorops.RoutingKey, *victorops.RequestDetails, error)
+	DeleteRoutingKey(keyname string) (*victorops.RequestDetails, error)
+}
+
+// Live is the current state of the org along with the slugs and IDs needed to change it
+type Live struct {
+	State State
+
+	// TeamSlugs maps team names to slugs
+	TeamSlugs map[string]string
+	// PolicySlugs maps policy references to slugs
+	PolicySlugs map[PolicyRef]string
+	// ContactIDs maps usernames and contact keys to contact ext IDs
+	ContactIDs map[string]map[string]string
+
+	// The default team and routing key can't be deleted, so they are never pruned
+	defaultTeam       string
+	defaultRoutingKey string
+}
+
+func (l *Live) policyRef(slug string) PolicyRef {
+	for ref, s := range l.PolicySlugs {
+		if s == slug {
+			return ref
+		}
+	}
+	// Policies outside of the org can't be named, keep the slug so the difference shows
+	return PolicyRef{Name: slug}
+}
+
+// FetchLive reads the current state of the org
+func FetchLive(client Client) (*Live, error) {
+	live := Live{
+		TeamSlugs:   map[string]string{},
+		PolicySlugs: map[PolicyRef]string{},
+		ContactIDs:  map[string]map[string]string{},
+	}
+
+	users, details, err := client.GetAllUserV2()
+	if err := apierror.Check(details, err); err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	for _, u := range users.Users {
+		user := User{
+			Username:  strings.ToLower(u.Username),
+			FirstName: u.FirstName,
+			LastName:  u.LastName,
+			Email:     u.Email,
+			Admin:     u.Admin,
+		}
+
+		contacts, details, err := client.GetAllContacts(u.Username)
+		if err := apierror.Check(details, err); err != nil {
+			return nil, fmt.Errorf("failed to list contacts of %s: %w", u.Username, err)
+		}
+		live.ContactIDs[user.Username] = map[string]string{}
+		for contactType, group := range map[string]victorops.ContactGroup{ContactPhone: contacts.Phones, ContactEmail: contacts.Emails} {
+			for _, c := range group.ContactMethods {
+				contact := Contact{Type: contactType, Value: c.Value, Label: c.Label}
+				user.Contacts = append(user.Contacts, contact)
+				live.ContactIDs[user.Username][contact.key()] = c.ExtID
+			}
+		}
+
+		live.State.Users = append(live.State.Users, user)
+	}
+
+	teams, details, err := client.GetAllTeams()
+	if err := apierror.Check(details, err); err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+	for _, t := range *teams {
+		team := Team{Name: t.Name}
+		live.TeamSlugs[t.Name] = t.Slug
+		if t.IsDefaultTeam {
+			live.defaultTeam = t.Name
+		}
+
+		members, details, err := client.GetTeamMembers(t.Slug)
+		if err := apierror.Check(details, err); err != nil {
+			return nil, fmt.Errorf("failed to list members of team %s: %w", t.Name, err)
+		}
+		for _, member := range members.Members {
+			team.Members = append(team.Members, strings.ToLower(member.Username))
+		}
+
+		live.State.Teams = append(live.State.Teams, team)
+	}
+
+	policyList, details, err := client.GetAllEscalationPolicies()
+	if err := apierror.Check(details, err); err != nil {
+		return nil, fmt.Errorf("failed to list escalation policies: %w", err)
+	}
+	for _, element := range policyList.Policies {
+		live.PolicySlugs[PolicyRef{Team: element.Team.Name, Name: element.Policy.Name}] = element.Policy.Slug
+	}
+	for _, element := range policyList.Policies {
+		policy, details, err := client.GetEscalationPolicy(element.Policy.Slug)
+		if err := apierror.Check(details, err); err != nil {
+			return nil, fmt.Errorf("failed to get escalation policy %s: %w", element.Policy.Slug, err)
+		}
+		live.State.EscalationPolicies = append(live.State.EscalationPolicies, PolicyFromAPI(*policy, element.Team.Name, live.policyRef))
+	}
+
+	routingKeys, details, err := client.GetAllRoutingKeys()
+	if err := apierror.Check(details, err); err != nil {
+		return nil, fmt.Errorf("failed to list routing keys: %w", err)
+	}
+	for _, rk := range routingKeys.RoutingKeys {
+		key := RoutingKey{Name: rk.RoutingKey, Targets: []PolicyRef{}}
+		if rk.IsDefault {
+			live.defaultRoutingKey = rk.RoutingKey
+		}
+		for _, target := range rk.Targets {
+			key.Targets = append(key.Targets, live.policyRef(target.PolicySlug))
+		}
+		live.State.RoutingKeys = append(live.State.RoutingKeys, key)
+	}
+
+	live.State.normalize()
+	return &live, nil
+}
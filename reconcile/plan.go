@@ -0,0 +1,516 @@
+package reconcile
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/victorops/go-victorops/internal/apierror"
+	"github.com/victorops/go-victorops/victorops"
+)
+
+// Action is what a change does to a resource
+type Action string
+
+// The actions of a plan
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+var actionSymbols = map[Action]string{
+	ActionCreate: "+",
+	ActionUpdate: "~",
+	ActionDelete: "-",
+}
+
+// The kinds of resources that are reconciled
+const (
+	KindUser             = "user"
+	KindContact          = "contact"
+	KindTeam             = "team"
+	KindTeamMember       = "team member"
+	KindEscalationPolicy = "escalation policy"
+	KindRoutingKey       = "routing key"
+)
+
+// Changes are applied by phase, so that resources exist before they are referenced and
+// are no longer referenced when they are deleted
+const (
+	phaseUsers = iota
+	phaseContacts
+	phaseTeams
+	phaseMembers
+	phasePolicies
+	phaseRoutingKeys
+	phaseDeleteRoutingKeys
+	phaseDeletePolicies
+	phaseDeleteMembers
+	phaseDeleteTeams
+	phaseDeleteContacts
+	phaseDeleteUsers
+)
+
+// Change is a single create, update or delete of a resource
+type Change struct {
+	Action Action
+	Kind   string
+	Name   string
+	// Detail describes what is changed by updates
+	Detail string
+
+	phase int
+	apply func(a *applier) error
+}
+
+func (c Change) String() string {
+	line := fmt.Sprintf("%s %s %s %s", actionSymbols[c.Action], c.Action, c.Kind, c.Name)
+	if c.Detail != "" {
+		line += ": " + c.Detail
+	}
+	return line
+}
+
+// Plan is the ordered list of changes bringing the live org to the desired state
+type Plan struct {
+	Changes []Change
+
+	live *Live
+	opts Options
+}
+
+// Options controls what a plan changes
+type Options struct {
+	// Prune deletes the users, contact methods, teams, escalation policies and routing keys
+	// of the org that aren't in the desired state. Members of the declared teams are always
+	// removed when they aren't in the desired state. The email contact method created
+	// along with each user is never deleted.
+	Prune bool
+	// ReplacementUser takes over the on-call shifts of removed team members and deleted
+	// users, and is required when the plan removes any
+	ReplacementUser string
+	// CreateOnly only creates what is missing from the org, e.g. to restore deleted
+	// resources, and leaves everything that exists as it is
+	CreateOnly bool
+	// DryRun makes Reconcile return the plan without applying it
+	DryRun bool
+}
+
+// IsEmpty returns true if the live org is already in the desired state
+func (p Plan) IsEmpty() bool {
+	return len(p.Changes) == 0
+}
+
+// String returns the plan with one change per line, prefixed with + for creates, ~ for
+// updates and - for deletes
+func (p Plan) String() string {
+	if p.IsEmpty() {
+		return "No changes.\n"
+	}
+
+	var b strings.Builder
+	counts := map[Action]int{}
+	for _, change := range p.Changes {
+		fmt.Fprintln(&b, change)
+		counts[change.Action]++
+	}
+	fmt.Fprintf(&b, "\nPlan: %d to create, %d to update, %d to delete.\n", counts[ActionCreate], counts[ActionUpdate], counts[ActionDelete])
+	return b.String()
+}
+
+func (p *Plan) add(change Change) {
+	p.Changes = append(p.Changes, change)
+}
+
+// NewPlan compares the desired state with the live org and returns the changes to apply.
+// It fails if the desired state can't be reached, e.g. when new policies hand off to each
+// other in a cycle.
+func NewPlan(desired State, live *Live, opts Options) (*Plan, error) {
+	desired.normalize()
+	plan := Plan{live: live, opts: opts}
+
+	plan.planUsers(desired, live)
+	plan.planTeams(desired, live)
+	err := plan.planPolicies(desired, live)
+	if err != nil {
+		return nil, err
+	}
+	plan.planRoutingKeys(desired, live)
+
+	if opts.CreateOnly {
+		var creates []Change
+		for _, change := range plan.Changes {
+			if change.Action == ActionCreate {
+				creates = append(creates, change)
+			}
+		}
+		plan.Changes = creates
+	}
+
+	if opts.ReplacementUser == "" {
+		for _, change := range plan.Changes {
+			if change.Action == ActionDelete && (change.Kind == KindUser || change.Kind == KindTeamMember) {
+				return nil, fmt.Errorf("a replacement user is needed to %s %s", change.Action, change.Kind)
+			}
+		}
+	}
+
+	sort.SliceStable(plan.Changes, func(a, b int) bool { return plan.Changes[a].phase < plan.Changes[b].phase })
+	return &plan, nil
+}
+
+func missingUsers(users []User, desired []User) []User {
+	declared := map[string]bool{}
+	for _, user := range desired {
+		declared[user.Username] = true
+	}
+
+	var missing []User
+	for _, user := range users {
+		if !declared[user.Username] {
+			missing = append(missing, user)
+		}
+	}
+	return missing
+}
+
+func (p *Plan) planUsers(desired State, live *Live) {
+	liveUsers := map[string]User{}
+	for _, user := range live.State.Users {
+		liveUsers[user.Username] = user
+	}
+
+	for _, user := range desired.Users {
+		user := user
+		apiUser := &victorops.User{
+			Username:  user.Username,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Email:     user.Email,
+			Admin:     user.Admin,
+		}
+
+		current, exists := liveUsers[user.Username]
+		if !exists {
+			p.add(Change{Action: ActionCreate, Kind: KindUser, Name: user.Username, phase: phaseUsers, apply: func(a *applier) error {
+				_, details, err := a.client.CreateUser(apiUser)
+				return apierror.Check(details, err)
+			}})
+		} else if fields := changedUserFields(current, user); len(fields) > 0 {
+			p.add(Change{Action: ActionUpdate, Kind: KindUser, Name: user.Username, Detail: strings.Join(fields, ", "), phase: phaseUsers, apply: func(a *applier) error {
+				_, details, err := a.client.UpdateUser(apiUser)
+				return apierror.Check(details, err)
+			}})
+		}
+
+		p.planContacts(user, current, live.ContactIDs[user.Username])
+	}
+
+	if p.opts.Prune {
+		for _, user := range missingUsers(live.State.Users, desired.Users) {
+			username := user.Username
+			p.add(Change{Action: ActionDelete, Kind: KindUser, Name: username, phase: phaseDeleteUsers, apply: func(a *applier) error {
+				return apierror.Check(a.client.DeleteUser(username, a.opts.ReplacementUser))
+			}})
+		}
+	}
+}
+
+func changedUserFields(current User, desired User) []string {
+	var fields []string
+	if current.FirstName != desired.FirstName {
+		fields = append(fields, "firstName")
+	}
+	if current.LastName != desired.LastName {
+		fields = append(fields, "lastName")
+	}
+	if current.Email != desired.Email {
+		fields = append(fields, "email")
+	}
+	if current.Admin != desired.Admin {
+		fields = append(fields, "admin")
+	}
+	return fields
+}
+
+// planContacts adds the missing contact methods of a user by type and value and, when
+// pruning, removes the undeclared ones. Labels are only set when a contact is created.
+// The email contact method the API creates along with the user is left alone, since it
+// exists as soon as the user does.
+func (p *Plan) planContacts(user User, current User, extIDs map[string]string) {
+	isDefault := func(contact Contact) bool {
+		return contact.Type == ContactEmail &&
+			(strings.EqualFold(contact.Value, user.Email) || (current.Email != "" && strings.EqualFold(contact.Value, current.Email)))
+	}
+
+	existing := map[string]bool{}
+	for _, contact := range current.Contacts {
+		existing[contact.key()] = true
+	}
+	wanted := map[string]bool{}
+
+	for _, contact := range user.Contacts {
+		wanted[contact.key()] = true
+		if existing[contact.key()] || isDefault(contact) {
+			continue
+		}
+
+		apiContact := &victorops.Contact{Label: contact.Label}
+		if contact.Type == ContactPhone {
+			apiContact.PhoneNumber = contact.Value
+		} else {
+			apiContact.Email = contact.Value
+		}
+		username := user.Username
+		p.add(Change{Action: ActionCreate, Kind: KindContact, Name: username + " " + contact.key(), phase: phaseContacts, apply: func(a *applier) error {
+			_, details, err := a.client.CreateContact(username, apiContact)
+			return apierror.Check(details, err)
+		}})
+	}
+
+	if !p.opts.Prune {
+		return
+	}
+	for _, contact := range current.Contacts {
+		if wanted[contact.key()] || isDefault(contact) {
+			continue
+		}
+
+		contactType := victorops.GetContactTypes().Email
+		if contact.Type == ContactPhone {
+			contactType = victorops.GetContactTypes().Phone
+		}
+		username, extID := user.Username, extIDs[contact.key()]
+		p.add(Change{Action: ActionDelete, Kind: KindContact, Name: username + " " + contact.key(), phase: phaseDeleteContacts, apply: func(a *applier) error {
+			return apierror.Check(a.client.DeleteContact(username, extID, contactType))
+		}})
+	}
+}
+
+func (p *Plan) planTeams(desired State, live *Live) {
+	liveTeams := map[string]Team{}
+	for _, team := range live.State.Teams {
+		liveTeams[team.Name] = team
+	}
+	declared := map[string]bool{}
+
+	for _, team := range desired.Teams {
+		name := team.Name
+		declared[name] = true
+
+		current, exists := liveTeams[name]
+		if !exists {
+			p.add(Change{Action: ActionCreate, Kind: KindTeam, Name: name, phase: phaseTeams, apply: func(a *applier) error {
+				created, details, err := a.client.CreateTeam(&victorops.Team{Name: name})
+				if err := apierror.Check(details, err); err != nil {
+					return err
+				}
+				a.teams[name] = created.Slug
+				a.result.TeamSlugs[name] = created.Slug
+				return nil
+			}})
+		}
+
+		members := map[string]bool{}
+		for _, member := range current.Members {
+			members[member] = true
+		}
+		wanted := map[string]bool{}
+		for _, member := range team.Members {
+			member := member
+			wanted[member] = true
+			if members[member] {
+				continue
+			}
+			p.add(Change{Action: ActionCreate, Kind: KindTeamMember, Name: name + "/" + member, phase: phaseMembers, apply: func(a *applier) error {
+				return apierror.Check(a.client.AddTeamMember(a.teams[name], member))
+			}})
+		}
+		for _, member := range current.Members {
+			member := member
+			if wanted[member] {
+				continue
+			}
+			p.add(Change{Action: ActionDelete, Kind: KindTeamMember, Name: name + "/" + member, phase: phaseDeleteMembers, apply: func(a *applier) error {
+				return apierror.Check(a.client.RemoveTeamMember(a.teams[name], member, a.opts.ReplacementUser))
+			}})
+		}
+	}
+
+	if p.opts.Prune {
+		for _, team := range live.State.Teams {
+			if declared[team.Name] || team.Name == live.defaultTeam {
+				continue
+			}
+			slug := live.TeamSlugs[team.Name]
+			p.add(Change{Action: ActionDelete, Kind: KindTeam, Name: team.Name, phase: phaseDeleteTeams, apply: func(a *applier) error {
+				return apierror.Check(a.client.DeleteTeam(slug))
+			}})
+		}
+	}
+}
+
+// policyTargets returns the policies a policy hands off to
+func policyTargets(policy EscalationPolicy) []PolicyRef {
+	var targets []PolicyRef
+	for _, step := range policy.Steps {
+		for _, entry := range step.Entries {
+			if entry.Type == victorops.ExecutionTypePolicyRouting && entry.Policy != nil {
+				targets = append(targets, *entry.Policy)
+			}
+		}
+	}
+	return targets
+}
+
+func (p *Plan) planPolicies(desired State, live *Live) error {
+	livePolicies := map[PolicyRef]EscalationPolicy{}
+	for _, policy := range live.State.EscalationPolicies {
+		livePolicies[policy.Ref()] = policy
+	}
+	declared := map[PolicyRef]bool{}
+	for _, policy := range desired.EscalationPolicies {
+		declared[policy.Ref()] = true
+	}
+
+	// New policies are created once the policies they hand off to exist
+	pending := map[PolicyRef]EscalationPolicy{}
+	for _, policy := range desired.EscalationPolicies {
+		if _, exists := livePolicies[policy.Ref()]; !exists {
+			pending[policy.Ref()] = policy
+		}
+	}
+	for _, policy := range desired.EscalationPolicies {
+		if _, ok := pending[policy.Ref()]; !ok {
+			continue
+		}
+		var ordered []EscalationPolicy
+		err := orderPolicies(policy, pending, map[PolicyRef]bool{}, &ordered)
+		if err != nil {
+			return err
+		}
+		for _, policy := range ordered {
+			policy := policy
+			p.add(Change{Action: ActionCreate, Kind: KindEscalationPolicy, Name: policy.Ref().String(), phase: phasePolicies, apply: func(a *applier) error {
+				created, details, err := a.client.CreateEscalationPolicy(policy.toAPI(a.teams[policy.Team], a.policySlug))
+				if err := apierror.Check(details, err); err != nil {
+					return err
+				}
+				a.policies[policy.Ref()] = created.ID
+				a.result.PolicySlugs[policy.Ref()] = created.ID
+				return nil
+			}})
+		}
+	}
+
+	// Updates follow the creates, since they may hand off to new policies
+	for _, policy := range desired.EscalationPolicies {
+		policy := policy
+		current, exists := livePolicies[policy.Ref()]
+		if !exists || reflect.DeepEqual(current, policy) {
+			continue
+		}
+		var fields []string
+		if current.IgnoreCustomPagingPolicies != policy.IgnoreCustomPagingPolicies {
+			fields = append(fields, "ignoreCustomPagingPolicies")
+		}
+		if !reflect.DeepEqual(current.Steps, policy.Steps) {
+			fields = append(fields, "steps")
+		}
+		slug := live.PolicySlugs[policy.Ref()]
+		p.add(Change{Action: ActionUpdate, Kind: KindEscalationPolicy, Name: policy.Ref().String(), Detail: strings.Join(fields, ", "), phase: phasePolicies, apply: func(a *applier) error {
+			apiPolicy := policy.toAPI(a.teams[policy.Team], a.policySlug)
+			apiPolicy.ID = slug
+			_, details, err := a.client.UpdateEscalationPolicy(apiPolicy)
+			return apierror.Check(details, err)
+		}})
+	}
+
+	if p.opts.Prune {
+		for _, policy := range live.State.EscalationPolicies {
+			if declared[policy.Ref()] {
+				continue
+			}
+			slug := live.PolicySlugs[policy.Ref()]
+			p.add(Change{Action: ActionDelete, Kind: KindEscalationPolicy, Name: policy.Ref().String(), phase: phaseDeletePolicies, apply: func(a *applier) error {
+				return apierror.Check(a.client.DeleteEscalationPolicy(slug))
+			}})
+		}
+	}
+
+	return nil
+}
+
+// orderPolicies appends policy to ordered after the pending policies it hands off to,
+// removing them from pending
+func orderPolicies(policy EscalationPolicy, pending map[PolicyRef]EscalationPolicy, visiting map[PolicyRef]bool, ordered *[]EscalationPolicy) error {
+	if visiting[policy.Ref()] {
+		return fmt.Errorf("new escalation policy %s hands off to itself through other new policies", policy.Ref())
+	}
+	visiting[policy.Ref()] = true
+
+	for _, target := range policyTargets(policy) {
+		if next, ok := pending[target]; ok {
+			err := orderPolicies(next, pending, visiting, ordered)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	delete(pending, policy.Ref())
+	*ordered = append(*ordered, policy)
+	return nil
+}
+
+func formatRefs(refs []PolicyRef) string {
+	var names []string
+	for _, ref := range refs {
+		names = append(names, ref.String())
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+func (p *Plan) planRoutingKeys(desired State, live *Live) {
+	liveKeys := map[string]RoutingKey{}
+	for _, key := range live.State.RoutingKeys {
+		liveKeys[key.Name] = key
+	}
+	declared := map[string]bool{}
+
+	for _, key := range desired.RoutingKeys {
+		key := key
+		declared[key.Name] = true
+
+		current, exists := liveKeys[key.Name]
+		if !exists {
+			p.add(Change{Action: ActionCreate, Kind: KindRoutingKey, Name: key.Name, phase: phaseRoutingKeys, apply: func(a *applier) error {
+				_, details, err := a.client.CreateRoutingKey(victorops.NewRoutingKey(key.Name, a.policySlugList(key.Targets)))
+				return apierror.Check(details, err)
+			}})
+		} else if !reflect.DeepEqual(current.Targets, key.Targets) {
+			detail := fmt.Sprintf("targets %s -> %s", formatRefs(current.Targets), formatRefs(key.Targets))
+			p.add(Change{Action: ActionUpdate, Kind: KindRoutingKey, Name: key.Name, Detail: detail, phase: phaseRoutingKeys, apply: func(a *applier) error {
+				_, details, err := a.client.UpdateRoutingKeyTargets(key.Name, a.policySlugList(key.Targets))
+				return apierror.Check(details, err)
+			}})
+		}
+	}
+
+	if p.opts.Prune {
+		for _, key := range live.State.RoutingKeys {
+			name := key.Name
+			if declared[name] || name == live.defaultRoutingKey {
+				continue
+			}
+			p.add(Change{Action: ActionDelete, Kind: KindRoutingKey, Name: name, phase: phaseDeleteRoutingKeys, apply: func(a *applier) error {
+				return apierror.Check(a.client.DeleteRoutingKey(name))
+			}})
+		}
+	}
+}
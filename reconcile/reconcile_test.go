@@ -0,0 +1,291 @@
+package reconcile
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/victorops/go-victorops/victorops"
+	"github.com/victorops/go-victorops/victoropstest"
+)
+
+func newTestServer(t *testing.T) (*victoropstest.Server, *victorops.Client) {
+	server := victoropstest.NewServer()
+	t.Cleanup(server.Close)
+	return server, server.Client()
+}
+
+func checkStatus(t *testing.T, details *victorops.RequestDetails, err error, want int) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details.StatusCode != want {
+		t.Fatalf("API returned %d, want %d: %s", details.StatusCode, want, details.ResponseBody)
+	}
+}
+
+const testDesiredState = `
+users:
+  - username: Alice
+    firstName: Alice
+    lastName: Smith
+    email: alice@example.com
+    contacts:
+      - type: email
+        value: alice@example.com
+      - type: phone
+        value: "+15555550100"
+  - username: bob
+    firstName: Bob
+    email: bob@example.com
+teams:
+  - name: Ops
+    members: [alice, bob]
+escalationPolicies:
+  - name: Primary
+    team: Ops
+    steps:
+      - timeout: 0
+        entries:
+          - type: user
+            user: alice
+      - timeout: 15
+        entries:
+          - type: policy_routing
+            policy: {team: Ops, name: Secondary}
+  - name: Secondary
+    team: Ops
+    steps:
+      - timeout: 0
+        entries:
+          - type: user
+            user: bob
+routingKeys:
+  - name: ops
+    targets:
+      - {team: Ops, name: Primary}
+`
+
+func loadTestState(t *testing.T) *State {
+	state, err := LoadState(strings.NewReader(testDesiredState))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return state
+}
+
+func TestLoadStateRejectsUndeclaredReferences(t *testing.T) {
+	_, err := LoadState(strings.NewReader(`
+teams:
+  - name: Ops
+    members: [carol]
+routingKeys:
+  - name: ops
+    targets:
+      - {team: Ops, name: Missing}
+`))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"member carol is not a declared user", "targets undeclared policy Ops/Missing"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+
+	_, err = LoadState(strings.NewReader("users:\n  - usrname: alice\n"))
+	if err == nil {
+		t.Error("expected unknown fields to be rejected")
+	}
+}
+
+func TestReconcileFromEmptyOrg(t *testing.T) {
+	server, client := newTestServer(t)
+	desired := loadTestState(t)
+
+	plan, result, err := Reconcile(client, *desired, Options{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, request := range server.Requests() {
+		if !strings.HasPrefix(request, "GET ") {
+			t.Errorf("dry run made change %s", request)
+		}
+	}
+	if result != nil {
+		t.Errorf("dry run returned result %+v", result)
+	}
+
+	// The declared email of alice is the one the API creates along with her
+	want := `+ create user alice
++ create user bob
++ create contact alice phone:+15555550100
++ create team Ops
++ create team member Ops/alice
++ create team member Ops/bob
++ create escalation policy Ops/Secondary
++ create escalation policy Ops/Primary
++ create routing key ops
+
+Plan: 9 to create, 0 to update, 0 to delete.
+`
+	if plan.String() != want {
+		t.Errorf("plan is\n%s\nwant\n%s", plan, want)
+	}
+
+	result, err = plan.Apply(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Applied) != 9 {
+		t.Errorf("applied %d changes, want 9", len(result.Applied))
+	}
+
+	teamSlug := result.TeamSlugs["Ops"]
+	primary := result.PolicySlugs[PolicyRef{Team: "Ops", Name: "Primary"}]
+	secondary := result.PolicySlugs[PolicyRef{Team: "Ops", Name: "Secondary"}]
+	if teamSlug == "" || primary == "" || secondary == "" {
+		t.Fatalf("missing created slugs in %+v", result)
+	}
+	policy, details, err := client.GetEscalationPolicy(primary)
+	checkStatus(t, details, err, http.StatusOK)
+	if got := policy.Steps[1].Entries[0].TargetPolicy.PolicySlug; got != secondary {
+		t.Errorf("primary hands off to %s, want %s", got, secondary)
+	}
+	key, _, err := client.GetRoutingKey("ops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := key.PolicySlugs(); !reflect.DeepEqual(got, []string{primary}) {
+		t.Errorf("routing key targets %v, want %v", got, []string{primary})
+	}
+
+	plan, _, err = Reconcile(client, *desired, Options{DryRun: true, Prune: true, ReplacementUser: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.IsEmpty() {
+		t.Errorf("expected no changes after apply, got\n%s", plan)
+	}
+}
+
+func TestReconcileUpdatesAndPrunes(t *testing.T) {
+	server, client := newTestServer(t)
+	desired := loadTestState(t)
+	_, _, err := Reconcile(client, *desired, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Drift the org away from the desired state
+	_, details, err := client.UpdateUser(&victorops.User{Username: "bob", FirstName: "Robert", Email: "bob@example.com"})
+	checkStatus(t, details, err, http.StatusOK)
+	_, details, err = client.CreateUser(&victorops.User{Username: "carol", Email: "carol@example.com"})
+	checkStatus(t, details, err, http.StatusOK)
+	_, details, err = client.CreateContact("alice", &victorops.Contact{Email: "old@example.com"})
+	checkStatus(t, details, err, http.StatusOK)
+	defaultTeam, details, err := client.CreateTeam(&victorops.Team{Name: "Default"})
+	checkStatus(t, details, err, http.StatusOK)
+	server.SetDefaultTeam(defaultTeam.Slug)
+	legacy, details, err := client.CreateTeam(&victorops.Team{Name: "Legacy"})
+	checkStatus(t, details, err, http.StatusOK)
+	_, details, err = client.CreateRoutingKey(victorops.NewRoutingKey("legacy", nil))
+	checkStatus(t, details, err, http.StatusOK)
+
+	live := mustFetchLive(t, client)
+	opsSlug := live.TeamSlugs["Ops"]
+	details, err = client.AddTeamMember(opsSlug, "carol")
+	checkStatus(t, details, err, http.StatusOK)
+	secondary, details, err := client.GetEscalationPolicy(live.PolicySlugs[PolicyRef{Team: "Ops", Name: "Secondary"}])
+	checkStatus(t, details, err, http.StatusOK)
+	secondary.Steps[0].Timeout = 5
+	_, details, err = client.UpdateEscalationPolicy(secondary)
+	checkStatus(t, details, err, http.StatusOK)
+
+	_, err = NewPlan(*desired, mustFetchLive(t, client), Options{})
+	if err == nil || !strings.Contains(err.Error(), "replacement user is needed to delete team member") {
+		t.Errorf("expected removing members without a replacement user to fail, got %v", err)
+	}
+
+	// Undeclared contacts are only removed when pruning
+	plan, err := NewPlan(*desired, mustFetchLive(t, client), Options{ReplacementUser: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, change := range plan.Changes {
+		if change.Kind == KindContact {
+			t.Errorf("planned %s without pruning", change)
+		}
+	}
+
+	plan, _, err = Reconcile(client, *desired, Options{Prune: true, ReplacementUser: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []string
+	for _, change := range plan.Changes {
+		lines = append(lines, change.String())
+	}
+	want := []string{
+		"~ update user bob: firstName",
+		"~ update escalation policy Ops/Secondary: steps",
+		"- delete routing key legacy",
+		"- delete team member Ops/carol",
+		"- delete team Legacy",
+		"- delete contact alice email:old@example.com",
+		"- delete user carol",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("plan is\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+
+	_, details, err = client.GetUser("carol")
+	checkStatus(t, details, err, http.StatusNotFound)
+	_, details, err = client.GetTeam(legacy.Slug)
+	checkStatus(t, details, err, http.StatusNotFound)
+	contacts, details, err := client.GetAllContacts("alice")
+	checkStatus(t, details, err, http.StatusOK)
+	if len(contacts.Emails.ContactMethods) != 1 || contacts.Emails.ContactMethods[0].Value != "alice@example.com" || len(contacts.Phones.ContactMethods) != 1 {
+		t.Errorf("unexpected contacts of alice %+v", contacts)
+	}
+
+	plan, _, err = Reconcile(client, *desired, Options{DryRun: true, Prune: true, ReplacementUser: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.IsEmpty() {
+		t.Errorf("expected no changes after apply, got\n%s", plan)
+	}
+}
+
+func TestApplyStopsAtFirstFailure(t *testing.T) {
+	server, client := newTestServer(t)
+	desired := State{
+		Users: []User{{Username: "alice", Email: "alice@example.com"}},
+		Teams: []Team{{Name: "Ops", Members: []string{"alice"}}},
+	}
+	server.InjectFault(victoropstest.Fault{Method: "POST", Path: "v1/team", StatusCode: http.StatusInternalServerError, Body: `{"error":"unavailable"}`})
+
+	plan, err := NewPlan(desired, mustFetchLive(t, client), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := plan.Apply(client)
+	if err == nil || !strings.Contains(err.Error(), "failed to create team Ops: API returned 500") {
+		t.Errorf("unexpected error %v", err)
+	}
+	if len(result.Applied) != 1 {
+		t.Errorf("applied %d changes, want 1", len(result.Applied))
+	}
+}
+
+func mustFetchLive(t *testing.T, client Client) *Live {
+	live, err := FetchLive(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return live
+}
@@ -0,0 +1,307 @@
+// Package reconcile brings a VictorOps org to a desired state described in a YAML or JSON
+// document. The desired state is compared with the live org to produce a plan, which can
+// be reviewed and then applied in dependency order.
+package reconcile
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/victorops/go-victorops/internal/yamljson"
+	"github.com/victorops/go-victorops/victorops"
+)
+
+// State is the desired state of a VictorOps org. Resources are referenced by name, since
+// slugs are only known once they have been created.
+type State struct {
+	Users              []User             `json:"users,omitempty"`
+	Teams              []Team             `json:"teams,omitempty"`
+	EscalationPolicies []EscalationPolicy `json:"escalationPolicies,omitempty"`
+	RoutingKeys        []RoutingKey       `json:"routingKeys,omitempty"`
+}
+
+// User is a user of the org and their contact methods
+type User struct {
+	Username  string    `json:"username"`
+	FirstName string    `json:"firstName,omitempty"`
+	LastName  string    `json:"lastName,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	Admin     bool      `json:"admin,omitempty"`
+	Contacts  []Contact `json:"contacts,omitempty"`
+}
+
+// Contact types that can be managed
+const (
+	ContactPhone = "phone"
+	ContactEmail = "email"
+)
+
+// Contact is a phone or email contact method of a user
+type Contact struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+}
+
+func (c Contact) key() string {
+	return c.Type + ":" + strings.ToLower(c.Value)
+}
+
+// Team is a team of the org and the usernames of its members
+type Team struct {
+	Name    string   `json:"name"`
+	Members []string `json:"members,omitempty"`
+}
+
+// PolicyRef references an escalation policy by the name of its team and its own name
+type PolicyRef struct {
+	Team string `json:"team"`
+	Name string `json:"name"`
+}
+
+func (r PolicyRef) String() string {
+	return r.Team + "/" + r.Name
+}
+
+// EscalationPolicy is an escalation policy of a team
+type EscalationPolicy struct {
+	Name                       string `json:"name"`
+	Team                       string `json:"team"`
+	IgnoreCustomPagingPolicies bool   `json:"ignoreCustomPagingPolicies,omitempty"`
+	Steps                      []Step `json:"steps"`
+}
+
+// Ref returns the reference to the policy
+func (p EscalationPolicy) Ref() PolicyRef {
+	return PolicyRef{Team: p.Team, Name: p.Name}
+}
+
+// Step is a step of an escalation policy, executed timeout minutes after the previous one
+type Step struct {
+	Timeout int     `json:"timeout"`
+	Entries []Entry `json:"entries"`
+}
+
+// Entry is a target notified in a step. Exactly one of the target fields matching Type is set.
+type Entry struct {
+	Type          victorops.ExecutionType `json:"type"`
+	User          string                  `json:"user,omitempty"`
+	RotationGroup string                  `json:"rotationGroup,omitempty"`
+	Webhook       string                  `json:"webhook,omitempty"`
+	Email         string                  `json:"email,omitempty"`
+	Policy        *PolicyRef              `json:"policy,omitempty"`
+}
+
+// RoutingKey is a routing key and the policies it targets
+type RoutingKey struct {
+	Name    string      `json:"name"`
+	Targets []PolicyRef `json:"targets"`
+}
+
+// LoadState reads a desired state document in YAML or JSON
+func LoadState(r io.Reader) (*State, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var state State
+	err = yamljson.Unmarshal(data, &state)
+	if err != nil {
+		return nil, err
+	}
+
+	state.normalize()
+	err = state.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// normalize sorts the lists of the state whose order doesn't matter, so states can be compared
+func (s *State) normalize() {
+	// Usernames are case insensitive
+	for i := range s.Users {
+		s.Users[i].Username = strings.ToLower(s.Users[i].Username)
+	}
+	for i := range s.EscalationPolicies {
+		for _, step := range s.EscalationPolicies[i].Steps {
+			for j := range step.Entries {
+				step.Entries[j].User = strings.ToLower(step.Entries[j].User)
+			}
+		}
+	}
+
+	sort.Slice(s.Users, func(a, b int) bool { return s.Users[a].Username < s.Users[b].Username })
+	for i := range s.Users {
+		contacts := s.Users[i].Contacts
+		sort.Slice(contacts, func(a, b int) bool { return contacts[a].key() < contacts[b].key() })
+	}
+	sort.Slice(s.Teams, func(a, b int) bool { return s.Teams[a].Name < s.Teams[b].Name })
+	for i := range s.Teams {
+		members := s.Teams[i].Members
+		for j := range members {
+			members[j] = strings.ToLower(members[j])
+		}
+		sort.Strings(members)
+	}
+	sort.Slice(s.EscalationPolicies, func(a, b int) bool {
+		return s.EscalationPolicies[a].Ref().String() < s.EscalationPolicies[b].Ref().String()
+	})
+	sort.Slice(s.RoutingKeys, func(a, b int) bool { return s.RoutingKeys[a].Name < s.RoutingKeys[b].Name })
+	for i := range s.RoutingKeys {
+		targets := s.RoutingKeys[i].Targets
+		sort.Slice(targets, func(a, b int) bool { return targets[a].String() < targets[b].String() })
+	}
+}
+
+// Validate checks that the state only references resources it declares, and that its
+// escalation policies are valid
+func (s State) Validate() error {
+	var problems []string
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	users := map[string]bool{}
+	for _, user := range s.Users {
+		if users[user.Username] {
+			problem("user %s is declared twice", user.Username)
+		}
+		users[user.Username] = true
+		for _, contact := range user.Contacts {
+			if contact.Type != ContactPhone && contact.Type != ContactEmail {
+				problem("contact %s of user %s has unknown type %q", contact.Value, user.Username, contact.Type)
+			}
+		}
+	}
+
+	teams := map[string]bool{}
+	for _, team := range s.Teams {
+		if teams[team.Name] {
+			problem("team %s is declared twice", team.Name)
+		}
+		teams[team.Name] = true
+		for _, member := range team.Members {
+			if !users[member] {
+				problem("team %s member %s is not a declared user", team.Name, member)
+			}
+		}
+	}
+
+	policies := map[PolicyRef]bool{}
+	for _, policy := range s.EscalationPolicies {
+		policies[policy.Ref()] = true
+	}
+	for _, policy := range s.EscalationPolicies {
+		if !teams[policy.Team] {
+			problem("escalation policy %s belongs to undeclared team %s", policy.Ref(), policy.Team)
+		}
+		for _, step := range policy.Steps {
+			for _, entry := range step.Entries {
+				if entry.Type == victorops.ExecutionTypeUser && !users[entry.User] {
+					problem("escalation policy %s notifies undeclared user %s", policy.Ref(), entry.User)
+				}
+				if entry.Type == victorops.ExecutionTypePolicyRouting && (entry.Policy == nil || !policies[*entry.Policy]) {
+					problem("escalation policy %s routes to an undeclared policy", policy.Ref())
+				}
+			}
+		}
+
+		// Validate the API shape of the policy with placeholder slugs
+		if err := policy.toAPI("team", func(PolicyRef) string { return "policy" }).Validate(); err != nil {
+			for _, p := range err.(*victorops.EscalationPolicyValidationError).Problems {
+				problem("escalation policy %s: %s", policy.Ref(), p)
+			}
+		}
+	}
+
+	for _, key := range s.RoutingKeys {
+		for _, target := range key.Targets {
+			if !policies[target] {
+				problem("routing key %s targets undeclared policy %s", key.Name, target)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid desired state:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// toAPI converts the policy to its API shape, resolving policy references with policySlug
+func (p EscalationPolicy) toAPI(teamSlug string, policySlug func(PolicyRef) string) *victorops.EscalationPolicy {
+	policy := victorops.EscalationPolicy{
+		Name:                       p.Name,
+		TeamID:                     teamSlug,
+		IgnoreCustomPagingPolicies: p.IgnoreCustomPagingPolicies,
+		Steps:                      []victorops.EscalationPolicySteps{},
+	}
+
+	for _, step := range p.Steps {
+		apiStep := victorops.EscalationPolicySteps{Timeout: step.Timeout, Entries: []victorops.EscalationPolicyStepEntry{}}
+		for _, entry := range step.Entries {
+			apiEntry := victorops.EscalationPolicyStepEntry{ExecutionType: entry.Type}
+			switch entry.Type {
+			case victorops.ExecutionTypeUser:
+				apiEntry.User = &victorops.EscalationPolicyUserTarget{Username: entry.User}
+			case victorops.ExecutionTypeRotationGroup, victorops.ExecutionTypeRotationGroupNext, victorops.ExecutionTypeRotationGroupPrevious:
+				apiEntry.RotationGroup = &victorops.EscalationPolicyRotationGroupTarget{Slug: entry.RotationGroup}
+			case victorops.ExecutionTypeWebhook:
+				apiEntry.Webhook = &victorops.EscalationPolicyWebhookTarget{Slug: entry.Webhook}
+			case victorops.ExecutionTypeEmail:
+				apiEntry.Email = &victorops.EscalationPolicyEmailTarget{Address: entry.Email}
+			case victorops.ExecutionTypePolicyRouting:
+				if entry.Policy != nil {
+					apiEntry.TargetPolicy = &victorops.EscalationPolicyPolicyTarget{PolicySlug: policySlug(*entry.Policy)}
+				}
+			}
+			apiStep.Entries = append(apiStep.Entries, apiEntry)
+		}
+		policy.Steps = append(policy.Steps, apiStep)
+	}
+
+	return &policy
+}
+
+// PolicyFromAPI converts a policy read from the API to the state shape. teamName is the
+// name of the policy's team, and policyRef resolves the slugs of the policies it hands
+// off to.
+func PolicyFromAPI(policy victorops.EscalationPolicy, teamName string, policyRef func(slug string) PolicyRef) EscalationPolicy {
+	p := EscalationPolicy{
+		Name:                       policy.Name,
+		Team:                       teamName,
+		IgnoreCustomPagingPolicies: policy.IgnoreCustomPagingPolicies,
+		Steps:                      []Step{},
+	}
+
+	for _, step := range policy.Steps {
+		s := Step{Timeout: step.Timeout, Entries: []Entry{}}
+		for _, apiEntry := range step.Entries {
+			entry := Entry{Type: apiEntry.ExecutionType}
+			switch {
+			case apiEntry.User != nil:
+				entry.User = strings.ToLower(apiEntry.User.Username)
+			case apiEntry.RotationGroup != nil:
+				entry.RotationGroup = apiEntry.RotationGroup.Slug
+			case apiEntry.Webhook != nil:
+				entry.Webhook = apiEntry.Webhook.Slug
+			case apiEntry.Email != nil:
+				entry.Email = apiEntry.Email.Address
+			case apiEntry.TargetPolicy != nil:
+				ref := policyRef(apiEntry.TargetPolicy.PolicySlug)
+				entry.Policy = &ref
+			}
+			s.Entries = append(s.Entries, entry)
+		}
+		p.Steps = append(p.Steps, s)
+	}
+
+	return p
+}
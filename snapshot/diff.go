@@ -0,0 +1,389 @@
+package snapshot
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/victorops/go-victorops/victorops"
+)
+
+// ChangeType is how a resource differs between two snapshots
+type ChangeType string
+
+// The types of changes found by Diff
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeRemoved ChangeType = "removed"
+	ChangeChanged ChangeType = "changed"
+	ChangeMoved   ChangeType = "moved"
+)
+
+var changeSymbols = map[ChangeType]string{
+	ChangeAdded:   "+",
+	ChangeRemoved: "-",
+	ChangeChanged: "~",
+	ChangeMoved:   ">",
+}
+
+// The kinds of resources compared by Diff
+const (
+	KindUser             = "user"
+	KindContact          = "contact"
+	KindTeam             = "team"
+	KindTeamMember       = "team member"
+	KindTeamAdmin        = "team admin"
+	KindEscalationPolicy = "escalation policy"
+	KindRoutingKey       = "routing key"
+)
+
+// Change is a single difference between two snapshots
+type Change struct {
+	Type   ChangeType `json:"type"`
+	Kind   string     `json:"kind"`
+	Name   string     `json:"name"`
+	Detail string     `json:"detail,omitempty"`
+}
+
+func (c Change) String() string {
+	line := fmt.Sprintf("%s %s %s", changeSymbols[c.Type], c.Kind, c.Name)
+	if c.Detail != "" {
+		line += ": " + c.Detail
+	}
+	return line
+}
+
+// Report lists the differences between two snapshots, both as readable changes and as a
+// JSON patch (RFC 6902) turning the old snapshot into the new one
+type Report struct {
+	Changes []Change         `json:"changes"`
+	Patch   []PatchOperation `json:"patch"`
+}
+
+// HasChanges returns true if the snapshots differ
+func (r Report) HasChanges() bool {
+	return len(r.Changes) > 0 || len(r.Patch) > 0
+}
+
+func (r Report) String() string {
+	if !r.HasChanges() {
+		return "No changes.\n"
+	}
+
+	var b strings.Builder
+	for _, change := range r.Changes {
+		fmt.Fprintln(&b, change)
+	}
+	return b.String()
+}
+
+// Diff compares two snapshots. Fields that change on their own, such as the creation
+// time of users, are ignored, and so are schedules since they move with every shift.
+func Diff(before *Snapshot, after *Snapshot) (*Report, error) {
+	d := differ{old: before, new: after}
+	d.users()
+	d.teams()
+	d.memberships()
+	d.policies()
+	d.routingKeys()
+
+	patch, err := Patch(before, after)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Report{Changes: d.changes, Patch: patch}, nil
+}
+
+type differ struct {
+	old     *Snapshot
+	new     *Snapshot
+	changes []Change
+}
+
+func (d *differ) add(changeType ChangeType, kind string, name string, detail string) {
+	d.changes = append(d.changes, Change{Type: changeType, Kind: kind, Name: name, Detail: detail})
+}
+
+// teamName returns the name and slug of a team, looked up in the new snapshot first
+func (d *differ) teamName(slug string) string {
+	if team, ok := d.new.Team(slug); ok {
+		return fmt.Sprintf("%s (%s)", team.Name, slug)
+	}
+	if team, ok := d.old.Team(slug); ok {
+		return fmt.Sprintf("%s (%s)", team.Name, slug)
+	}
+	return slug
+}
+
+// policyName returns the name and slug of a policy, looked up in the new snapshot first
+func (d *differ) policyName(slug string) string {
+	if policy, ok := d.new.EscalationPolicy(slug); ok {
+		return fmt.Sprintf("%s (%s)", policy.Name, slug)
+	}
+	if policy, ok := d.old.EscalationPolicy(slug); ok {
+		return fmt.Sprintf("%s (%s)", policy.Name, slug)
+	}
+	return slug
+}
+
+func changedField(fields []string, name string, before interface{}, after interface{}) []string {
+	if reflect.DeepEqual(before, after) {
+		return fields
+	}
+	if _, ok := before.(string); ok {
+		return append(fields, fmt.Sprintf("%s %q -> %q", name, before, after))
+	}
+	return append(fields, fmt.Sprintf("%s %v -> %v", name, before, after))
+}
+
+func (d *differ) users() {
+	for _, user := range d.old.Users {
+		if _, ok := d.new.User(user.Username); !ok {
+			d.add(ChangeRemoved, KindUser, user.Username, "")
+		}
+	}
+
+	for _, user := range d.new.Users {
+		old, ok := d.old.User(user.Username)
+		if !ok {
+			d.add(ChangeAdded, KindUser, user.Username, "")
+			continue
+		}
+
+		var fields []string
+		fields = changedField(fields, "firstName", old.FirstName, user.FirstName)
+		fields = changedField(fields, "lastName", old.LastName, user.LastName)
+		fields = changedField(fields, "email", old.Email, user.Email)
+		fields = changedField(fields, "admin", old.Admin, user.Admin)
+		if len(fields) > 0 {
+			d.add(ChangeChanged, KindUser, user.Username, strings.Join(fields, ", "))
+		}
+
+		d.contacts(user.Username, "phone", old.Contacts.Phones, user.Contacts.Phones)
+		d.contacts(user.Username, "email", old.Contacts.Emails, user.Contacts.Emails)
+		d.contacts(user.Username, "device", old.Contacts.Devices, user.Contacts.Devices)
+	}
+}
+
+func (d *differ) contacts(username string, contactType string, before victorops.ContactGroup, after victorops.ContactGroup) {
+	values := func(group victorops.ContactGroup) map[string]bool {
+		set := map[string]bool{}
+		for _, contact := range group.ContactMethods {
+			set[contact.Value] = true
+		}
+		return set
+	}
+	oldValues, newValues := values(before), values(after)
+
+	for _, value := range sortedKeys(oldValues) {
+		if !newValues[value] {
+			d.add(ChangeRemoved, KindContact, username, contactType+" "+value)
+		}
+	}
+	for _, value := range sortedKeys(newValues) {
+		if !oldValues[value] {
+			d.add(ChangeAdded, KindContact, username, contactType+" "+value)
+		}
+	}
+}
+
+func sortedKeys(set map[string]bool) []string {
+	var keys []string
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (d *differ) teams() {
+	for _, team := range d.old.Teams {
+		if _, ok := d.new.Team(team.Slug); !ok {
+			d.add(ChangeRemoved, KindTeam, fmt.Sprintf("%s (%s)", team.Name, team.Slug), "")
+		}
+	}
+
+	for _, team := range d.new.Teams {
+		old, ok := d.old.Team(team.Slug)
+		if !ok {
+			d.add(ChangeAdded, KindTeam, d.teamName(team.Slug), "")
+			continue
+		}
+		if old.Name != team.Name {
+			d.add(ChangeChanged, KindTeam, d.teamName(team.Slug), fmt.Sprintf("name %s -> %s", old.Name, team.Name))
+		}
+
+		oldAdmins, newAdmins := stringSet(old.Admins), stringSet(team.Admins)
+		for _, admin := range sortedKeys(oldAdmins) {
+			if !newAdmins[admin] {
+				d.add(ChangeRemoved, KindTeamAdmin, d.teamName(team.Slug), admin)
+			}
+		}
+		for _, admin := range sortedKeys(newAdmins) {
+			if !oldAdmins[admin] {
+				d.add(ChangeAdded, KindTeamAdmin, d.teamName(team.Slug), admin)
+			}
+		}
+	}
+}
+
+func stringSet(values []string) map[string]bool {
+	set := map[string]bool{}
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+// memberships reports the teams each user joined and left. A user leaving some teams and
+// joining others is reported as a single move.
+func (d *differ) memberships() {
+	teamsOf := func(s *Snapshot) map[string]map[string]bool {
+		teams := map[string]map[string]bool{}
+		for _, team := range s.Teams {
+			for _, member := range team.Members {
+				if teams[member] == nil {
+					teams[member] = map[string]bool{}
+				}
+				teams[member][team.Slug] = true
+			}
+		}
+		return teams
+	}
+	oldTeams, newTeams := teamsOf(d.old), teamsOf(d.new)
+
+	usernames := map[string]bool{}
+	for username := range oldTeams {
+		usernames[username] = true
+	}
+	for username := range newTeams {
+		usernames[username] = true
+	}
+
+	for _, username := range sortedKeys(usernames) {
+		var left, joined []string
+		for _, slug := range sortedKeys(oldTeams[username]) {
+			if !newTeams[username][slug] {
+				left = append(left, d.teamName(slug))
+			}
+		}
+		for _, slug := range sortedKeys(newTeams[username]) {
+			if !oldTeams[username][slug] {
+				joined = append(joined, d.teamName(slug))
+			}
+		}
+
+		switch {
+		case len(left) > 0 && len(joined) > 0:
+			d.add(ChangeMoved, KindTeamMember, username, strings.Join(left, ", ")+" -> "+strings.Join(joined, ", "))
+		case len(left) > 0:
+			d.add(ChangeRemoved, KindTeamMember, username, strings.Join(left, ", "))
+		case len(joined) > 0:
+			d.add(ChangeAdded, KindTeamMember, username, strings.Join(joined, ", "))
+		}
+	}
+}
+
+// describeEntry returns the execution type and target of a step entry
+func (d *differ) describeEntry(entry victorops.EscalationPolicyStepEntry) string {
+	target := ""
+	switch {
+	case entry.User != nil:
+		target = entry.User.Username
+	case entry.RotationGroup != nil:
+		target = entry.RotationGroup.Slug
+	case entry.Webhook != nil:
+		target = entry.Webhook.Slug
+	case entry.Email != nil:
+		target = entry.Email.Address
+	case entry.TargetPolicy != nil:
+		target = d.policyName(entry.TargetPolicy.PolicySlug)
+	}
+	return strings.TrimSpace(string(entry.ExecutionType) + " " + target)
+}
+
+func (d *differ) describeStep(step victorops.EscalationPolicySteps) string {
+	var entries []string
+	for _, entry := range step.Entries {
+		entries = append(entries, d.describeEntry(entry))
+	}
+	return fmt.Sprintf("after %dm notify %s", step.Timeout, strings.Join(entries, ", "))
+}
+
+func (d *differ) policies() {
+	for _, policy := range d.old.EscalationPolicies {
+		if _, ok := d.new.EscalationPolicy(policy.ID); !ok {
+			d.add(ChangeRemoved, KindEscalationPolicy, fmt.Sprintf("%s (%s)", policy.Name, policy.ID), "")
+		}
+	}
+
+	for _, policy := range d.new.EscalationPolicies {
+		old, ok := d.old.EscalationPolicy(policy.ID)
+		if !ok {
+			d.add(ChangeAdded, KindEscalationPolicy, d.policyName(policy.ID), "")
+			continue
+		}
+
+		var fields []string
+		fields = changedField(fields, "name", old.Name, policy.Name)
+		if old.TeamID != policy.TeamID {
+			fields = append(fields, fmt.Sprintf("team %s -> %s", d.teamName(old.TeamID), d.teamName(policy.TeamID)))
+		}
+		fields = changedField(fields, "ignoreCustomPagingPolicies", old.IgnoreCustomPagingPolicies, policy.IgnoreCustomPagingPolicies)
+
+		for i := 0; i < len(old.Steps) || i < len(policy.Steps); i++ {
+			switch {
+			case i >= len(old.Steps):
+				fields = append(fields, fmt.Sprintf("step %d added (%s)", i+1, d.describeStep(policy.Steps[i])))
+			case i >= len(policy.Steps):
+				fields = append(fields, fmt.Sprintf("step %d removed (%s)", i+1, d.describeStep(old.Steps[i])))
+			case !reflect.DeepEqual(old.Steps[i], policy.Steps[i]):
+				fields = append(fields, fmt.Sprintf("step %d (%s) -> (%s)", i+1, d.describeStep(old.Steps[i]), d.describeStep(policy.Steps[i])))
+			}
+		}
+
+		if len(fields) > 0 {
+			d.add(ChangeChanged, KindEscalationPolicy, d.policyName(policy.ID), strings.Join(fields, ", "))
+		}
+	}
+}
+
+func (d *differ) routingKeys() {
+	keys := func(s *Snapshot) map[string]victorops.RoutingKey {
+		byName := map[string]victorops.RoutingKey{}
+		for _, key := range s.RoutingKeys {
+			byName[key.RoutingKey] = key
+		}
+		return byName
+	}
+	oldKeys, newKeys := keys(d.old), keys(d.new)
+
+	describeTargets := func(key victorops.RoutingKey) string {
+		var names []string
+		for _, slug := range key.PolicySlugs() {
+			names = append(names, d.policyName(slug))
+		}
+		if len(names) == 0 {
+			return "none"
+		}
+		return strings.Join(names, ", ")
+	}
+
+	for _, key := range d.old.RoutingKeys {
+		if _, ok := newKeys[key.RoutingKey]; !ok {
+			d.add(ChangeRemoved, KindRoutingKey, key.RoutingKey, "")
+		}
+	}
+	for _, key := range d.new.RoutingKeys {
+		old, ok := oldKeys[key.RoutingKey]
+		if !ok {
+			d.add(ChangeAdded, KindRoutingKey, key.RoutingKey, "targets "+describeTargets(key))
+			continue
+		}
+		if !reflect.DeepEqual(old.PolicySlugs(), key.PolicySlugs()) {
+			d.add(ChangeChanged, KindRoutingKey, key.RoutingKey, fmt.Sprintf("targets %s -> %s", describeTargets(old), describeTargets(key)))
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/victorops/go-victorops/victorops"
+)
+
+func TestDiff(t *testing.T) {
+	before := exportTestOrg(t, testOrg())
+
+	org := testOrg()
+	org.users[0].CreatedAt = "2021-01-01T00:00:00Z"
+	org.users[1].LastName = "Smith"
+	org.users = append(org.users, victorops.User{Username: "carol"})
+	org.contacts["alice"] = victorops.AllContactResponse{Emails: victorops.ContactGroup{ContactMethods: []victorops.Contact{
+		{ExtID: "e1", Value: "a@example.com"},
+		{ExtID: "e3", Value: "c@example.com"},
+	}}}
+	org.teams[0].Version = 7
+	org.members = map[string][]string{"team-ops": {"alice"}, "team-dev": {"bob", "carol"}}
+	org.admins = map[string][]string{"team-ops": {"alice"}, "team-dev": {"bob"}}
+	org.policies[0].Steps[0].Timeout = 5
+	org.routingKeys = []victorops.RoutingKey{
+		{RoutingKey: "ops", Targets: []victorops.RoutingKeyTarget{{PolicySlug: "pol-2"}}},
+	}
+	after := exportTestOrg(t, org)
+
+	report, err := Diff(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `~ user alice: lastName "" -> "Smith"
+- contact alice: email b@example.com
++ contact alice: email c@example.com
++ user carol
++ team admin Dev (team-dev): bob
+- team member bob: Ops (team-ops)
++ team member carol: Dev (team-dev)
+~ escalation policy Secondary (pol-2): step 1 (after 0m notify user bob) -> (after 5m notify user bob)
+~ routing key ops: targets Primary (pol-1), Secondary (pol-2) -> Secondary (pol-2)
+`
+	if report.String() != want {
+		t.Errorf("report is\n%s\nwant\n%s", report, want)
+	}
+
+	for _, operation := range report.Patch {
+		if strings.Contains(operation.Path, "createdAt") || strings.Contains(operation.Path, "version") || operation.Path == "/takenAt" {
+			t.Errorf("patch touches volatile field %s", operation.Path)
+		}
+	}
+
+	// Applying the patch to the old snapshot gives the new one, without its volatile fields
+	patched, err := patchDocument(before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var document interface{} = patched
+	for _, operation := range report.Patch {
+		document = applyOperation(t, document, operation)
+	}
+	wantDocument, err := patchDocument(after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(document, interface{}(wantDocument)) {
+		got, _ := json.MarshalIndent(document, "", "  ")
+		t.Errorf("patched snapshot is\n%s", got)
+	}
+}
+
+func TestDiffMove(t *testing.T) {
+	before := exportTestOrg(t, testOrg())
+	org := testOrg()
+	org.members = map[string][]string{"team-ops": {"bob"}, "team-dev": {"bob", "alice"}}
+	after := exportTestOrg(t, org)
+
+	report, err := Diff(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Change{{Type: ChangeMoved, Kind: KindTeamMember, Name: "alice", Detail: "Ops (team-ops) -> Dev (team-dev)"}}
+	if !reflect.DeepEqual(report.Changes, want) {
+		t.Errorf("changes are %+v, want %+v", report.Changes, want)
+	}
+}
+
+func TestDiffUnchanged(t *testing.T) {
+	before := exportTestOrg(t, testOrg())
+	org := testOrg()
+	org.users[0].PasswordLastUpdated = "2021-02-01T00:00:00Z"
+	after := exportTestOrg(t, org)
+
+	report, err := Diff(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.HasChanges() {
+		t.Errorf("expected no changes, got %+v", report)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"changes":null,"patch":[]}` {
+		t.Errorf("unexpected JSON report %s", data)
+	}
+}
+
+func TestPatchLeavesSnapshotsUnchanged(t *testing.T) {
+	before := exportTestOrg(t, testOrg())
+	after := exportTestOrg(t, testOrg())
+	// Out of order, as snapshots built by hand or edited after Read can be
+	before.Users[0], before.Users[1] = before.Users[1], before.Users[0]
+	after.Teams[0].Members = []string{"bob", "alice"}
+	after.RoutingKeys[0].Targets[0], after.RoutingKeys[0].Targets[1] = after.RoutingKeys[0].Targets[1], after.RoutingKeys[0].Targets[0]
+
+	beforeData, _ := json.Marshal(before)
+	afterData, _ := json.Marshal(after)
+	_, err := Patch(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := json.Marshal(before); string(data) != string(beforeData) {
+		t.Errorf("Patch changed the old snapshot\n%s\nwas\n%s", data, beforeData)
+	}
+	if data, _ := json.Marshal(after); string(data) != string(afterData) {
+		t.Errorf("Patch changed the new snapshot\n%s\nwas\n%s", data, afterData)
+	}
+}
+
+// applyOperation applies a single JSON patch operation, as a JSON patch library would
+func applyOperation(t *testing.T, document interface{}, operation PatchOperation) interface{} {
+	tokens := strings.Split(operation.Path, "/")[1:]
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+
+	var apply func(value interface{}, tokens []string) interface{}
+	apply = func(value interface{}, tokens []string) interface{} {
+		last := len(tokens) == 1
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if !last {
+				v[tokens[0]] = apply(v[tokens[0]], tokens[1:])
+				return v
+			}
+			if operation.Op == "remove" {
+				delete(v, tokens[0])
+			} else {
+				v[tokens[0]] = operation.Value
+			}
+			return v
+		case []interface{}:
+			if tokens[0] == "-" {
+				return append(v, operation.Value)
+			}
+			index, err := strconv.Atoi(tokens[0])
+			if err != nil {
+				t.Fatalf("bad index in %s", operation.Path)
+			}
+			if !last {
+				v[index] = apply(v[index], tokens[1:])
+				return v
+			}
+			switch operation.Op {
+			case "remove":
+				return append(v[:index:index], v[index+1:]...)
+			case "add":
+				return append(v[:index:index], append([]interface{}{operation.Value}, v[index:]...)...)
+			default:
+				v[index] = operation.Value
+				return v
+			}
+		}
+		t.Fatalf("can't apply %s to %v", operation.Path, value)
+		return nil
+	}
+
+	// Values are added as decoded JSON, like the rest of the document
+	data, _ := json.Marshal(operation.Value)
+	json.Unmarshal(data, &operation.Value)
+	return apply(document, tokens)
+}
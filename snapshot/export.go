@@ -0,0 +1,119 @@
+package snapshot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/victorops/go-victorops/internal/apierror"
+	"github.com/victorops/go-victorops/victorops"
+)
+
+// Client is the part of *victorops.Client used to export snapshots
+type Client interface {
+	GetAllUserV2() (*victorops.UserListV2, *victorops.RequestDetails, error)
+	GetAllContacts(username string) (*victorops.AllContactResponse, *victorops.RequestDetails, error)
+	GetAllTeams() (*[]victorops.Team, *victorops.RequestDetails, error)
+	GetTeamMembers(teamID string) (*victorops.TeamMembers, *victorops.RequestDetails, error)
+	GetTeamAdmins(teamID string) (*victorops.TeamAdmins, *victorops.RequestDetails, error)
+	GetAllEscalationPolicies() (*victorops.EscalationPolicyList, *victorops.RequestDetails, error)
+	GetEscalationPolicy(escalationPolicyID string) (*victorops.EscalationPolicy, *victorops.RequestDetails, error)
+	GetAllRoutingKeys() (*victorops.RoutingKeyList, *victorops.RequestDetails, error)
+	GetApiTeamSchedule(teamSlug string, daysForward int, daysSkip int, step int) (*victorops.ApiTeamSchedule, *victorops.RequestDetails, error)
+}
+
+// ExportOptions controls what is exported
+type ExportOptions struct {
+	// ScheduleDays is how many days of on-call schedule are exported for each team.
+	// Zero skips schedules, which change every time a shift rolls.
+	ScheduleDays int
+	// Now returns the time the snapshot is taken, time.Now if nil
+	Now func() time.Time
+}
+
+// Export walks the users, contact methods, teams, members, admins, escalation policies,
+// routing keys and optionally schedules of the org
+func Export(client Client, opts ExportOptions) (*Snapshot, error) {
+	now := time.Now
+	if opts.Now != nil {
+		now = opts.Now
+	}
+	s := Snapshot{
+		Version:            Version,
+		TakenAt:            now().UTC(),
+		Users:              []User{},
+		Teams:              []Team{},
+		EscalationPolicies: []victorops.EscalationPolicy{},
+		RoutingKeys:        []victorops.RoutingKey{},
+	}
+
+	users, details, err := client.GetAllUserV2()
+	if err := apierror.Check(details, err); err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	for _, user := range users.Users {
+		contacts, details, err := client.GetAllContacts(user.Username)
+		if err := apierror.Check(details, err); err != nil {
+			return nil, fmt.Errorf("failed to list contacts of %s: %w", user.Username, err)
+		}
+		s.Users = append(s.Users, User{User: user, Contacts: *contacts})
+	}
+
+	teams, details, err := client.GetAllTeams()
+	if err := apierror.Check(details, err); err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+	for _, t := range *teams {
+		team := Team{Team: t, Members: []string{}, Admins: []string{}}
+
+		members, details, err := client.GetTeamMembers(t.Slug)
+		if err := apierror.Check(details, err); err != nil {
+			return nil, fmt.Errorf("failed to list members of team %s: %w", t.Slug, err)
+		}
+		for _, member := range members.Members {
+			team.Members = append(team.Members, member.Username)
+		}
+
+		admins, details, err := client.GetTeamAdmins(t.Slug)
+		if err := apierror.Check(details, err); err != nil {
+			return nil, fmt.Errorf("failed to list admins of team %s: %w", t.Slug, err)
+		}
+		team.Admins = append(team.Admins, admins.Usernames()...)
+
+		if opts.ScheduleDays > 0 {
+			schedule, details, err := client.GetApiTeamSchedule(t.Slug, opts.ScheduleDays, 0, 0)
+			if err := apierror.Check(details, err); err != nil {
+				return nil, fmt.Errorf("failed to get schedule of team %s: %w", t.Slug, err)
+			}
+			s.Schedules = append(s.Schedules, *schedule)
+		}
+
+		s.Teams = append(s.Teams, team)
+	}
+
+	policies, details, err := client.GetAllEscalationPolicies()
+	if err := apierror.Check(details, err); err != nil {
+		return nil, fmt.Errorf("failed to list escalation policies: %w", err)
+	}
+	for _, element := range policies.Policies {
+		policy, details, err := client.GetEscalationPolicy(element.Policy.Slug)
+		if err := apierror.Check(details, err); err != nil {
+			return nil, fmt.Errorf("failed to get escalation policy %s: %w", element.Policy.Slug, err)
+		}
+		if policy.ID == "" {
+			policy.ID = element.Policy.Slug
+		}
+		if policy.TeamID == "" {
+			policy.TeamID = element.Team.Slug
+		}
+		s.EscalationPolicies = append(s.EscalationPolicies, *policy)
+	}
+
+	routingKeys, details, err := client.GetAllRoutingKeys()
+	if err := apierror.Check(details, err); err != nil {
+		return nil, fmt.Errorf("failed to list routing keys: %w", err)
+	}
+	s.RoutingKeys = append(s.RoutingKeys, routingKeys.RoutingKeys...)
+
+	s.normalize()
+	return &s, nil
+}
@@ -0,0 +1,232 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PatchOperation is an operation of a JSON patch (RFC 6902)
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// MarshalJSON leaves out the value of remove operations, which have none
+func (o PatchOperation) MarshalJSON() ([]byte, error) {
+	if o.Op == "remove" {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{o.Op, o.Path})
+	}
+	type operation PatchOperation
+	return json.Marshal(operation(o))
+}
+
+// volatileFields are left out of patches. They change on their own, are bumped on every
+// save, or are derived from other fields.
+var volatileFields = map[string][]string{
+	"":      {"takenAt", "schedules"},
+	"users": {"createdAt", "passwordLastUpdated"},
+	"teams": {"memberCount", "version"},
+}
+
+// identityKeys identify the elements of arrays of objects, so that adding an element to
+// a sorted list doesn't turn into a replacement of every element after it
+var identityKeys = []string{"username", "slug", "routingKey", "extId", "policySlug"}
+
+// Patch returns the JSON patch turning the old snapshot into the new one, leaving out
+// volatile fields and schedules
+func Patch(before *Snapshot, after *Snapshot) ([]PatchOperation, error) {
+	beforeDocument, err := patchDocument(before)
+	if err != nil {
+		return nil, err
+	}
+	afterDocument, err := patchDocument(after)
+	if err != nil {
+		return nil, err
+	}
+
+	operations := []PatchOperation{}
+	diffValues("", beforeDocument, afterDocument, &operations)
+	return operations, nil
+}
+
+// patchDocument returns the snapshot as decoded JSON without its volatile fields. A copy
+// of the snapshot is normalized, leaving the caller's as it is.
+func patchDocument(s *Snapshot) (map[string]interface{}, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var normalized Snapshot
+	err = json.Unmarshal(data, &normalized)
+	if err != nil {
+		return nil, err
+	}
+	normalized.normalize()
+	data, err = json.Marshal(&normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	var document map[string]interface{}
+	err = json.Unmarshal(data, &document)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range volatileFields[""] {
+		delete(document, field)
+	}
+	for list, fields := range volatileFields {
+		items, _ := document[list].([]interface{})
+		for _, item := range items {
+			object, _ := item.(map[string]interface{})
+			for _, field := range fields {
+				delete(object, field)
+			}
+		}
+	}
+	return document, nil
+}
+
+func escapePointer(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
+
+func diffValues(path string, before interface{}, after interface{}, operations *[]PatchOperation) {
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+
+	switch b := before.(type) {
+	case map[string]interface{}:
+		if a, ok := after.(map[string]interface{}); ok {
+			diffObjects(path, b, a, operations)
+			return
+		}
+	case []interface{}:
+		if a, ok := after.([]interface{}); ok {
+			diffArrays(path, b, a, operations)
+			return
+		}
+	}
+
+	*operations = append(*operations, PatchOperation{Op: "replace", Path: path, Value: after})
+}
+
+func diffObjects(path string, before map[string]interface{}, after map[string]interface{}, operations *[]PatchOperation) {
+	var keys []string
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		keyPath := path + "/" + escapePointer(key)
+		b, inBefore := before[key]
+		a, inAfter := after[key]
+		switch {
+		case !inAfter:
+			*operations = append(*operations, PatchOperation{Op: "remove", Path: keyPath})
+		case !inBefore:
+			*operations = append(*operations, PatchOperation{Op: "add", Path: keyPath, Value: a})
+		default:
+			diffValues(keyPath, b, a, operations)
+		}
+	}
+}
+
+// elementIdentity returns what identifies an array element, and false if the element is
+// an object without an identity key
+func elementIdentity(element interface{}) (string, bool) {
+	object, isObject := element.(map[string]interface{})
+	if !isObject {
+		data, _ := json.Marshal(element)
+		return string(data), true
+	}
+	for _, key := range identityKeys {
+		if value, ok := object[key].(string); ok {
+			return key + "=" + value, true
+		}
+	}
+	return "", false
+}
+
+// diffArrays aligns the elements of both arrays by identity when they all have one, and
+// by index otherwise, e.g. for the steps of escalation policies
+func diffArrays(path string, before []interface{}, after []interface{}, operations *[]PatchOperation) {
+	beforeIDs, keyed := arrayIdentities(before)
+	afterIDs, afterKeyed := arrayIdentities(after)
+	if !keyed || !afterKeyed {
+		common := len(before)
+		if len(after) < common {
+			common = len(after)
+		}
+		for i := 0; i < common; i++ {
+			diffValues(path+"/"+strconv.Itoa(i), before[i], after[i], operations)
+		}
+		for i := len(before) - 1; i >= common; i-- {
+			*operations = append(*operations, PatchOperation{Op: "remove", Path: path + "/" + strconv.Itoa(i)})
+		}
+		for i := common; i < len(after); i++ {
+			*operations = append(*operations, PatchOperation{Op: "add", Path: path + "/-", Value: after[i]})
+		}
+		return
+	}
+
+	// Longest common subsequence of identities
+	lengths := make([][]int, len(before)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if beforeIDs[i] == afterIDs[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	// pos is the index in the array as patched so far
+	i, j, pos := 0, 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && beforeIDs[i] == afterIDs[j]:
+			diffValues(path+"/"+strconv.Itoa(pos), before[i], after[j], operations)
+			i, j, pos = i+1, j+1, pos+1
+		case i < len(before) && (j == len(after) || lengths[i+1][j] >= lengths[i][j+1]):
+			*operations = append(*operations, PatchOperation{Op: "remove", Path: path + "/" + strconv.Itoa(pos)})
+			i++
+		default:
+			*operations = append(*operations, PatchOperation{Op: "add", Path: path + "/" + strconv.Itoa(pos), Value: after[j]})
+			j, pos = j+1, pos+1
+		}
+	}
+}
+
+func arrayIdentities(array []interface{}) ([]string, bool) {
+	ids := make([]string, len(array))
+	for i, element := range array {
+		id, ok := elementIdentity(element)
+		if !ok {
+			return nil, false
+		}
+		ids[i] = id
+	}
+	return ids, true
+}
@@ -0,0 +1,270 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/victorops/go-victorops/internal/apierror"
+	"github.com/victorops/go-victorops/reconcile"
+	"github.com/victorops/go-victorops/victorops"
+)
+
+// RestoreClient is the part of *victorops.Client used to restore snapshots
+type RestoreClient interface {
+	reconcile.Client
+	GetTeamAdmins(teamID string) (*victorops.TeamAdmins, *victorops.RequestDetails, error)
+	AddTeamAdmin(teamID string, username string) (*victorops.RequestDetails, error)
+}
+
+// RestoreOptions controls how a snapshot is restored
+type RestoreOptions struct {
+	// DryRun plans the restore without changing the org
+	DryRun bool
+}
+
+// SlugTranslation maps the slug of a team or escalation policy in a snapshot to its slug
+// in the org it was restored to
+type SlugTranslation struct {
+	Name    string `json:"name"`
+	OldSlug string `json:"oldSlug"`
+	// NewSlug is empty for resources that would be created by a dry run, or that a failed
+	// restore didn't get to
+	NewSlug string `json:"newSlug"`
+	Created bool   `json:"created"`
+}
+
+// RestoreReport is what a restore did, or would do for dry runs
+type RestoreReport struct {
+	Plan *reconcile.Plan `json:"-"`
+	// Admins are the team admins promoted, as team/username
+	Admins             []string          `json:"admins"`
+	Teams              []SlugTranslation `json:"teams"`
+	EscalationPolicies []SlugTranslation `json:"escalationPolicies"`
+	// Warnings are references that can't be restored, e.g. to deleted rotation groups
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// WriteJSON writes the slug translations and warnings of the report as JSON
+func (r RestoreReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+func (r RestoreReport) String() string {
+	var b strings.Builder
+	b.WriteString(r.Plan.String())
+	if len(r.Admins) > 0 {
+		b.WriteString("\nTeam admins:\n")
+		for _, admin := range r.Admins {
+			fmt.Fprintf(&b, "  %s\n", admin)
+		}
+	}
+
+	translations := func(title string, list []SlugTranslation) {
+		fmt.Fprintf(&b, "\n%s:\n", title)
+		for _, t := range list {
+			newSlug := t.NewSlug
+			if newSlug == "" {
+				newSlug = "(to be created)"
+			}
+			fmt.Fprintf(&b, "  %s: %s -> %s\n", t.Name, t.OldSlug, newSlug)
+		}
+	}
+	translations("Teams", r.Teams)
+	translations("Escalation policies", r.EscalationPolicies)
+
+	if len(r.Warnings) > 0 {
+		b.WriteString("\nWarnings:\n")
+		for _, warning := range r.Warnings {
+			fmt.Fprintf(&b, "  %s\n", warning)
+		}
+	}
+	return b.String()
+}
+
+// policyRefs maps the policy slugs of the snapshot to references by team and policy name
+func (s Snapshot) policyRefs() map[string]reconcile.PolicyRef {
+	teamNames := map[string]string{}
+	for _, team := range s.Teams {
+		teamNames[team.Slug] = team.Name
+	}
+
+	refs := map[string]reconcile.PolicyRef{}
+	for _, policy := range s.EscalationPolicies {
+		refs[policy.ID] = reconcile.PolicyRef{Team: teamNames[policy.TeamID], Name: policy.Name}
+	}
+	return refs
+}
+
+// State converts the snapshot to a desired state, referencing teams and policies by name
+// instead of by slug. Device contacts are left out since they can't be created through
+// the API, and so are the email contacts of users' own addresses, which the API creates
+// along with the users.
+func (s Snapshot) State() (*reconcile.State, error) {
+	state := reconcile.State{}
+
+	for _, user := range s.Users {
+		// Usernames are lowercased, as reconcile does for the usernames in policies
+		u := reconcile.User{
+			Username:  strings.ToLower(user.Username),
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Email:     user.Email,
+			Admin:     user.Admin,
+		}
+		for _, contact := range user.Contacts.Phones.ContactMethods {
+			u.Contacts = append(u.Contacts, reconcile.Contact{Type: reconcile.ContactPhone, Value: contact.Value, Label: contact.Label})
+		}
+		for _, contact := range user.Contacts.Emails.ContactMethods {
+			if strings.EqualFold(contact.Value, user.Email) {
+				continue
+			}
+			u.Contacts = append(u.Contacts, reconcile.Contact{Type: reconcile.ContactEmail, Value: contact.Value, Label: contact.Label})
+		}
+		state.Users = append(state.Users, u)
+	}
+
+	teamNames := map[string]string{}
+	for _, team := range s.Teams {
+		teamNames[team.Slug] = team.Name
+		members := []string{}
+		for _, member := range team.Members {
+			members = append(members, strings.ToLower(member))
+		}
+		state.Teams = append(state.Teams, reconcile.Team{Name: team.Name, Members: members})
+	}
+
+	refs := s.policyRefs()
+	policyRef := func(slug string) reconcile.PolicyRef {
+		if ref, ok := refs[slug]; ok {
+			return ref
+		}
+		return reconcile.PolicyRef{Name: slug}
+	}
+	for _, policy := range s.EscalationPolicies {
+		state.EscalationPolicies = append(state.EscalationPolicies, reconcile.PolicyFromAPI(policy, teamNames[policy.TeamID], policyRef))
+	}
+
+	for _, key := range s.RoutingKeys {
+		rk := reconcile.RoutingKey{Name: key.RoutingKey, Targets: []reconcile.PolicyRef{}}
+		for _, slug := range key.PolicySlugs() {
+			rk.Targets = append(rk.Targets, policyRef(slug))
+		}
+		state.RoutingKeys = append(state.RoutingKeys, rk)
+	}
+
+	err := state.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// warnings lists the references of the snapshot's policies to rotation groups and
+// webhooks, which can't be restored and may no longer exist
+func (s Snapshot) warnings() []string {
+	var warnings []string
+	for _, policy := range s.EscalationPolicies {
+		for i, step := range policy.Steps {
+			for _, entry := range step.Entries {
+				switch {
+				case entry.RotationGroup != nil:
+					warnings = append(warnings, fmt.Sprintf("escalation policy %s step %d references rotation group %s, which isn't restored", policy.Name, i+1, entry.RotationGroup.Slug))
+				case entry.Webhook != nil:
+					warnings = append(warnings, fmt.Sprintf("escalation policy %s step %d references webhook %s, which isn't restored", policy.Name, i+1, entry.Webhook.Slug))
+				}
+			}
+		}
+	}
+	return warnings
+}
+
+// Restore recreates the users, contact methods, teams, memberships, team admins,
+// escalation policies and routing keys of the snapshot that are missing from the org, in
+// dependency order. Existing resources are left as they are. The report maps the slugs of
+// the snapshot to the slugs in the org. When a change or a team admin promotion fails,
+// the report of what was restored before it is returned along with the error.
+func Restore(client RestoreClient, s *Snapshot, opts RestoreOptions) (*RestoreReport, error) {
+	state, err := s.State()
+	if err != nil {
+		return nil, err
+	}
+
+	live, err := reconcile.FetchLive(client)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := reconcile.NewPlan(*state, live, reconcile.Options{CreateOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	report := RestoreReport{Plan: plan, Admins: []string{}, Warnings: s.warnings()}
+
+	// failure is the first error, after which nothing else is changed but the report of
+	// what was restored is still built
+	result := &reconcile.Result{}
+	var failure error
+	if !opts.DryRun {
+		result, failure = plan.Apply(client)
+	}
+
+	teamSlugs := map[string]string{}
+	for name, slug := range live.TeamSlugs {
+		teamSlugs[name] = slug
+	}
+	for name, slug := range result.TeamSlugs {
+		teamSlugs[name] = slug
+	}
+	for _, team := range s.Teams {
+		_, existed := live.TeamSlugs[team.Name]
+		report.Teams = append(report.Teams, SlugTranslation{Name: team.Name, OldSlug: team.Slug, NewSlug: teamSlugs[team.Name], Created: !existed})
+		if failure == nil {
+			failure = restoreAdmins(client, team, teamSlugs[team.Name], opts, &report)
+		}
+	}
+
+	refs := s.policyRefs()
+	for _, policy := range s.EscalationPolicies {
+		ref := refs[policy.ID]
+		newSlug, existed := live.PolicySlugs[ref]
+		if !existed {
+			newSlug = result.PolicySlugs[ref]
+		}
+		report.EscalationPolicies = append(report.EscalationPolicies, SlugTranslation{Name: ref.String(), OldSlug: policy.ID, NewSlug: newSlug, Created: !existed})
+	}
+
+	return &report, failure
+}
+
+// restoreAdmins promotes the admins of a team in the snapshot that aren't admins of the
+// team in the org. teamSlug is empty for teams that a dry run would create.
+func restoreAdmins(client RestoreClient, team Team, teamSlug string, opts RestoreOptions, report *RestoreReport) error {
+	current := map[string]bool{}
+	if teamSlug != "" {
+		admins, details, err := client.GetTeamAdmins(teamSlug)
+		if err := apierror.Check(details, err); err != nil {
+			return fmt.Errorf("failed to list admins of team %s: %w", team.Name, err)
+		}
+		for _, username := range admins.Usernames() {
+			current[strings.ToLower(username)] = true
+		}
+	}
+
+	for _, username := range team.Admins {
+		if current[strings.ToLower(username)] {
+			continue
+		}
+		if !opts.DryRun {
+			err := apierror.Check(client.AddTeamAdmin(teamSlug, username))
+			if err != nil {
+				return fmt.Errorf("failed to promote %s to admin of team %s: %w", username, team.Name, err)
+			}
+		}
+		report.Admins = append(report.Admins, team.Name+"/"+username)
+	}
+	return nil
+}
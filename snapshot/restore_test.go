@@ -0,0 +1,416 @@
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/victorops/go-victorops/victorops"
+	"github.com/victorops/go-victorops/victoropstest"
+)
+
+// restoreClient adds the write endpoints to fakeClient, generating new slugs for what
+// it creates
+type restoreClient struct {
+	*fakeClient
+	nextID int
+	// adminStatus, when set, is returned by AddTeamAdmin instead of promoting the user
+	adminStatus int
+}
+
+func (f *restoreClient) id(prefix string) string {
+	f.nextID++
+	return fmt.Sprintf("%s-new%d", prefix, f.nextID)
+}
+
+func (f *restoreClient) CreateUser(user *victorops.User) (*victorops.User, *victorops.RequestDetails, error) {
+	f.users = append(f.users, *user)
+	return user, ok, nil
+}
+
+func (f *restoreClient) UpdateUser(user *victorops.User) (*victorops.User, *victorops.RequestDetails, error) {
+	return nil, nil, fmt.Errorf("restores don't update users")
+}
+
+func (f *restoreClient) DeleteUser(username string, replacementUser string) (*victorops.RequestDetails, error) {
+	return nil, fmt.Errorf("restores don't delete users")
+}
+
+func (f *restoreClient) CreateContact(username string, contact *victorops.Contact) (*victorops.Contact, *victorops.RequestDetails, error) {
+	contacts := f.contacts[username]
+	if contact.PhoneNumber != "" {
+		contacts.Phones.ContactMethods = append(contacts.Phones.ContactMethods, victorops.Contact{ExtID: f.id("contact"), Value: contact.PhoneNumber})
+	} else {
+		contacts.Emails.ContactMethods = append(contacts.Emails.ContactMethods, victorops.Contact{ExtID: f.id("contact"), Value: contact.Email})
+	}
+	f.contacts[username] = contacts
+	return contact, ok, nil
+}
+
+func (f *restoreClient) DeleteContact(username string, contactExtID string, contactType victorops.ContactType) (*victorops.RequestDetails, error) {
+	return nil, fmt.Errorf("restores don't delete contacts")
+}
+
+func (f *restoreClient) CreateTeam(team *victorops.Team) (*victorops.Team, *victorops.RequestDetails, error) {
+	created := victorops.Team{Name: team.Name, Slug: f.id("team")}
+	f.teams = append(f.teams, created)
+	return &created, ok, nil
+}
+
+func (f *restoreClient) DeleteTeam(teamID string) (*victorops.RequestDetails, error) {
+	return nil, fmt.Errorf("restores don't delete teams")
+}
+
+func (f *restoreClient) AddTeamMember(teamID string, username string) (*victorops.RequestDetails, error) {
+	f.members[teamID] = append(f.members[teamID], username)
+	return ok, nil
+}
+
+func (f *restoreClient) RemoveTeamMember(teamID string, username string, replacement string) (*victorops.RequestDetails, error) {
+	return nil, fmt.Errorf("restores don't remove members")
+}
+
+func (f *restoreClient) AddTeamAdmin(teamID string, username string) (*victorops.RequestDetails, error) {
+	if teamID == "" {
+		return &victorops.RequestDetails{StatusCode: http.StatusNotFound}, nil
+	}
+	if f.adminStatus != 0 {
+		return &victorops.RequestDetails{StatusCode: f.adminStatus, ResponseBody: `{"error":"unavailable"}`}, nil
+	}
+	f.admins[teamID] = append(f.admins[teamID], username)
+	return ok, nil
+}
+
+func (f *restoreClient) CreateEscalationPolicy(escalationPolicy *victorops.EscalationPolicy) (*victorops.EscalationPolicy, *victorops.RequestDetails, error) {
+	created := *escalationPolicy
+	created.ID = f.id("pol")
+	f.policies = append(f.policies, created)
+	return &created, ok, nil
+}
+
+func (f *restoreClient) UpdateEscalationPolicy(escalationPolicy *victorops.EscalationPolicy) (*victorops.EscalationPolicy, *victorops.RequestDetails, error) {
+	return nil, nil, fmt.Errorf("restores don't update policies")
+}
+
+func (f *restoreClient) DeleteEscalationPolicy(escalationPolicyID string) (*victorops.RequestDetails, error) {
+	return nil, fmt.Errorf("restores don't delete policies")
+}
+
+func (f *restoreClient) CreateRoutingKey(routingKey *victorops.RoutingKey) (*victorops.RoutingKey, *victorops.RequestDetails, error) {
+	f.routingKeys = append(f.routingKeys, *victorops.NewRoutingKey(routingKey.RoutingKey, routingKey.PolicySlugs()))
+	return routingKey, ok, nil
+}
+
+func (f *restoreClient) UpdateRoutingKeyTargets(keyname string, policySlugs []string) (*victorops.RoutingKey, *victorops.RequestDetails, error) {
+	return nil, nil, fmt.Errorf("restores don't retarget routing keys")
+}
+
+func (f *restoreClient) DeleteRoutingKey(keyname string) (*victorops.RequestDetails, error) {
+	return nil, fmt.Errorf("restores don't delete routing keys")
+}
+
+// deletedOrg is the test org after an accidental bulk delete of everything but alice and
+// her contact methods
+func deletedOrg() *restoreClient {
+	org := testOrg()
+	org.users = org.users[1:]
+	org.teams = nil
+	org.members = map[string][]string{}
+	org.admins = map[string][]string{}
+	org.policies = nil
+	org.routingKeys = nil
+	return &restoreClient{fakeClient: &org}
+}
+
+func TestRestore(t *testing.T) {
+	s := exportTestOrg(t, testOrg())
+	client := deletedOrg()
+
+	report, err := Restore(client, s, RestoreOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := exportTestOrg(t, client.fakeClient)
+	translate := map[string]string{}
+	for _, list := range [][]SlugTranslation{report.Teams, report.EscalationPolicies} {
+		for _, translation := range list {
+			if !translation.Created || !strings.Contains(translation.NewSlug, "-new") {
+				t.Errorf("unexpected translation %+v", translation)
+			}
+			translate[translation.OldSlug] = translation.NewSlug
+		}
+	}
+
+	ops, found := restored.Team(translate["team-ops"])
+	if !found || !reflect.DeepEqual(ops.Members, []string{"alice", "bob"}) || !reflect.DeepEqual(ops.Admins, []string{"alice"}) {
+		t.Errorf("team-ops restored as %+v", ops)
+	}
+	primary, found := restored.EscalationPolicy(translate["pol-1"])
+	if !found || primary.TeamID != translate["team-ops"] {
+		t.Fatalf("pol-1 restored as %+v", primary)
+	}
+	if got := primary.Steps[1].Entries[0].TargetPolicy.PolicySlug; got != translate["pol-2"] {
+		t.Errorf("restored primary hands off to %s, want %s", got, translate["pol-2"])
+	}
+	want := []string{translate["pol-1"], translate["pol-2"]}
+	sort.Strings(want)
+	if got := restored.RoutingKeys[0].PolicySlugs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("restored routing key targets %v, want %v", got, want)
+	}
+
+	// Nothing is left to restore
+	report, err = Restore(client, s, RestoreOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Plan.IsEmpty() || len(report.Admins) > 0 {
+		t.Errorf("expected nothing to restore, got\n%s", report)
+	}
+	for _, translation := range report.Teams {
+		if translation.Created {
+			t.Errorf("team %s reported as created again", translation.Name)
+		}
+	}
+}
+
+func TestRestoreDryRun(t *testing.T) {
+	s := exportTestOrg(t, testOrg())
+	s.EscalationPolicies[1].Steps = append(s.EscalationPolicies[1].Steps, victorops.EscalationPolicySteps{
+		Timeout: 30,
+		Entries: []victorops.EscalationPolicyStepEntry{{
+			ExecutionType: victorops.ExecutionTypeRotationGroup,
+			RotationGroup: &victorops.EscalationPolicyRotationGroupTarget{Slug: "rtg-1"},
+		}},
+	})
+	client := deletedOrg()
+
+	report, err := Restore(client, s, RestoreOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.teams) > 0 || len(client.users) > 1 {
+		t.Errorf("dry run changed the org")
+	}
+
+	want := `+ create user bob
++ create team Dev
++ create team Ops
++ create team member Dev/bob
++ create team member Ops/alice
++ create team member Ops/bob
++ create escalation policy Ops/Secondary
++ create escalation policy Ops/Primary
++ create routing key ops
+
+Plan: 9 to create, 0 to update, 0 to delete.
+
+Team admins:
+  Ops/alice
+
+Teams:
+  Dev: team-dev -> (to be created)
+  Ops: team-ops -> (to be created)
+
+Escalation policies:
+  Ops/Primary: pol-1 -> (to be created)
+  Ops/Secondary: pol-2 -> (to be created)
+
+Warnings:
+  escalation policy Secondary step 2 references rotation group rtg-1, which isn't restored
+`
+	if report.String() != want {
+		t.Errorf("report is\n%s\nwant\n%s", report, want)
+	}
+
+	var buf bytes.Buffer
+	err = report.WriteJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"oldSlug": "pol-1"`) {
+		t.Errorf("unexpected JSON report %s", buf.String())
+	}
+}
+
+// apiOrg creates the test org on a fake of the API and snapshots it
+func apiOrg(t *testing.T) *Snapshot {
+	server := victoropstest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	check := func(details *victorops.RequestDetails, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if details.StatusCode != http.StatusOK {
+			t.Fatalf("API returned %d: %s", details.StatusCode, details.ResponseBody)
+		}
+	}
+	for _, username := range []string{"alice", "bob"} {
+		_, details, err := client.CreateUser(&victorops.User{Username: username, FirstName: strings.Title(username), Email: username + "@example.com"})
+		check(details, err)
+	}
+	_, details, err := client.CreateContact("alice", &victorops.Contact{PhoneNumber: "+15555550100", Label: "mobile"})
+	check(details, err)
+	team, details, err := client.CreateTeam(&victorops.Team{Name: "Ops"})
+	check(details, err)
+	check(client.AddTeamMember(team.Slug, "alice"))
+	check(client.AddTeamMember(team.Slug, "bob"))
+	check(client.AddTeamAdmin(team.Slug, "alice"))
+	policy, _ := victorops.NewPolicy("Primary").Team(team.Slug).Step(0).NotifyUser("alice").Build()
+	policy, details, err = client.CreateEscalationPolicy(policy)
+	check(details, err)
+	_, details, err = client.CreateRoutingKey(victorops.NewRoutingKey("ops", []string{policy.ID}))
+	check(details, err)
+
+	s, err := Export(client, ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestRestoreToAPI(t *testing.T) {
+	s := apiOrg(t)
+	server := victoropstest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	// The email contacts the API creates along with users aren't created again
+	report, err := Restore(client, s, RestoreOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Teams) != 1 || report.Teams[0].NewSlug == "" || len(report.EscalationPolicies) != 1 || report.EscalationPolicies[0].NewSlug == "" {
+		t.Errorf("unexpected report\n%s", report)
+	}
+
+	restored, err := Export(client, ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err := Diff(s, restored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only the slugs of the restored team and policy differ
+	for _, change := range diff.Changes {
+		if change.Type != ChangeMoved {
+			t.Errorf("unexpected difference %s", change)
+		}
+	}
+
+	report, err = Restore(client, s, RestoreOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Plan.IsEmpty() || len(report.Admins) > 0 {
+		t.Errorf("expected nothing to restore, got\n%s", report)
+	}
+}
+
+func TestRestorePartialFailure(t *testing.T) {
+	s := apiOrg(t)
+	server := victoropstest.NewServer()
+	defer server.Close()
+	server.InjectFault(victoropstest.Fault{Method: "POST", Path: "v1/team", StatusCode: http.StatusInternalServerError, Body: `{"error":"unavailable"}`})
+
+	report, err := Restore(server.Client(), s, RestoreOptions{})
+	if err == nil || !strings.Contains(err.Error(), "failed to create team Ops: API returned 500") {
+		t.Errorf("unexpected error %v", err)
+	}
+	if report == nil {
+		t.Fatal("no report of the partial restore")
+	}
+	if len(report.Teams) != 1 || report.Teams[0].NewSlug != "" || len(report.Admins) > 0 {
+		t.Errorf("unexpected report\n%s", report)
+	}
+	users, _, err := server.Client().GetAllUserV2()
+	if err != nil || len(users.Users) != 2 {
+		t.Errorf("expected the users to be restored before the failure, got %+v %v", users, err)
+	}
+}
+
+func TestRestoreAdminFailure(t *testing.T) {
+	s := exportTestOrg(t, testOrg())
+	client := deletedOrg()
+	client.adminStatus = http.StatusInternalServerError
+
+	report, err := Restore(client, s, RestoreOptions{})
+	if err == nil || !strings.Contains(err.Error(), "failed to promote alice to admin of team Ops: API returned 500") {
+		t.Errorf("unexpected error %v", err)
+	}
+	if report == nil {
+		t.Fatal("no report of the partial restore")
+	}
+	for _, list := range [][]SlugTranslation{report.Teams, report.EscalationPolicies} {
+		for _, translation := range list {
+			if !translation.Created || translation.NewSlug == "" {
+				t.Errorf("unexpected translation %+v", translation)
+			}
+		}
+	}
+	if len(report.Teams) != 2 || len(report.EscalationPolicies) != 2 || len(report.Admins) > 0 {
+		t.Errorf("unexpected report\n%s", report)
+	}
+}
+
+func TestRestoreMixedCaseUsername(t *testing.T) {
+	source := victoropstest.NewServer()
+	defer source.Close()
+	client := source.Client()
+	_, _, err := client.CreateUser(&victorops.User{Username: "JaneDoe", FirstName: "Jane", Email: "jane@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	team, _, err := client.CreateTeam(&victorops.Team{Name: "Ops"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.AddTeamMember(team.Slug, "JaneDoe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, _ := victorops.NewPolicy("Primary").Team(team.Slug).Step(0).NotifyUser("JaneDoe").Build()
+	_, _, err = client.CreateEscalationPolicy(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Export(client, ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Users[0].Username != "JaneDoe" || s.Teams[0].Members[0] != "JaneDoe" {
+		t.Fatalf("expected the snapshot to keep the case of usernames, got %+v %+v", s.Users, s.Teams)
+	}
+
+	server := victoropstest.NewServer()
+	defer server.Close()
+	report, err := Restore(server.Client(), s, RestoreOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.EscalationPolicies) != 1 || report.EscalationPolicies[0].NewSlug == "" {
+		t.Fatalf("unexpected report\n%s", report)
+	}
+	restored, _, err := server.Client().GetEscalationPolicy(report.EscalationPolicies[0].NewSlug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry := restored.Steps[0].Entries[0]; entry.User == nil || !strings.EqualFold(entry.User.Username, "JaneDoe") {
+		t.Errorf("restored policy notifies %+v", entry)
+	}
+	members, _, err := server.Client().GetTeamMembers(report.Teams[0].NewSlug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members.Members) != 1 || !strings.EqualFold(members.Members[0].Username, "JaneDoe") {
+		t.Errorf("restored team members %+v", members.Members)
+	}
+}
@@ -0,0 +1,166 @@
+// Package snapshot exports a point-in-time copy of a whole VictorOps org to a versioned
+// YAML or JSON file. Snapshots are sorted so exporting an unchanged org produces the same
+// file apart from its timestamp, which keeps them readable when committed to git.
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/victorops/go-victorops/internal/yamljson"
+	"github.com/victorops/go-victorops/victorops"
+)
+
+// Version is the snapshot format written by this package. Snapshots of a newer version
+// can't be read.
+const Version = 1
+
+// Snapshot is the state of an org at a point in time
+type Snapshot struct {
+	Version            int                          `json:"version"`
+	TakenAt            time.Time                    `json:"takenAt"`
+	Users              []User                       `json:"users"`
+	Teams              []Team                       `json:"teams"`
+	EscalationPolicies []victorops.EscalationPolicy `json:"escalationPolicies"`
+	RoutingKeys        []victorops.RoutingKey       `json:"routingKeys"`
+	Schedules          []victorops.ApiTeamSchedule  `json:"schedules,omitempty"`
+}
+
+// User is a user of the org along with their contact methods
+type User struct {
+	victorops.User
+	Contacts victorops.AllContactResponse `json:"contacts"`
+}
+
+// Team is a team of the org along with the usernames of its members and admins
+type Team struct {
+	victorops.Team
+	Members []string `json:"members"`
+	Admins  []string `json:"admins"`
+}
+
+// Format is the encoding of a snapshot file
+type Format string
+
+// The formats snapshots can be written in
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+)
+
+// Write encodes the snapshot in the given format
+func (s *Snapshot) Write(w io.Writer, format Format) error {
+	s.normalize()
+
+	var data []byte
+	var err error
+	switch format {
+	case FormatYAML:
+		data, err = yamljson.Marshal(s)
+	case FormatJSON:
+		data, err = json.MarshalIndent(s, "", "  ")
+		data = append(data, '\n')
+	default:
+		return fmt.Errorf("unknown snapshot format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+// Read decodes a snapshot written in either format
+func Read(r io.Reader) (*Snapshot, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var s Snapshot
+	err = yamljson.Unmarshal(data, &s)
+	if err != nil {
+		return nil, err
+	}
+	if s.Version < 1 || s.Version > Version {
+		return nil, fmt.Errorf("unsupported snapshot version %d, expected at most %d", s.Version, Version)
+	}
+
+	s.normalize()
+	return &s, nil
+}
+
+// User returns the user with the given username
+func (s Snapshot) User(username string) (User, bool) {
+	for _, user := range s.Users {
+		if user.Username == username {
+			return user, true
+		}
+	}
+	return User{}, false
+}
+
+// Team returns the team with the given slug
+func (s Snapshot) Team(slug string) (Team, bool) {
+	for _, team := range s.Teams {
+		if team.Slug == slug {
+			return team, true
+		}
+	}
+	return Team{}, false
+}
+
+// EscalationPolicy returns the escalation policy with the given slug
+func (s Snapshot) EscalationPolicy(slug string) (victorops.EscalationPolicy, bool) {
+	for _, policy := range s.EscalationPolicies {
+		if policy.ID == slug {
+			return policy, true
+		}
+	}
+	return victorops.EscalationPolicy{}, false
+}
+
+func sortContacts(contacts []victorops.Contact) {
+	sort.Slice(contacts, func(a, b int) bool {
+		if contacts[a].Value != contacts[b].Value {
+			return contacts[a].Value < contacts[b].Value
+		}
+		return contacts[a].ExtID < contacts[b].ExtID
+	})
+}
+
+// normalize sorts everything whose order isn't meaningful. The steps of escalation
+// policies and the rolls of schedules keep their order.
+func (s *Snapshot) normalize() {
+	sort.Slice(s.Users, func(a, b int) bool { return s.Users[a].Username < s.Users[b].Username })
+	for _, user := range s.Users {
+		sortContacts(user.Contacts.Phones.ContactMethods)
+		sortContacts(user.Contacts.Emails.ContactMethods)
+		sortContacts(user.Contacts.Devices.ContactMethods)
+	}
+
+	sort.Slice(s.Teams, func(a, b int) bool { return s.Teams[a].Slug < s.Teams[b].Slug })
+	for _, team := range s.Teams {
+		sort.Strings(team.Members)
+		sort.Strings(team.Admins)
+	}
+
+	sort.Slice(s.EscalationPolicies, func(a, b int) bool { return s.EscalationPolicies[a].ID < s.EscalationPolicies[b].ID })
+
+	sort.Slice(s.RoutingKeys, func(a, b int) bool { return s.RoutingKeys[a].RoutingKey < s.RoutingKeys[b].RoutingKey })
+	for _, key := range s.RoutingKeys {
+		targets := key.Targets
+		sort.Slice(targets, func(a, b int) bool { return targets[a].PolicySlug < targets[b].PolicySlug })
+	}
+
+	sort.Slice(s.Schedules, func(a, b int) bool { return s.Schedules[a].Team.Slug < s.Schedules[b].Team.Slug })
+	for _, team := range s.Schedules {
+		schedules := team.Schedules
+		sort.Slice(schedules, func(a, b int) bool { return schedules[a].Policy.Slug < schedules[b].Policy.Slug })
+	}
+}
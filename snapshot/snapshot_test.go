@@ -0,0 +1,223 @@
+package snapshot
+
+import (
+	"bytes"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/victorops/go-victorops/victorops"
+)
+
+var ok = &victorops.RequestDetails{StatusCode: http.StatusOK}
+
+// fakeClient returns canned org data, in the order given
+type fakeClient struct {
+	users       []victorops.User
+	contacts    map[string]victorops.AllContactResponse
+	teams       []victorops.Team
+	members     map[string][]string
+	admins      map[string][]string
+	policies    []victorops.EscalationPolicy
+	routingKeys []victorops.RoutingKey
+}
+
+func (f fakeClient) GetAllUserV2() (*victorops.UserListV2, *victorops.RequestDetails, error) {
+	return &victorops.UserListV2{Users: f.users}, ok, nil
+}
+
+func (f fakeClient) GetAllContacts(username string) (*victorops.AllContactResponse, *victorops.RequestDetails, error) {
+	contacts := f.contacts[username]
+	return &contacts, ok, nil
+}
+
+func (f fakeClient) GetAllTeams() (*[]victorops.Team, *victorops.RequestDetails, error) {
+	return &f.teams, ok, nil
+}
+
+func (f fakeClient) GetTeamMembers(teamID string) (*victorops.TeamMembers, *victorops.RequestDetails, error) {
+	members := victorops.TeamMembers{}
+	for _, username := range f.members[teamID] {
+		members.Members = append(members.Members, victorops.User{Username: username})
+	}
+	return &members, ok, nil
+}
+
+func (f fakeClient) GetTeamAdmins(teamID string) (*victorops.TeamAdmins, *victorops.RequestDetails, error) {
+	admins := victorops.TeamAdmins{}
+	for _, username := range f.admins[teamID] {
+		admins.TeamAdmins = append(admins.TeamAdmins, victorops.Admin{Username: username})
+	}
+	return &admins, ok, nil
+}
+
+func (f fakeClient) GetAllEscalationPolicies() (*victorops.EscalationPolicyList, *victorops.RequestDetails, error) {
+	list := victorops.EscalationPolicyList{}
+	for _, policy := range f.policies {
+		element := victorops.EscalationPolicyListElement{
+			Policy: victorops.EscalationPolicyListDetail{Name: policy.Name, Slug: policy.ID},
+			Team:   victorops.EscalationPolicyListDetail{Slug: policy.TeamID},
+		}
+		for _, team := range f.teams {
+			if team.Slug == policy.TeamID {
+				element.Team.Name = team.Name
+			}
+		}
+		list.Policies = append(list.Policies, element)
+	}
+	return &list, ok, nil
+}
+
+func (f fakeClient) GetEscalationPolicy(escalationPolicyID string) (*victorops.EscalationPolicy, *victorops.RequestDetails, error) {
+	for _, policy := range f.policies {
+		if policy.ID == escalationPolicyID {
+			// The detail endpoint doesn't return the slug
+			policy.ID = ""
+			return &policy, ok, nil
+		}
+	}
+	return nil, &victorops.RequestDetails{StatusCode: http.StatusNotFound}, nil
+}
+
+func (f fakeClient) GetAllRoutingKeys() (*victorops.RoutingKeyList, *victorops.RequestDetails, error) {
+	return &victorops.RoutingKeyList{RoutingKeys: f.routingKeys}, ok, nil
+}
+
+func (f fakeClient) GetApiTeamSchedule(teamSlug string, daysForward int, daysSkip int, step int) (*victorops.ApiTeamSchedule, *victorops.RequestDetails, error) {
+	return &victorops.ApiTeamSchedule{Team: victorops.ApiTeam{Slug: teamSlug}}, ok, nil
+}
+
+func testOrg() fakeClient {
+	return fakeClient{
+		users: []victorops.User{
+			{Username: "bob", FirstName: "Bob", CreatedAt: "2020-01-01T00:00:00Z"},
+			{Username: "alice", FirstName: "Alice", Admin: true},
+		},
+		contacts: map[string]victorops.AllContactResponse{
+			"alice": {Emails: victorops.ContactGroup{ContactMethods: []victorops.Contact{
+				{ExtID: "e2", Value: "b@example.com"},
+				{ExtID: "e1", Value: "a@example.com"},
+			}}},
+		},
+		teams: []victorops.Team{
+			{Name: "Ops", Slug: "team-ops"},
+			{Name: "Dev", Slug: "team-dev"},
+		},
+		members: map[string][]string{"team-ops": {"bob", "alice"}, "team-dev": {"bob"}},
+		admins:  map[string][]string{"team-ops": {"alice"}},
+		policies: []victorops.EscalationPolicy{
+			{ID: "pol-2", Name: "Secondary", TeamID: "team-ops", Steps: []victorops.EscalationPolicySteps{
+				{Timeout: 0, Entries: []victorops.EscalationPolicyStepEntry{{ExecutionType: victorops.ExecutionTypeUser, User: &victorops.EscalationPolicyUserTarget{Username: "bob"}}}},
+			}},
+			{ID: "pol-1", Name: "Primary", TeamID: "team-ops", Steps: []victorops.EscalationPolicySteps{
+				{Timeout: 0, Entries: []victorops.EscalationPolicyStepEntry{{ExecutionType: victorops.ExecutionTypeUser, User: &victorops.EscalationPolicyUserTarget{Username: "alice"}}}},
+				{Timeout: 15, Entries: []victorops.EscalationPolicyStepEntry{{ExecutionType: victorops.ExecutionTypePolicyRouting, TargetPolicy: &victorops.EscalationPolicyPolicyTarget{PolicySlug: "pol-2"}}}},
+			}},
+		},
+		routingKeys: []victorops.RoutingKey{
+			{RoutingKey: "ops", Targets: []victorops.RoutingKeyTarget{{PolicySlug: "pol-2"}, {PolicySlug: "pol-1"}}},
+		},
+	}
+}
+
+func exportTestOrg(t *testing.T, client Client) *Snapshot {
+	s, err := Export(client, ExportOptions{
+		ScheduleDays: 7,
+		Now:          func() time.Time { return time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestExport(t *testing.T) {
+	s := exportTestOrg(t, testOrg())
+
+	var usernames []string
+	for _, user := range s.Users {
+		usernames = append(usernames, user.Username)
+	}
+	if !reflect.DeepEqual(usernames, []string{"alice", "bob"}) {
+		t.Errorf("users are %v", usernames)
+	}
+
+	alice, _ := s.User("alice")
+	if got := alice.Contacts.Emails.ContactMethods[0].ExtID; got != "e1" {
+		t.Errorf("first email of alice is %s, want e1", got)
+	}
+
+	ops, found := s.Team("team-ops")
+	if !found {
+		t.Fatal("team-ops not exported")
+	}
+	if !reflect.DeepEqual(ops.Members, []string{"alice", "bob"}) || !reflect.DeepEqual(ops.Admins, []string{"alice"}) {
+		t.Errorf("team-ops has members %v and admins %v", ops.Members, ops.Admins)
+	}
+
+	primary, found := s.EscalationPolicy("pol-1")
+	if !found || len(primary.Steps) != 2 {
+		t.Errorf("expected the detail of pol-1, got %+v", primary)
+	}
+	if got := s.RoutingKeys[0].PolicySlugs(); !reflect.DeepEqual(got, []string{"pol-1", "pol-2"}) {
+		t.Errorf("routing key targets %v", got)
+	}
+	if len(s.Schedules) != 2 {
+		t.Errorf("exported %d schedules, want 2", len(s.Schedules))
+	}
+}
+
+func TestSnapshotIsDeterministic(t *testing.T) {
+	org := testOrg()
+	var first bytes.Buffer
+	err := exportTestOrg(t, org).Write(&first, FormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The API returning things in another order mustn't change the file
+	org.users[0], org.users[1] = org.users[1], org.users[0]
+	org.teams[0], org.teams[1] = org.teams[1], org.teams[0]
+	org.policies[0], org.policies[1] = org.policies[1], org.policies[0]
+	org.members["team-ops"] = []string{"alice", "bob"}
+	var second bytes.Buffer
+	err = exportTestOrg(t, org).Write(&second, FormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.String() != second.String() {
+		t.Errorf("snapshots differ:\n%s\n---\n%s", first.String(), second.String())
+	}
+	if !strings.HasPrefix(first.String(), "escalationPolicies:") || !strings.Contains(first.String(), "\nversion: 1\n") {
+		t.Errorf("unexpected snapshot:\n%s", first.String())
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatYAML, FormatJSON} {
+		s := exportTestOrg(t, testOrg())
+		var buf bytes.Buffer
+		err := s.Write(&buf, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		read, err := Read(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(read, s) {
+			t.Errorf("%s snapshot read back as\n%+v\nwant\n%+v", format, read, s)
+		}
+	}
+}
+
+func TestReadRejectsNewerVersions(t *testing.T) {
+	_, err := Read(strings.NewReader("version: 2\n"))
+	if err == nil || !strings.Contains(err.Error(), "unsupported snapshot version 2") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
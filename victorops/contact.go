@@ -39,13 +39,13 @@ func GetContactTypes() ContactTypes {
 }
 
 // GetContactTypeFromNotificationType returns a ContactType based on the notificationType string
-// returned in notification steps.
+// returned in notification steps, such as the Type of a PagingPolicyRule.
 func GetContactTypeFromNotificationType(notificationType string) ContactType {
-	if notificationType == "push" {
+	if notificationType == NotificationTypePush {
 		return GetContactTypes().Device
-	} else if notificationType == "email" {
+	} else if notificationType == NotificationTypeEmail {
 		return GetContactTypes().Email
-	} else if notificationType == "phone" || notificationType == "sms" {
+	} else if notificationType == NotificationTypePhone || notificationType == NotificationTypeSMS {
 		return GetContactTypes().Phone
 	}
 	return ContactType{}
@@ -3,17 +3,76 @@ package victorops
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 )
 
-// EscalationPolicyStepEntry is a struct to store escalation policy step entries
+// ErrUpdateEscalationPolicyUnsupported is returned by UpdateEscalationPolicy when the
+// org's API does not allow policies to be updated in place
+var ErrUpdateEscalationPolicyUnsupported = errors.New("updating escalation policies is not supported by this API")
+
+// ExecutionType is the kind of target an escalation policy step entry notifies
+type ExecutionType string
+
+// The execution types supported by escalation policy step entries
+const (
+	ExecutionTypeUser                  ExecutionType = "user"
+	ExecutionTypeRotationGroup         ExecutionType = "rotation_group"
+	ExecutionTypeRotationGroupNext     ExecutionType = "rotation_group_next"
+	ExecutionTypeRotationGroupPrevious ExecutionType = "rotation_group_previous"
+	ExecutionTypeWebhook               ExecutionType = "webhook"
+	ExecutionTypeEmail                 ExecutionType = "email"
+	ExecutionTypePolicyRouting         ExecutionType = "policy_routing"
+)
+
+// IsValid returns true if the execution type is one known by the API
+func (t ExecutionType) IsValid() bool {
+	switch t {
+	case ExecutionTypeUser, ExecutionTypeRotationGroup, ExecutionTypeRotationGroupNext, ExecutionTypeRotationGroupPrevious,
+		ExecutionTypeWebhook, ExecutionTypeEmail, ExecutionTypePolicyRouting:
+		return true
+	}
+	return false
+}
+
+// EscalationPolicyUserTarget is the user notified by a user step entry
+type EscalationPolicyUserTarget struct {
+	Username  string `json:"username"`
+	FirstName string `json:"firstName,omitempty"`
+	LastName  string `json:"lastName,omitempty"`
+}
+
+// EscalationPolicyRotationGroupTarget is the rotation group notified by a rotation group step entry
+type EscalationPolicyRotationGroupTarget struct {
+	Slug  string `json:"slug"`
+	Label string `json:"label,omitempty"`
+}
+
+// EscalationPolicyWebhookTarget is the webhook called by a webhook step entry
+type EscalationPolicyWebhookTarget struct {
+	Slug  string `json:"slug"`
+	Label string `json:"label,omitempty"`
+}
+
+// EscalationPolicyEmailTarget is the address emailed by an email step entry
+type EscalationPolicyEmailTarget struct {
+	Address string `json:"address"`
+}
+
+// EscalationPolicyPolicyTarget is the policy a policy routing step entry hands off to
+type EscalationPolicyPolicyTarget struct {
+	PolicySlug string `json:"policySlug"`
+}
+
+// EscalationPolicyStepEntry is a struct to store escalation policy step entries.
+// Only the target matching the ExecutionType is expected to be set.
 type EscalationPolicyStepEntry struct {
-	ExecutionType string            `json:"executionType"`
-	User          map[string]string `json:"user"`
-	RotationGroup map[string]string `json:"rotationGroup"`
-	Webhook       map[string]string `json:"webhook"`
-	Email         map[string]string `json:"email"`
-	TargetPolicy  map[string]string `json:"targetPolicy"`
+	ExecutionType ExecutionType                        `json:"executionType"`
+	User          *EscalationPolicyUserTarget          `json:"user,omitempty"`
+	RotationGroup *EscalationPolicyRotationGroupTarget `json:"rotationGroup,omitempty"`
+	Webhook       *EscalationPolicyWebhookTarget       `json:"webhook,omitempty"`
+	Email         *EscalationPolicyEmailTarget         `json:"email,omitempty"`
+	TargetPolicy  *EscalationPolicyPolicyTarget        `json:"targetPolicy,omitempty"`
 }
 
 // EscalationPolicySteps is a struct to store escalation policy steps
@@ -50,6 +109,12 @@ type EscalationPolicyList struct {
 	Policies []EscalationPolicyListElement `json:"policies"`
 }
 
+// TeamEscalationPolicyList is a struct to hold the response from the list team escalation policies API call
+type TeamEscalationPolicyList struct {
+	Team     EscalationPolicyListDetail   `json:"team"`
+	Policies []EscalationPolicyListDetail `json:"policies"`
+}
+
 func parseEscalationPoliciesRepsonse(response string) (*EscalationPolicyList, error) {
 	var escalationPolicyList EscalationPolicyList
 	err := json.Unmarshal([]byte(response), &escalationPolicyList)
@@ -97,6 +162,22 @@ func (c Client) GetAllEscalationPolicies() (*EscalationPolicyList, *RequestDetai
 	return policyList, details, nil
 }
 
+// GetTeamEscalationPolicies lists the escalation policies of a single team
+func (c Client) GetTeamEscalationPolicies(teamSlug string) (*TeamEscalationPolicyList, *RequestDetails, error) {
+	details, err := c.makePublicAPICall("GET", "v1/team/"+teamSlug+"/policies", http.NoBody, nil)
+	if err != nil {
+		return nil, details, err
+	}
+
+	var policyList TeamEscalationPolicyList
+	err = json.Unmarshal([]byte(details.ResponseBody), &policyList)
+	if err != nil {
+		return nil, details, err
+	}
+
+	return &policyList, details, nil
+}
+
 // GetEscalationPolicy gets an escalation policy by ID
 func (c Client) GetEscalationPolicy(escalationPolicyID string) (*EscalationPolicy, *RequestDetails, error) {
 	details, err := c.makePublicAPICall("GET", "v1/policies/"+escalationPolicyID, bytes.NewBufferString("{}"), nil)
@@ -112,6 +193,39 @@ func (c Client) GetEscalationPolicy(escalationPolicyID string) (*EscalationPolic
 	return newEscalationPolicy, details, nil
 }
 
+// UpdateEscalationPolicy updates an existing escalation policy in place, keeping its ID so
+// routing keys targeting it keep working
+func (c Client) UpdateEscalationPolicy(escalationPolicy *EscalationPolicy) (*EscalationPolicy, *RequestDetails, error) {
+	if escalationPolicy.ID == "" {
+		return nil, nil, errors.New("escalation policy ID is required for an update")
+	}
+
+	jsonEp, err := json.Marshal(escalationPolicy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	details, err := c.makePublicAPICall("PUT", "v1/policies/"+escalationPolicy.ID, bytes.NewBuffer(jsonEp), nil)
+	if err != nil {
+		return nil, details, err
+	}
+
+	if details.StatusCode == http.StatusMethodNotAllowed || details.StatusCode == http.StatusNotImplemented {
+		return nil, details, ErrUpdateEscalationPolicyUnsupported
+	}
+
+	updatedEscalationPolicy, err := parseEscalationPolicyRepsonse(details.ResponseBody)
+	if err != nil {
+		return updatedEscalationPolicy, details, err
+	}
+
+	if updatedEscalationPolicy.ID == "" {
+		updatedEscalationPolicy.ID = escalationPolicy.ID
+	}
+
+	return updatedEscalationPolicy, details, nil
+}
+
 // DeleteEscalationPolicy deletes an escalation policy by ID
 func (c Client) DeleteEscalationPolicy(escalationPolicyID string) (*RequestDetails, error) {
 	// Make the request
@@ -0,0 +1,212 @@
+package victorops
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxEscalationStepTimeout is the longest timeout, in minutes, Validate accepts for a step
+const MaxEscalationStepTimeout = 24 * 60
+
+// EscalationPolicyValidationError holds every problem found by EscalationPolicy.Validate
+type EscalationPolicyValidationError struct {
+	Problems []string
+}
+
+func (e *EscalationPolicyValidationError) Error() string {
+	return "invalid escalation policy: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks the policy locally before it is sent to the API. All problems are
+// returned at once as an *EscalationPolicyValidationError, or nil if there are none.
+func (ep EscalationPolicy) Validate() error {
+	var problems []string
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if strings.TrimSpace(ep.Name) == "" {
+		problem("name is empty")
+	}
+	if ep.TeamID == "" {
+		problem("team is empty")
+	}
+	if len(ep.Steps) == 0 {
+		problem("policy has no steps")
+	}
+
+	for i, step := range ep.Steps {
+		if step.Timeout < 0 || step.Timeout > MaxEscalationStepTimeout {
+			problem("step %d timeout %d is not between 0 and %d minutes", i+1, step.Timeout, MaxEscalationStepTimeout)
+		}
+
+		if len(step.Entries) == 0 {
+			if i == len(ep.Steps)-1 {
+				problem("last step %d waits %d minutes but notifies nobody", i+1, step.Timeout)
+			} else {
+				problem("step %d has no entries", i+1)
+			}
+		}
+
+		for j, entry := range step.Entries {
+			if err := entry.validate(); err != nil {
+				problem("step %d entry %d %s", i+1, j+1, err)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &EscalationPolicyValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// target returns the identifier of the target matching the entry's execution type, whether
+// that target is set, and the number of targets set on the entry
+func (e EscalationPolicyStepEntry) target() (string, bool, int) {
+	count := 0
+	if e.User != nil {
+		count++
+	}
+	if e.RotationGroup != nil {
+		count++
+	}
+	if e.Webhook != nil {
+		count++
+	}
+	if e.Email != nil {
+		count++
+	}
+	if e.TargetPolicy != nil {
+		count++
+	}
+
+	switch e.ExecutionType {
+	case ExecutionTypeUser:
+		if e.User != nil {
+			return e.User.Username, true, count
+		}
+	case ExecutionTypeRotationGroup, ExecutionTypeRotationGroupNext, ExecutionTypeRotationGroupPrevious:
+		if e.RotationGroup != nil {
+			return e.RotationGroup.Slug, true, count
+		}
+	case ExecutionTypeWebhook:
+		if e.Webhook != nil {
+			return e.Webhook.Slug, true, count
+		}
+	case ExecutionTypeEmail:
+		if e.Email != nil {
+			return e.Email.Address, true, count
+		}
+	case ExecutionTypePolicyRouting:
+		if e.TargetPolicy != nil {
+			return e.TargetPolicy.PolicySlug, true, count
+		}
+	}
+	return "", false, count
+}
+
+func (e EscalationPolicyStepEntry) validate() error {
+	if !e.ExecutionType.IsValid() {
+		return fmt.Errorf("has unknown execution type %q", string(e.ExecutionType))
+	}
+
+	id, ok, count := e.target()
+	switch {
+	case count != 1:
+		return fmt.Errorf("has %d targets, expected exactly one", count)
+	case !ok:
+		return fmt.Errorf("of type %s has no matching target", e.ExecutionType)
+	case strings.TrimSpace(id) == "":
+		return fmt.Errorf("of type %s has an empty target", e.ExecutionType)
+	}
+	return nil
+}
+
+// EscalationPolicyBuilder builds an EscalationPolicy step by step
+type EscalationPolicyBuilder struct {
+	policy EscalationPolicy
+}
+
+// NewPolicy starts building an escalation policy with the given name
+func NewPolicy(name string) *EscalationPolicyBuilder {
+	return &EscalationPolicyBuilder{policy: EscalationPolicy{Name: name}}
+}
+
+// Team sets the slug of the team owning the policy
+func (b *EscalationPolicyBuilder) Team(teamSlug string) *EscalationPolicyBuilder {
+	b.policy.TeamID = teamSlug
+	return b
+}
+
+// IgnoreCustomPagingPolicies sets whether users are paged ignoring their own paging policies
+func (b *EscalationPolicyBuilder) IgnoreCustomPagingPolicies(ignore bool) *EscalationPolicyBuilder {
+	b.policy.IgnoreCustomPagingPolicies = ignore
+	return b
+}
+
+// Step starts a new step executed timeout minutes after the previous one
+func (b *EscalationPolicyBuilder) Step(timeout int) *EscalationPolicyBuilder {
+	b.policy.Steps = append(b.policy.Steps, EscalationPolicySteps{Timeout: timeout, Entries: []EscalationPolicyStepEntry{}})
+	return b
+}
+
+// entry adds an entry to the current step, starting an immediate first step if there is none
+func (b *EscalationPolicyBuilder) entry(entry EscalationPolicyStepEntry) *EscalationPolicyBuilder {
+	if len(b.policy.Steps) == 0 {
+		b.Step(0)
+	}
+	last := len(b.policy.Steps) - 1
+	b.policy.Steps[last].Entries = append(b.policy.Steps[last].Entries, entry)
+	return b
+}
+
+// NotifyUser notifies a user in the current step
+func (b *EscalationPolicyBuilder) NotifyUser(username string) *EscalationPolicyBuilder {
+	return b.entry(EscalationPolicyStepEntry{ExecutionType: ExecutionTypeUser, User: &EscalationPolicyUserTarget{Username: username}})
+}
+
+// NotifyRotation notifies whoever is on call for a rotation group in the current step
+func (b *EscalationPolicyBuilder) NotifyRotation(rotationGroupSlug string) *EscalationPolicyBuilder {
+	return b.entry(EscalationPolicyStepEntry{ExecutionType: ExecutionTypeRotationGroup, RotationGroup: &EscalationPolicyRotationGroupTarget{Slug: rotationGroupSlug}})
+}
+
+// NotifyRotationNext notifies whoever is next on call for a rotation group in the current step
+func (b *EscalationPolicyBuilder) NotifyRotationNext(rotationGroupSlug string) *EscalationPolicyBuilder {
+	return b.entry(EscalationPolicyStepEntry{ExecutionType: ExecutionTypeRotationGroupNext, RotationGroup: &EscalationPolicyRotationGroupTarget{Slug: rotationGroupSlug}})
+}
+
+// NotifyRotationPrevious notifies whoever was previously on call for a rotation group in the current step
+func (b *EscalationPolicyBuilder) NotifyRotationPrevious(rotationGroupSlug string) *EscalationPolicyBuilder {
+	return b.entry(EscalationPolicyStepEntry{ExecutionType: ExecutionTypeRotationGroupPrevious, RotationGroup: &EscalationPolicyRotationGroupTarget{Slug: rotationGroupSlug}})
+}
+
+// NotifyWebhook calls a webhook in the current step
+func (b *EscalationPolicyBuilder) NotifyWebhook(webhookSlug string) *EscalationPolicyBuilder {
+	return b.entry(EscalationPolicyStepEntry{ExecutionType: ExecutionTypeWebhook, Webhook: &EscalationPolicyWebhookTarget{Slug: webhookSlug}})
+}
+
+// NotifyEmail emails an address in the current step
+func (b *EscalationPolicyBuilder) NotifyEmail(address string) *EscalationPolicyBuilder {
+	return b.entry(EscalationPolicyStepEntry{ExecutionType: ExecutionTypeEmail, Email: &EscalationPolicyEmailTarget{Address: address}})
+}
+
+// RouteToPolicy hands the incident off to another escalation policy in the current step
+func (b *EscalationPolicyBuilder) RouteToPolicy(policySlug string) *EscalationPolicyBuilder {
+	return b.entry(EscalationPolicyStepEntry{ExecutionType: ExecutionTypePolicyRouting, TargetPolicy: &EscalationPolicyPolicyTarget{PolicySlug: policySlug}})
+}
+
+// Build validates and returns the policy. The policy has its own copy of the steps, so
+// later calls on the builder don't change it.
+func (b *EscalationPolicyBuilder) Build() (*EscalationPolicy, error) {
+	policy := b.policy
+	policy.Steps = nil
+	for _, step := range b.policy.Steps {
+		step.Entries = append([]EscalationPolicyStepEntry{}, step.Entries...)
+		policy.Steps = append(policy.Steps, step)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
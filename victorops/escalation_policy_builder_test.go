@@ -0,0 +1,105 @@
+package victorops
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEscalationPolicyBuilder(t *testing.T) {
+	policy, err := NewPolicy("High Severity").
+		Team("team-abcd").
+		Step(0).NotifyUser("bob").NotifyRotation("rtg-abcd").
+		Step(15).RouteToPolicy("pol-efgh").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &EscalationPolicy{
+		Name:   "High Severity",
+		TeamID: "team-abcd",
+		Steps: []EscalationPolicySteps{
+			{
+				Timeout: 0,
+				Entries: []EscalationPolicyStepEntry{
+					{ExecutionType: ExecutionTypeUser, User: &EscalationPolicyUserTarget{Username: "bob"}},
+					{ExecutionType: ExecutionTypeRotationGroup, RotationGroup: &EscalationPolicyRotationGroupTarget{Slug: "rtg-abcd"}},
+				},
+			},
+			{
+				Timeout: 15,
+				Entries: []EscalationPolicyStepEntry{
+					{ExecutionType: ExecutionTypePolicyRouting, TargetPolicy: &EscalationPolicyPolicyTarget{PolicySlug: "pol-efgh"}},
+				},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(policy, want) {
+		t.Errorf("returned \n\n%#v want \n\n%#v", policy, want)
+	}
+
+}
+
+func TestEscalationPolicyBuilderCopiesSteps(t *testing.T) {
+	builder := NewPolicy("High Severity").Team("team-abcd").Step(0).NotifyUser("bob")
+	first, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Steps[0].Entries[0] = EscalationPolicyStepEntry{ExecutionType: ExecutionTypeEmail, Email: &EscalationPolicyEmailTarget{Address: "ops@example.com"}}
+	first.Steps[0].Timeout = 5
+
+	second, err := builder.NotifyUser("carol").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second.Steps) != 1 || second.Steps[0].Timeout != 0 || len(second.Steps[0].Entries) != 2 || second.Steps[0].Entries[0].User == nil || second.Steps[0].Entries[0].User.Username != "bob" {
+		t.Errorf("changing a built policy changed the builder: %#v", second.Steps)
+	}
+	if len(first.Steps[0].Entries) != 1 {
+		t.Errorf("building again changed a built policy: %#v", first.Steps)
+	}
+}
+
+func TestEscalationPolicyValidate(t *testing.T) {
+	policy := EscalationPolicy{
+		Name: "High Severity",
+		Steps: []EscalationPolicySteps{
+			{
+				Timeout: -1,
+				Entries: []EscalationPolicyStepEntry{
+					{ExecutionType: ExecutionTypeUser, User: &EscalationPolicyUserTarget{Username: "bob"}, Email: &EscalationPolicyEmailTarget{Address: "bob@example.com"}},
+					{ExecutionType: ExecutionTypeWebhook, RotationGroup: &EscalationPolicyRotationGroupTarget{Slug: "rtg-abcd"}},
+					{ExecutionType: ExecutionTypeEmail, Email: &EscalationPolicyEmailTarget{}},
+					{ExecutionType: "usr", User: &EscalationPolicyUserTarget{Username: "bob"}},
+				},
+			},
+			{Timeout: 30},
+		},
+	}
+
+	err := policy.Validate()
+	validationErr, ok := err.(*EscalationPolicyValidationError)
+	if !ok {
+		t.Fatalf("expected an *EscalationPolicyValidationError, got %v", err)
+	}
+
+	want := []string{
+		"team is empty",
+		"step 1 timeout -1 is not between 0 and 1440 minutes",
+		"step 1 entry 1 has 2 targets, expected exactly one",
+		"step 1 entry 2 of type webhook has no matching target",
+		"step 1 entry 3 of type email has an empty target",
+		`step 1 entry 4 has unknown execution type "usr"`,
+		"last step 2 waits 30 minutes but notifies nobody",
+	}
+	if !reflect.DeepEqual(validationErr.Problems, want) {
+		t.Errorf("returned \n\n%s want \n\n%s", strings.Join(validationErr.Problems, "\n"), strings.Join(want, "\n"))
+	}
+
+	if _, err := NewPolicy("").Build(); err == nil {
+		t.Error("expected an empty policy to be invalid")
+	}
+}
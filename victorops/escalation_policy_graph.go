@@ -0,0 +1,255 @@
+package victorops
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// EscalationPolicyGraph links escalation policies to the policies they hand off to and
+// to the routing keys targeting them
+type EscalationPolicyGraph struct {
+	Policies    map[string]EscalationPolicy
+	RoutingKeys []RoutingKey
+}
+
+// EscalationPolicyPath is a chain of policies, each handing off to the next
+type EscalationPolicyPath []EscalationPolicyListDetail
+
+func (p EscalationPolicyPath) String() string {
+	var names []string
+	for _, policy := range p {
+		names = append(names, formatPolicyDetail(policy))
+	}
+	return strings.Join(names, " -> ")
+}
+
+// EscalationPolicyGraphReport holds the problems found in an EscalationPolicyGraph
+type EscalationPolicyGraphReport struct {
+	// Cycles are paths that start and end with the same policy
+	Cycles []EscalationPolicyPath `json:"cycles"`
+	// Unreachable policies can't be reached from any routing key, even through hand-offs
+	Unreachable []EscalationPolicyListDetail `json:"unreachable"`
+	// Unreferenced policies aren't targeted directly by any routing key
+	Unreferenced []EscalationPolicyListDetail `json:"unreferenced"`
+}
+
+func formatPolicyDetail(policy EscalationPolicyListDetail) string {
+	if policy.Name == "" {
+		return policy.Slug
+	}
+	return fmt.Sprintf("%s (%s)", policy.Name, policy.Slug)
+}
+
+// HasProblems returns true if the report contains any finding
+func (r EscalationPolicyGraphReport) HasProblems() bool {
+	return len(r.Cycles) > 0 || len(r.Unreachable) > 0 || len(r.Unreferenced) > 0
+}
+
+func (r EscalationPolicyGraphReport) String() string {
+	var b strings.Builder
+	section := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "%s:\n", title)
+		for _, line := range lines {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+
+	var cycles, unreachable, unreferenced []string
+	for _, cycle := range r.Cycles {
+		cycles = append(cycles, cycle.String())
+	}
+	for _, policy := range r.Unreachable {
+		unreachable = append(unreachable, formatPolicyDetail(policy))
+	}
+	for _, policy := range r.Unreferenced {
+		unreferenced = append(unreferenced, formatPolicyDetail(policy))
+	}
+
+	section("Cycles", cycles)
+	section("Unreachable policies", unreachable)
+	section("Policies not targeted by any routing key", unreferenced)
+	return b.String()
+}
+
+// NewEscalationPolicyGraph builds the graph of the given policies and routing keys
+func NewEscalationPolicyGraph(policies []EscalationPolicy, routingKeys []RoutingKey) *EscalationPolicyGraph {
+	graph := EscalationPolicyGraph{Policies: map[string]EscalationPolicy{}, RoutingKeys: routingKeys}
+	for _, policy := range policies {
+		graph.Policies[policy.ID] = policy
+	}
+	return &graph
+}
+
+func (g EscalationPolicyGraph) detail(slug string) EscalationPolicyListDetail {
+	return EscalationPolicyListDetail{Name: g.Policies[slug].Name, Slug: slug}
+}
+
+func (g EscalationPolicyGraph) sortedSlugs() []string {
+	var slugs []string
+	for slug := range g.Policies {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+	return slugs
+}
+
+// Targets returns the slugs of the known policies the given policy hands off to
+func (g EscalationPolicyGraph) Targets(policySlug string) []string {
+	seen := map[string]bool{}
+	var targets []string
+	for _, step := range g.Policies[policySlug].Steps {
+		for _, entry := range step.Entries {
+			if entry.ExecutionType != ExecutionTypePolicyRouting || entry.TargetPolicy == nil {
+				continue
+			}
+			target := entry.TargetPolicy.PolicySlug
+			if _, ok := g.Policies[target]; ok && !seen[target] {
+				seen[target] = true
+				targets = append(targets, target)
+			}
+		}
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+// Cycles returns every loop of hand-offs between policies. Each cycle is reported once,
+// starting and ending at its policy with the lowest slug.
+func (g EscalationPolicyGraph) Cycles() []EscalationPolicyPath {
+	var cycles []EscalationPolicyPath
+
+	for _, start := range g.sortedSlugs() {
+		onPath := map[string]bool{}
+		var path []string
+
+		var visit func(slug string)
+		visit = func(slug string) {
+			path = append(path, slug)
+			onPath[slug] = true
+			for _, target := range g.Targets(slug) {
+				// Only follow policies after the start so each cycle is found from its lowest slug
+				if target < start {
+					continue
+				}
+				if target == start {
+					var cycle EscalationPolicyPath
+					for _, s := range append(path, start) {
+						cycle = append(cycle, g.detail(s))
+					}
+					cycles = append(cycles, cycle)
+					continue
+				}
+				if !onPath[target] {
+					visit(target)
+				}
+			}
+			onPath[slug] = false
+			path = path[:len(path)-1]
+		}
+		visit(start)
+	}
+
+	return cycles
+}
+
+func (g EscalationPolicyGraph) routingKeyTargets() map[string]bool {
+	targeted := map[string]bool{}
+	for _, key := range g.RoutingKeys {
+		for _, target := range key.Targets {
+			targeted[target.PolicySlug] = true
+		}
+	}
+	return targeted
+}
+
+// Unreferenced returns the policies that no routing key targets directly
+func (g EscalationPolicyGraph) Unreferenced() []EscalationPolicyListDetail {
+	targeted := g.routingKeyTargets()
+	var unreferenced []EscalationPolicyListDetail
+	for _, slug := range g.sortedSlugs() {
+		if !targeted[slug] {
+			unreferenced = append(unreferenced, g.detail(slug))
+		}
+	}
+	return unreferenced
+}
+
+// Unreachable returns the policies that can't be reached from any routing key,
+// neither directly nor through hand-offs from other policies
+func (g EscalationPolicyGraph) Unreachable() []EscalationPolicyListDetail {
+	reached := map[string]bool{}
+	var queue []string
+	for slug := range g.routingKeyTargets() {
+		if _, ok := g.Policies[slug]; ok {
+			queue = append(queue, slug)
+		}
+	}
+	for len(queue) > 0 {
+		slug := queue[0]
+		queue = queue[1:]
+		if reached[slug] {
+			continue
+		}
+		reached[slug] = true
+		queue = append(queue, g.Targets(slug)...)
+	}
+
+	var unreachable []EscalationPolicyListDetail
+	for _, slug := range g.sortedSlugs() {
+		if !reached[slug] {
+			unreachable = append(unreachable, g.detail(slug))
+		}
+	}
+	return unreachable
+}
+
+// Analyze returns the cycles, unreachable and unreferenced policies of the graph
+func (g EscalationPolicyGraph) Analyze() *EscalationPolicyGraphReport {
+	return &EscalationPolicyGraphReport{
+		Cycles:       g.Cycles(),
+		Unreachable:  g.Unreachable(),
+		Unreferenced: g.Unreferenced(),
+	}
+}
+
+// GetAllEscalationPoliciesDetailed returns the full detail of every escalation policy in the org
+func (c Client) GetAllEscalationPoliciesDetailed() ([]EscalationPolicy, *RequestDetails, error) {
+	policyList, details, err := c.GetAllEscalationPolicies()
+	if err != nil {
+		return nil, details, err
+	}
+
+	var policies []EscalationPolicy
+	for _, element := range policyList.Policies {
+		policy, details, err := c.GetEscalationPolicy(element.Policy.Slug)
+		if err != nil {
+			return nil, details, err
+		}
+		if policy.ID == "" {
+			policy.ID = element.Policy.Slug
+		}
+		policies = append(policies, *policy)
+	}
+
+	return policies, details, nil
+}
+
+// GetEscalationPolicyGraph fetches every escalation policy and routing key of the org and
+// builds their graph
+func (c Client) GetEscalationPolicyGraph() (*EscalationPolicyGraph, *RequestDetails, error) {
+	policies, details, err := c.GetAllEscalationPoliciesDetailed()
+	if err != nil {
+		return nil, details, err
+	}
+
+	routingKeys, details, err := c.GetAllRoutingKeys()
+	if err != nil {
+		return nil, details, err
+	}
+
+	return NewEscalationPolicyGraph(policies, routingKeys.RoutingKeys), details, nil
+}
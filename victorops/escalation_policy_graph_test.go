@@ -0,0 +1,104 @@
+package victorops
+
+import (
+	"net/http"
+	"testing"
+)
+
+func routeToPolicy(slug string, name string, targets ...string) EscalationPolicy {
+	builder := NewPolicy(name).Team("team-abcd").Step(0).NotifyUser("janedoe")
+	for _, target := range targets {
+		builder.Step(15).RouteToPolicy(target)
+	}
+	policy, _ := builder.Build()
+	policy.ID = slug
+	return *policy
+}
+
+func TestEscalationPolicyGraph(t *testing.T) {
+	policies := []EscalationPolicy{
+		routeToPolicy("pol-a", "A", "pol-b"),
+		routeToPolicy("pol-b", "B", "pol-c"),
+		routeToPolicy("pol-c", "C", "pol-a", "pol-missing"),
+		routeToPolicy("pol-d", "D"),
+		routeToPolicy("pol-e", "E", "pol-e"),
+	}
+	routingKeys := []RoutingKey{
+		{RoutingKey: "default", Targets: []RoutingKeyTarget{{PolicySlug: "pol-a"}}},
+	}
+
+	report := NewEscalationPolicyGraph(policies, routingKeys).Analyze()
+
+	if len(report.Cycles) != 2 {
+		t.Fatalf("expected 2 cycles, got %v", report.Cycles)
+	}
+	if got := report.Cycles[0].String(); got != "A (pol-a) -> B (pol-b) -> C (pol-c) -> A (pol-a)" {
+		t.Errorf("unexpected cycle %s", got)
+	}
+	if got := report.Cycles[1].String(); got != "E (pol-e) -> E (pol-e)" {
+		t.Errorf("unexpected cycle %s", got)
+	}
+
+	if len(report.Unreachable) != 2 || report.Unreachable[0].Slug != "pol-d" || report.Unreachable[1].Slug != "pol-e" {
+		t.Errorf("unexpected unreachable policies %v", report.Unreachable)
+	}
+
+	// pol-b and pol-c are reachable through pol-a, but not targeted directly
+	if len(report.Unreferenced) != 4 || report.Unreferenced[0].Slug != "pol-b" {
+		t.Errorf("unexpected unreferenced policies %v", report.Unreferenced)
+	}
+
+	want := "Cycles:\n" +
+		"  A (pol-a) -> B (pol-b) -> C (pol-c) -> A (pol-a)\n" +
+		"  E (pol-e) -> E (pol-e)\n" +
+		"Unreachable policies:\n" +
+		"  D (pol-d)\n" +
+		"  E (pol-e)\n" +
+		"Policies not targeted by any routing key:\n" +
+		"  B (pol-b)\n" +
+		"  C (pol-c)\n" +
+		"  D (pol-d)\n" +
+		"  E (pol-e)\n"
+	if report.String() != want {
+		t.Errorf("returned \n\n%s want \n\n%s", report.String(), want)
+	}
+}
+
+func TestGetEscalationPolicyGraph(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/api-public/v1/policies", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		w.Write([]byte(`
+		{
+			"policies": [
+				{"policy": {"name": "A", "slug": "pol-a"}, "team": {"name": "Infrastructure", "slug": "team-abcd"}},
+				{"policy": {"name": "B", "slug": "pol-b"}, "team": {"name": "Infrastructure", "slug": "team-abcd"}}
+			]
+		}
+		`))
+	})
+	testMux.HandleFunc("/api-public/v1/policies/pol-a", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name": "A", "slug": "pol-a", "steps": [{"timeout": 0, "entries": [{"executionType": "policy_routing", "targetPolicy": {"policySlug": "pol-b"}}]}]}`))
+	})
+	testMux.HandleFunc("/api-public/v1/policies/pol-b", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name": "B", "steps": [{"timeout": 0, "entries": [{"executionType": "policy_routing", "targetPolicy": {"policySlug": "pol-a"}}]}]}`))
+	})
+	testMux.HandleFunc("/api-public/v1/org/routing-keys", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"routingKeys": [{"routingKey": "default", "targets": [{"policySlug": "pol-a", "policyName": "A"}]}]}`))
+	})
+
+	graph, _, err := testClient.GetEscalationPolicyGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := graph.Analyze()
+	if len(report.Cycles) != 1 || report.Cycles[0].String() != "A (pol-a) -> B (pol-b) -> A (pol-a)" {
+		t.Errorf("unexpected cycles %v", report.Cycles)
+	}
+	if len(report.Unreachable) != 0 {
+		t.Errorf("unexpected unreachable policies %v", report.Unreachable)
+	}
+}
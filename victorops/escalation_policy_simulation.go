@@ -0,0 +1,230 @@
+package victorops
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EscalationNotification is a single notification sent while an incident escalates
+type EscalationNotification struct {
+	At time.Time `json:"at"`
+	// Policy is the policy executing the step, Via the policies that handed off to it
+	Policy        EscalationPolicyListDetail   `json:"policy"`
+	Via           []EscalationPolicyListDetail `json:"via,omitempty"`
+	Step          int                          `json:"step"`
+	ExecutionType ExecutionType                `json:"executionType"`
+	Target        string                       `json:"target"`
+	// Usernames are the users notified, resolved from the schedules for rotation groups
+	Usernames []string `json:"usernames,omitempty"`
+	Note      string   `json:"note,omitempty"`
+}
+
+// EscalationTimeline is the ordered list of notifications of a simulated incident
+type EscalationTimeline struct {
+	Start         time.Time                `json:"start"`
+	Notifications []EscalationNotification `json:"notifications"`
+}
+
+func (t EscalationTimeline) String() string {
+	var b strings.Builder
+	for _, n := range t.Notifications {
+		fmt.Fprintf(&b, "+%-6s %s step %d %s %s %s",
+			formatElapsed(n.At.Sub(t.Start)), n.At.Format(time.RFC3339), n.Step, formatPolicyDetail(n.Policy), n.ExecutionType, n.Target)
+		if len(n.Usernames) > 0 {
+			fmt.Fprintf(&b, " -> %s", strings.Join(n.Usernames, ", "))
+		}
+		if n.Note != "" {
+			fmt.Fprintf(&b, " (%s)", n.Note)
+		}
+		fmt.Fprintln(&b)
+	}
+	return b.String()
+}
+
+func formatElapsed(d time.Duration) string {
+	if d%time.Hour == 0 && d != 0 {
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dm", int(d.Minutes()))
+}
+
+// EscalationSimulator simulates an unacknowledged incident escalating through policies
+type EscalationSimulator struct {
+	// Policies are used to follow hand-offs to other policies, by slug
+	Policies map[string]EscalationPolicy
+	// Schedules are used to resolve who is on call for rotation groups
+	Schedules []ApiTeamSchedule
+	// RotationGroups maps rotation group slugs to the rotation names used in Schedules,
+	// which don't include the slugs
+	RotationGroups map[string]string
+}
+
+// NewEscalationSimulator creates a simulator from policies and schedules, e.g. loaded
+// from fixtures or fetched with GetAllEscalationPoliciesDetailed and GetApiTeamSchedule.
+// Rotation groups are named by the labels of the policy step entries targeting them.
+func NewEscalationSimulator(policies []EscalationPolicy, schedules []ApiTeamSchedule) *EscalationSimulator {
+	simulator := EscalationSimulator{Policies: map[string]EscalationPolicy{}, Schedules: schedules, RotationGroups: map[string]string{}}
+	for _, policy := range policies {
+		simulator.Policies[policy.ID] = policy
+		for _, step := range policy.Steps {
+			for _, entry := range step.Entries {
+				if entry.RotationGroup != nil && entry.RotationGroup.Slug != "" && entry.RotationGroup.Label != "" {
+					simulator.RotationGroups[entry.RotationGroup.Slug] = entry.RotationGroup.Label
+				}
+			}
+		}
+	}
+	return &simulator
+}
+
+// Simulate returns who is notified when if an incident routed to policy at start is
+// never acknowledged. Each step runs its timeout in minutes after the previous one, and
+// hand-offs start the target policy from its first step.
+func (s EscalationSimulator) Simulate(policy EscalationPolicy, start time.Time) *EscalationTimeline {
+	timeline := EscalationTimeline{Start: start, Notifications: []EscalationNotification{}}
+	s.simulate(policy, start, nil, &timeline)
+
+	sort.SliceStable(timeline.Notifications, func(a, b int) bool {
+		return timeline.Notifications[a].At.Before(timeline.Notifications[b].At)
+	})
+	return &timeline
+}
+
+func (s EscalationSimulator) simulate(policy EscalationPolicy, start time.Time, via []EscalationPolicyListDetail, timeline *EscalationTimeline) {
+	detail := EscalationPolicyListDetail{Name: policy.Name, Slug: policy.ID}
+	at := start
+
+	for i, step := range policy.Steps {
+		at = at.Add(time.Duration(step.Timeout) * time.Minute)
+
+		for _, entry := range step.Entries {
+			id, _, _ := entry.target()
+			notification := EscalationNotification{
+				At:            at,
+				Policy:        detail,
+				Via:           via,
+				Step:          i + 1,
+				ExecutionType: entry.ExecutionType,
+				Target:        id,
+			}
+
+			switch entry.ExecutionType {
+			case ExecutionTypeUser:
+				notification.Usernames = []string{id}
+			case ExecutionTypeRotationGroup, ExecutionTypeRotationGroupNext, ExecutionTypeRotationGroupPrevious:
+				users, found := s.rotationUsers(policy.ID, id, entry.ExecutionType, at)
+				switch {
+				case !found:
+					notification.Note = "unknown rotation"
+				case len(users) == 0:
+					notification.Note = "nobody on call"
+				default:
+					notification.Usernames = users
+				}
+			case ExecutionTypePolicyRouting:
+				target, ok := s.Policies[id]
+				switch {
+				case !ok:
+					notification.Note = "unknown policy, not followed"
+				case policyInPath(id, detail, via):
+					notification.Note = "cycle, not followed"
+				default:
+					timeline.Notifications = append(timeline.Notifications, notification)
+					s.simulate(target, at, append(append([]EscalationPolicyListDetail{}, via...), detail), timeline)
+					continue
+				}
+			}
+
+			timeline.Notifications = append(timeline.Notifications, notification)
+		}
+	}
+}
+
+func policyInPath(slug string, current EscalationPolicyListDetail, via []EscalationPolicyListDetail) bool {
+	if current.Slug == slug {
+		return true
+	}
+	for _, policy := range via {
+		if policy.Slug == slug {
+			return true
+		}
+	}
+	return false
+}
+
+// rotationUsers returns who is on call at the given time for the rotation group of a
+// policy, and false if the policy's schedule has no rotation for the group
+func (s EscalationSimulator) rotationUsers(policySlug string, rotationGroupSlug string, executionType ExecutionType, at time.Time) ([]string, bool) {
+	name, ok := s.RotationGroups[rotationGroupSlug]
+	if !ok {
+		return nil, false
+	}
+
+	var users []string
+	found := false
+	for _, team := range s.Schedules {
+		for _, policy := range team.Schedules {
+			if policy.Policy.Slug != policySlug {
+				continue
+			}
+
+			for _, entry := range policy.Schedule {
+				if entry.RotationName != name {
+					continue
+				}
+				found = true
+
+				username := rollUserAt(entry.Rolls, at, executionType)
+				if username == "" {
+					continue
+				}
+				if executionType == ExecutionTypeRotationGroup {
+					username = overrideUserAt(policy.Overrides, username, at)
+				}
+				users = append(users, username)
+			}
+		}
+	}
+	return users, found
+}
+
+// rollUserAt returns the user of the roll covering at, or of the roll after or before it
+func rollUserAt(rolls []ApiOnCallRoll, at time.Time, executionType ExecutionType) string {
+	sorted := append([]ApiOnCallRoll{}, rolls...)
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a].Start.Before(sorted[b].Start) })
+
+	for i, roll := range sorted {
+		if at.Before(roll.Start) || !at.Before(roll.End) {
+			continue
+		}
+		switch executionType {
+		case ExecutionTypeRotationGroupNext:
+			for _, next := range sorted[i+1:] {
+				if next.OnCallUser.Username != roll.OnCallUser.Username {
+					return next.OnCallUser.Username
+				}
+			}
+			return ""
+		case ExecutionTypeRotationGroupPrevious:
+			for j := i - 1; j >= 0; j-- {
+				if sorted[j].OnCallUser.Username != roll.OnCallUser.Username {
+					return sorted[j].OnCallUser.Username
+				}
+			}
+			return ""
+		}
+		return roll.OnCallUser.Username
+	}
+	return ""
+}
+
+func overrideUserAt(overrides []ApiOnCallOverride, username string, at time.Time) string {
+	for _, override := range overrides {
+		if override.OrigOnCallUser.Username == username && !at.Before(override.Start) && at.Before(override.End) {
+			return override.OverrideOnCallUser.Username
+		}
+	}
+	return username
+}
@@ -0,0 +1,123 @@
+package victorops
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEscalationSimulator(t *testing.T) {
+	primary := EscalationPolicy{
+		Name:   "Primary",
+		TeamID: "team-abcd",
+		ID:     "pol-a",
+		Steps: []EscalationPolicySteps{
+			{
+				Timeout: 0,
+				Entries: []EscalationPolicyStepEntry{
+					{ExecutionType: ExecutionTypeRotationGroup, RotationGroup: &EscalationPolicyRotationGroupTarget{Slug: "rtg-abcd", Label: "Primary"}},
+					// Not in any schedule, and without a label to find it by
+					{ExecutionType: ExecutionTypeRotationGroup, RotationGroup: &EscalationPolicyRotationGroupTarget{Slug: "rtg-wxyz"}},
+				},
+			},
+			{
+				Timeout: 15,
+				Entries: []EscalationPolicyStepEntry{
+					{ExecutionType: ExecutionTypeRotationGroupNext, RotationGroup: &EscalationPolicyRotationGroupTarget{Slug: "rtg-abcd", Label: "Primary"}},
+					{ExecutionType: ExecutionTypeUser, User: &EscalationPolicyUserTarget{Username: "bob"}},
+				},
+			},
+			{
+				Timeout: 45,
+				Entries: []EscalationPolicyStepEntry{
+					{ExecutionType: ExecutionTypePolicyRouting, TargetPolicy: &EscalationPolicyPolicyTarget{PolicySlug: "pol-b"}},
+				},
+			},
+		},
+	}
+	management, _ := NewPolicy("Management").
+		Team("team-abcd").
+		Step(0).NotifyEmail("managers@example.com").
+		Step(10).RouteToPolicy("pol-a").
+		Build()
+	management.ID = "pol-b"
+
+	schedules := []ApiTeamSchedule{
+		{
+			Team: ApiTeam{Slug: "team-abcd"},
+			Schedules: []ApiEscalationPolicySchedule{
+				{
+					Policy: ApiEscalationPolicy{Slug: "pol-a"},
+					Schedule: []ApiOnCallEntry{
+						{
+							RotationName: "Primary",
+							Rolls: []ApiOnCallRoll{
+								testRoll(t, "janedoe", "2020-03-31T09:00:00Z", "2020-04-07T09:00:00Z"),
+								testRoll(t, "johndoe", "2020-04-07T09:00:00Z", "2020-04-14T09:00:00Z"),
+							},
+						},
+						{
+							// Not targeted by the policy, so its users are never notified
+							RotationName: "Secondary",
+							Rolls: []ApiOnCallRoll{
+								testRoll(t, "dave", "2020-03-31T09:00:00Z", "2020-04-14T09:00:00Z"),
+							},
+						},
+					},
+					Overrides: []ApiOnCallOverride{
+						{
+							OrigOnCallUser:     ApiUser{Username: "janedoe"},
+							OverrideOnCallUser: ApiUser{Username: "carol"},
+							Start:              mustParseTime(t, "2020-04-01T00:00:00Z"),
+							End:                mustParseTime(t, "2020-04-01T06:00:00Z"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	simulator := NewEscalationSimulator([]EscalationPolicy{primary, *management}, schedules)
+	start := mustParseTime(t, "2020-04-01T05:30:00Z")
+	timeline := simulator.Simulate(primary, start)
+
+	want := []struct {
+		offset time.Duration
+		policy string
+		target string
+		users  []string
+		note   string
+	}{
+		{0, "pol-a", "rtg-abcd", []string{"carol"}, ""},
+		{0, "pol-a", "rtg-wxyz", nil, "unknown rotation"},
+		{15 * time.Minute, "pol-a", "rtg-abcd", []string{"johndoe"}, ""},
+		{15 * time.Minute, "pol-a", "bob", []string{"bob"}, ""},
+		{60 * time.Minute, "pol-a", "pol-b", nil, ""},
+		{60 * time.Minute, "pol-b", "managers@example.com", nil, ""},
+		{70 * time.Minute, "pol-b", "pol-a", nil, "cycle, not followed"},
+	}
+
+	if len(timeline.Notifications) != len(want) {
+		t.Fatalf("unexpected timeline \n\n%s", timeline)
+	}
+	for i, w := range want {
+		n := timeline.Notifications[i]
+		if !n.At.Equal(start.Add(w.offset)) || n.Policy.Slug != w.policy || n.Target != w.target || n.Note != w.note || len(n.Usernames) != len(w.users) {
+			t.Errorf("notification %d: got %#v want %v", i, n, w)
+			continue
+		}
+		for j := range w.users {
+			if n.Usernames[j] != w.users[j] {
+				t.Errorf("notification %d: got users %v want %v", i, n.Usernames, w.users)
+			}
+		}
+	}
+
+	if len(timeline.Notifications[5].Via) != 1 || timeline.Notifications[5].Via[0].Slug != "pol-a" {
+		t.Errorf("expected pol-b to be reached via pol-a, got %v", timeline.Notifications[5].Via)
+	}
+
+	wantString := "+0m     2020-04-01T05:30:00Z step 1 Primary (pol-a) rotation_group rtg-abcd -> carol\n"
+	if got := timeline.String(); len(got) < len(wantString) || got[:len(wantString)] != wantString {
+		t.Errorf("unexpected timeline \n\n%s", got)
+	}
+}
@@ -0,0 +1,202 @@
+package victorops
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func testEscalationPolicy() *EscalationPolicy {
+	return &EscalationPolicy{
+		Name:                       "High Severity",
+		TeamID:                     "team-abcd",
+		IgnoreCustomPagingPolicies: false,
+		ID:                         "pol-abcd",
+		Steps: []EscalationPolicySteps{
+			{
+				Timeout: 15,
+				Entries: []EscalationPolicyStepEntry{
+					{
+						ExecutionType: ExecutionTypeUser,
+						User:          &EscalationPolicyUserTarget{Username: "janedoe"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestUpdateEscalationPolicy(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/api-public/v1/policies/pol-abcd", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+
+		var policy EscalationPolicy
+		if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+			t.Fatal(err)
+		}
+		if policy.Steps[0].Timeout != 15 {
+			t.Errorf("unexpected policy sent %#v", policy)
+		}
+
+		w.Write([]byte(`
+		{
+			"name": "High Severity",
+			"teamSlug": "team-abcd",
+			"ignoreCustomPagingPolicies": false,
+			"steps": [
+				{
+					"timeout": 15,
+					"entries": [
+						{"executionType": "user", "user": {"username": "janedoe"}}
+					]
+				}
+			]
+		}
+		`))
+	})
+
+	resp, _, err := testClient.UpdateEscalationPolicy(testEscalationPolicy())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := testEscalationPolicy()
+	if !reflect.DeepEqual(resp, want) {
+		t.Errorf("returned \n\n%#v want \n\n%#v", resp, want)
+	}
+}
+
+func TestUpdateEscalationPolicyUnsupported(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/api-public/v1/policies/pol-abcd", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	_, details, err := testClient.UpdateEscalationPolicy(testEscalationPolicy())
+	if err != ErrUpdateEscalationPolicyUnsupported {
+		t.Errorf("expected ErrUpdateEscalationPolicyUnsupported, got %v", err)
+	}
+	if details.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("unexpected status code %d", details.StatusCode)
+	}
+
+	policy := testEscalationPolicy()
+	policy.ID = ""
+	if _, _, err := testClient.UpdateEscalationPolicy(policy); err == nil {
+		t.Error("expected an error for a policy without ID")
+	}
+}
+
+func TestEscalationPolicyStepEntryJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		JSONString string
+		entry      EscalationPolicyStepEntry
+	}{
+		{
+			name:       "user",
+			JSONString: `{"executionType":"user","user":{"username":"janedoe"}}`,
+			entry:      EscalationPolicyStepEntry{ExecutionType: ExecutionTypeUser, User: &EscalationPolicyUserTarget{Username: "janedoe"}},
+		},
+		{
+			name:       "rotation group",
+			JSONString: `{"executionType":"rotation_group_next","rotationGroup":{"slug":"rtg-abcd"}}`,
+			entry:      EscalationPolicyStepEntry{ExecutionType: ExecutionTypeRotationGroupNext, RotationGroup: &EscalationPolicyRotationGroupTarget{Slug: "rtg-abcd"}},
+		},
+		{
+			name:       "webhook",
+			JSONString: `{"executionType":"webhook","webhook":{"slug":"wh-abcd"}}`,
+			entry:      EscalationPolicyStepEntry{ExecutionType: ExecutionTypeWebhook, Webhook: &EscalationPolicyWebhookTarget{Slug: "wh-abcd"}},
+		},
+		{
+			name:       "email",
+			JSONString: `{"executionType":"email","email":{"address":"oncall@example.com"}}`,
+			entry:      EscalationPolicyStepEntry{ExecutionType: ExecutionTypeEmail, Email: &EscalationPolicyEmailTarget{Address: "oncall@example.com"}},
+		},
+		{
+			name:       "policy routing",
+			JSONString: `{"executionType":"policy_routing","targetPolicy":{"policySlug":"pol-abcd"}}`,
+			entry:      EscalationPolicyStepEntry{ExecutionType: ExecutionTypePolicyRouting, TargetPolicy: &EscalationPolicyPolicyTarget{PolicySlug: "pol-abcd"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var entry EscalationPolicyStepEntry
+			if err := json.Unmarshal([]byte(test.JSONString), &entry); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(entry, test.entry) {
+				t.Errorf("returned \n\n%#v want \n\n%#v", entry, test.entry)
+			}
+
+			marshalled, err := json.Marshal(entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(marshalled) != test.JSONString {
+				t.Errorf("returned \n\n%s want \n\n%s", marshalled, test.JSONString)
+			}
+		})
+	}
+}
+
+func TestEscalationPolicyStepEntryUnknownExecutionType(t *testing.T) {
+	// Execution types added to the API after this client must survive a read and write back
+	JSONString := `{"executionType":"team_page"}`
+	var entry EscalationPolicyStepEntry
+	if err := json.Unmarshal([]byte(JSONString), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.ExecutionType.IsValid() {
+		t.Errorf("execution type %q is valid", entry.ExecutionType)
+	}
+
+	marshalled, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(marshalled) != JSONString {
+		t.Errorf("returned \n\n%s want \n\n%s", marshalled, JSONString)
+	}
+}
+
+func TestGetTeamEscalationPolicies(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/api-public/v1/team/team-abcd/policies", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		w.Write([]byte(`
+		{
+			"team": {"name": "Infrastructure", "slug": "team-abcd"},
+			"policies": [
+				{"name": "High Severity", "slug": "pol-abcd", "_selfUrl": "/api-public/v1/policies/pol-abcd"},
+				{"name": "Low Severity", "slug": "pol-efgh", "_selfUrl": "/api-public/v1/policies/pol-efgh"}
+			]
+		}
+		`))
+	})
+
+	resp, _, err := testClient.GetTeamEscalationPolicies("team-abcd")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &TeamEscalationPolicyList{
+		Team: EscalationPolicyListDetail{Name: "Infrastructure", Slug: "team-abcd"},
+		Policies: []EscalationPolicyListDetail{
+			{Name: "High Severity", Slug: "pol-abcd"},
+			{Name: "Low Severity", Slug: "pol-efgh"},
+		},
+	}
+	if !reflect.DeepEqual(resp, want) {
+		t.Errorf("returned \n\n%#v want \n\n%#v", resp, want)
+	}
+}
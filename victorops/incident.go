@@ -55,6 +55,26 @@ type IncidentResponse struct {
 	Incidents []Incident `json:"incidents,omitempty"`
 }
 
+// IncidentUpdateRequest acknowledges or resolves incidents on behalf of a user
+type IncidentUpdateRequest struct {
+	UserName        string   `json:"userName"`
+	IncidentNumbers []string `json:"incidentNames"`
+	Message         string   `json:"message,omitempty"`
+}
+
+// IncidentUpdateResult is the outcome of acknowledging or resolving a single incident
+type IncidentUpdateResult struct {
+	IncidentNumber string `json:"incidentNumber"`
+	EntityID       string `json:"entityId,omitempty"`
+	CmdAccepted    bool   `json:"cmdAccepted"`
+	Message        string `json:"message,omitempty"`
+}
+
+// IncidentUpdateResponse holds the results of acknowledging or resolving incidents
+type IncidentUpdateResponse struct {
+	Results []IncidentUpdateResult `json:"results"`
+}
+
 func parseIncidentsResponse(response string) (*IncidentResponse, error) {
 
 	var incidentList IncidentResponse
@@ -109,3 +129,90 @@ func (c Client) GetIncidents() (*IncidentResponse, *RequestDetails, error) {
 
 	return incidentList, details, nil
 }
+
+func (c Client) updateIncidents(action string, username string, incidentNumbers []int, message string) (*IncidentUpdateResponse, *RequestDetails, error) {
+	request := IncidentUpdateRequest{UserName: username, IncidentNumbers: []string{}, Message: message}
+	for _, number := range incidentNumbers {
+		request.IncidentNumbers = append(request.IncidentNumbers, strconv.Itoa(number))
+	}
+	jsonRequest, err := json.Marshal(request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	details, err := c.makePublicAPICall("PATCH", "v1/incidents/"+action, bytes.NewBuffer(jsonRequest), nil)
+	if err != nil {
+		return nil, details, err
+	}
+
+	var response IncidentUpdateResponse
+	err = json.Unmarshal([]byte(details.ResponseBody), &response)
+	if err != nil {
+		return nil, details, err
+	}
+
+	return &response, details, nil
+}
+
+// AckIncidents acknowledges incidents by number on behalf of a user
+func (c Client) AckIncidents(username string, incidentNumbers []int, message string) (*IncidentUpdateResponse, *RequestDetails, error) {
+	return c.updateIncidents("ack", username, incidentNumbers, message)
+}
+
+// ResolveIncidents resolves incidents by number on behalf of a user
+func (c Client) ResolveIncidents(username string, incidentNumbers []int, message string) (*IncidentUpdateResponse, *RequestDetails, error) {
+	return c.updateIncidents("resolve", username, incidentNumbers, message)
+}
+
+// incidentHistoryPageLimit is the number of incidents requested per page of the incident history
+const incidentHistoryPageLimit = 100
+
+// incidentHistoryPage is a page of the incident history returned by the reporting API
+type incidentHistoryPage struct {
+	Offset    int        `json:"offset"`
+	Limit     int        `json:"limit"`
+	Total     int        `json:"total"`
+	Incidents []Incident `json:"incidents"`
+}
+
+// GetIncidentHistory returns the incidents that started between start and end, including
+// those resolved long ago. All pages of the history are fetched and returned together,
+// stopping at the first page without incidents that weren't on the previous ones.
+func (c Client) GetIncidentHistory(start time.Time, end time.Time) (*IncidentResponse, *RequestDetails, error) {
+	history := IncidentResponse{Incidents: []Incident{}}
+	var details *RequestDetails
+	seen := map[string]bool{}
+
+	for offset := 0; ; offset += incidentHistoryPageLimit {
+		var err error
+		details, err = c.makePublicAPICall("GET", "v2/reporting/incidents", bytes.NewBufferString("{}"), map[string]string{
+			"startedAfter":  start.Format(time.RFC3339),
+			"startedBefore": end.Format(time.RFC3339),
+			"limit":         strconv.Itoa(incidentHistoryPageLimit),
+			"offset":        strconv.Itoa(offset),
+		})
+		if err != nil {
+			return nil, details, err
+		}
+
+		var page incidentHistoryPage
+		err = json.Unmarshal([]byte(details.ResponseBody), &page)
+		if err != nil {
+			return nil, details, err
+		}
+		added := 0
+		for _, incident := range page.Incidents {
+			if !seen[incident.IncidentNumber] {
+				seen[incident.IncidentNumber] = true
+				history.Incidents = append(history.Incidents, incident)
+				added++
+			}
+		}
+
+		if len(page.Incidents) < incidentHistoryPageLimit || added == 0 || (page.Total > 0 && len(history.Incidents) >= page.Total) {
+			break
+		}
+	}
+
+	return &history, details, nil
+}
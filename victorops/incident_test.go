@@ -1,6 +1,15 @@
 package victorops
 
-import "testing"
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
 
 func TestIncidents(t *testing.T) {
 	tests := []struct {
@@ -71,3 +80,96 @@ func TestIncidents(t *testing.T) {
 		})
 	}
 }
+
+func TestAckAndResolveIncidents(t *testing.T) {
+	setup()
+	defer teardown()
+
+	for _, action := range []string{"ack", "resolve"} {
+		testMux.HandleFunc("/api-public/v1/incidents/"+action, func(w http.ResponseWriter, r *http.Request) {
+			testMethod(t, r, "PATCH")
+			body, _ := ioutil.ReadAll(r.Body)
+			want := `{"userName":"janedoe","incidentNames":["4","7"],"message":"on it"}`
+			if string(body) != want {
+				t.Errorf("request body %s, want %s", body, want)
+			}
+			w.Write([]byte(`{"results": [
+				{"incidentNumber": "4", "entityId": "entity-4", "cmdAccepted": true, "message": "Acked"},
+				{"incidentNumber": "7", "cmdAccepted": false, "message": "Incident already resolved"}
+			]}`))
+		})
+	}
+
+	want := &IncidentUpdateResponse{Results: []IncidentUpdateResult{
+		{IncidentNumber: "4", EntityID: "entity-4", CmdAccepted: true, Message: "Acked"},
+		{IncidentNumber: "7", CmdAccepted: false, Message: "Incident already resolved"},
+	}}
+
+	resp, _, err := testClient.AckIncidents("janedoe", []int{4, 7}, "on it")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(resp, want) {
+		t.Errorf("returned \n\n%#v want \n\n%#v", resp, want)
+	}
+
+	resp, _, err = testClient.ResolveIncidents("janedoe", []int{4, 7}, "on it")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(resp, want) {
+		t.Errorf("returned \n\n%#v want \n\n%#v", resp, want)
+	}
+}
+
+func TestGetIncidentHistory(t *testing.T) {
+	for _, test := range []struct {
+		name         string
+		ignoreOffset bool
+		want         int
+		requests     int
+	}{
+		{"paged", false, 150, 2},
+		// A server that ignores the offset and has no total serves the same full page forever
+		{"offset ignored", true, incidentHistoryPageLimit, 2},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			setup()
+			defer teardown()
+
+			requests := 0
+			testMux.HandleFunc("/api-public/v2/reporting/incidents", func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "GET")
+				requests++
+				if r.URL.Query().Get("startedAfter") != "2020-04-01T00:00:00Z" || r.URL.Query().Get("startedBefore") != "2020-05-01T00:00:00Z" {
+					t.Errorf("unexpected query %s", r.URL.RawQuery)
+				}
+
+				offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+				limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+				if test.ignoreOffset {
+					offset = 0
+				}
+				incidents := []Incident{}
+				for i := offset; i < offset+limit && i < 150; i++ {
+					incidents = append(incidents, Incident{IncidentNumber: strconv.Itoa(i + 1)})
+				}
+				body, _ := json.Marshal(incidentHistoryPage{Offset: offset, Limit: limit, Incidents: incidents})
+				w.Write(body)
+			})
+
+			start, _ := time.Parse(time.RFC3339, "2020-04-01T00:00:00Z")
+			end, _ := time.Parse(time.RFC3339, "2020-05-01T00:00:00Z")
+			resp, _, err := testClient.GetIncidentHistory(start, end)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if requests != test.requests || len(resp.Incidents) != test.want {
+				t.Errorf("got %d incidents in %d requests, want %d in %d", len(resp.Incidents), requests, test.want, test.requests)
+			}
+			if last := resp.Incidents[len(resp.Incidents)-1].IncidentNumber; last != fmt.Sprint(test.want) {
+				t.Errorf("last incident %s, want %d", last, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package victorops
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MembershipIndex answers which users are in which teams without calling the API. It is
+// loaded once with LoadMembershipIndex and safe for concurrent use.
+type MembershipIndex struct {
+	mu          sync.RWMutex
+	userTeams   map[string]map[string]bool
+	teamMembers map[string]map[string]bool
+	loadedAt    time.Time
+}
+
+// NewMembershipIndex builds an index from the members of each team, by team slug
+func NewMembershipIndex(members map[string]TeamMembers) *MembershipIndex {
+	index := MembershipIndex{}
+	index.set(members)
+	return &index
+}
+
+func (m *MembershipIndex) set(members map[string]TeamMembers) {
+	userTeams := map[string]map[string]bool{}
+	teamMembers := map[string]map[string]bool{}
+	for teamSlug, teamMembersList := range members {
+		teamMembers[teamSlug] = map[string]bool{}
+		for _, member := range teamMembersList.Members {
+			// Usernames are case insensitive
+			username := strings.ToLower(member.Username)
+			teamMembers[teamSlug][username] = true
+			if userTeams[username] == nil {
+				userTeams[username] = map[string]bool{}
+			}
+			userTeams[username][teamSlug] = true
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.userTeams = userTeams
+	m.teamMembers = teamMembers
+	m.loadedAt = time.Now()
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := []string{}
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TeamsOf returns the slugs of the teams a user is a member of
+func (m *MembershipIndex) TeamsOf(username string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return sortedKeys(m.userTeams[strings.ToLower(username)])
+}
+
+// MembersOf returns the lower cased usernames of the members of a team
+func (m *MembershipIndex) MembersOf(teamSlug string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return sortedKeys(m.teamMembers[teamSlug])
+}
+
+// IsMember returns whether a user is a member of a team
+func (m *MembershipIndex) IsMember(teamSlug string, username string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.teamMembers[teamSlug][strings.ToLower(username)]
+}
+
+// LoadedAt returns when the index was last loaded
+func (m *MembershipIndex) LoadedAt() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.loadedAt
+}
+
+// Reload fetches the members of every team again and replaces the content of the index
+func (m *MembershipIndex) Reload(c *Client) (*RequestDetails, error) {
+	members, details, err := c.getAllTeamMembers()
+	if err != nil {
+		return details, err
+	}
+
+	m.set(members)
+	return details, nil
+}
+
+func (c Client) getAllTeamMembers() (map[string]TeamMembers, *RequestDetails, error) {
+	teams, details, err := c.GetAllTeams()
+	if err != nil {
+		return nil, details, err
+	}
+
+	members := map[string]TeamMembers{}
+	for _, team := range *teams {
+		teamMembers, details, err := c.GetTeamMembers(team.Slug)
+		if err != nil {
+			return nil, details, err
+		}
+		members[team.Slug] = *teamMembers
+	}
+
+	return members, details, nil
+}
+
+// LoadMembershipIndex fetches the members of every team once, so membership can be
+// checked without further API calls
+func (c Client) LoadMembershipIndex() (*MembershipIndex, *RequestDetails, error) {
+	index := MembershipIndex{}
+	details, err := index.Reload(&c)
+	if err != nil {
+		return nil, details, err
+	}
+	return &index, details, nil
+}
@@ -0,0 +1,76 @@
+package victorops
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestGetUserTeams(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/api-public/v1/user/janedoe/teams", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		w.Write([]byte(`
+		{
+			"teams": [
+				{"name": "Infrastructure", "slug": "team-abcd", "memberCount": 2, "version": 1, "isDefaultTeam": false}
+			]
+		}
+		`))
+	})
+
+	resp, _, err := testClient.GetUserTeams("janedoe")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &[]Team{{Name: "Infrastructure", Slug: "team-abcd", MemberCount: 2, Version: 1}}
+	if !reflect.DeepEqual(resp, want) {
+		t.Errorf("returned \n\n%#v want \n\n%#v", resp, want)
+	}
+}
+
+func TestLoadMembershipIndex(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := 0
+	testMux.HandleFunc("/api-public/v1/team", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`[{"name": "Infrastructure", "slug": "team-abcd"}, {"name": "Database", "slug": "team-efgh"}]`))
+	})
+	testMux.HandleFunc("/api-public/v1/team/team-abcd/members", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"members": [{"username": "JaneDoe"}, {"username": "johndoe"}]}`))
+	})
+	testMux.HandleFunc("/api-public/v1/team/team-efgh/members", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"members": [{"username": "janedoe"}]}`))
+	})
+
+	index, _, err := testClient.LoadMembershipIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+
+	if got := index.TeamsOf("janedoe"); !reflect.DeepEqual(got, []string{"team-abcd", "team-efgh"}) {
+		t.Errorf("unexpected teams of janedoe %v", got)
+	}
+	if got := index.MembersOf("team-abcd"); !reflect.DeepEqual(got, []string{"janedoe", "johndoe"}) {
+		t.Errorf("unexpected members of team-abcd %v", got)
+	}
+	if !index.IsMember("team-efgh", "JANEDOE") || index.IsMember("team-efgh", "johndoe") {
+		t.Errorf("unexpected membership of team-efgh %v", index.MembersOf("team-efgh"))
+	}
+	if got := index.TeamsOf("nobody"); len(got) != 0 {
+		t.Errorf("unexpected teams of an unknown user %v", got)
+	}
+	if requests != 3 {
+		t.Errorf("expected lookups not to call the API, got %d requests", requests)
+	}
+}
@@ -0,0 +1,350 @@
+package victorops
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OnCallFindingKind identifies the kind of problem reported by the on-call analyzer
+type OnCallFindingKind string
+
+const (
+	// OnCallCoverageGap is reported when nobody is on call for a policy
+	OnCallCoverageGap OnCallFindingKind = "coverage_gap"
+	// OnCallOverlappingOverrides is reported when two overrides replace the same user at the same time
+	OnCallOverlappingOverrides OnCallFindingKind = "overlapping_overrides"
+	// OnCallConcurrentTeams is reported when a user is on call for more than one team at once
+	OnCallConcurrentTeams OnCallFindingKind = "concurrent_teams"
+	// OnCallLongShift is reported when a user is continuously on call for longer than the configured limit
+	OnCallLongShift OnCallFindingKind = "long_shift"
+)
+
+// OnCallFinding is a single problem found in an on-call schedule
+type OnCallFinding struct {
+	Kind     OnCallFindingKind `json:"kind"`
+	Teams    []string          `json:"teams,omitempty"`
+	Policy   string            `json:"policy,omitempty"`
+	Username string            `json:"username,omitempty"`
+	Start    time.Time         `json:"start"`
+	End      time.Time         `json:"end"`
+	Message  string            `json:"message"`
+}
+
+// OnCallAnalysisOptions controls the checks done by AnalyzeOnCallSchedules
+type OnCallAnalysisOptions struct {
+	// Start and End bound the window checked for coverage gaps. When they are zero
+	// the window of each policy is derived from its first and last roll.
+	Start time.Time
+	End   time.Time
+	// MaxShift is the longest a user may be continuously on call for a team.
+	// A zero value disables the check.
+	MaxShift time.Duration
+}
+
+// OnCallAnalysis holds the findings of the on-call analyzer, ordered by start time
+type OnCallAnalysis struct {
+	Findings []OnCallFinding `json:"findings"`
+}
+
+// HasFindings returns true if the analysis found any problem
+func (a OnCallAnalysis) HasFindings() bool {
+	return len(a.Findings) > 0
+}
+
+// FindingsOfKind returns the findings of the given kind
+func (a OnCallAnalysis) FindingsOfKind(kind OnCallFindingKind) []OnCallFinding {
+	var findings []OnCallFinding
+	for _, f := range a.Findings {
+		if f.Kind == kind {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// onCallInterval is a half open [start, end) range of time
+type onCallInterval struct {
+	start time.Time
+	end   time.Time
+}
+
+func (i onCallInterval) overlaps(o onCallInterval) bool {
+	return i.start.Before(o.end) && o.start.Before(i.end)
+}
+
+// mergeIntervals sorts the intervals and joins the ones that overlap or touch
+func mergeIntervals(intervals []onCallInterval) []onCallInterval {
+	var sorted []onCallInterval
+	for _, i := range intervals {
+		if i.start.Before(i.end) {
+			sorted = append(sorted, i)
+		}
+	}
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a].start.Before(sorted[b].start) })
+
+	var merged []onCallInterval
+	for _, i := range sorted {
+		last := len(merged) - 1
+		if last >= 0 && !i.start.After(merged[last].end) {
+			if i.end.After(merged[last].end) {
+				merged[last].end = i.end
+			}
+			continue
+		}
+		merged = append(merged, i)
+	}
+	return merged
+}
+
+// subtractIntervals returns the parts of a that are not covered by b
+func subtractIntervals(a []onCallInterval, b []onCallInterval) []onCallInterval {
+	result := mergeIntervals(a)
+	for _, cut := range mergeIntervals(b) {
+		var next []onCallInterval
+		for _, i := range result {
+			if !i.overlaps(cut) {
+				next = append(next, i)
+				continue
+			}
+			if i.start.Before(cut.start) {
+				next = append(next, onCallInterval{start: i.start, end: cut.start})
+			}
+			if cut.end.Before(i.end) {
+				next = append(next, onCallInterval{start: cut.end, end: i.end})
+			}
+		}
+		result = next
+	}
+	return result
+}
+
+// intersectIntervals returns the parts of time covered by both a and b
+func intersectIntervals(a []onCallInterval, b []onCallInterval) []onCallInterval {
+	var result []onCallInterval
+	for _, x := range mergeIntervals(a) {
+		for _, y := range mergeIntervals(b) {
+			if !x.overlaps(y) {
+				continue
+			}
+			i := onCallInterval{start: x.start, end: x.end}
+			if y.start.After(i.start) {
+				i.start = y.start
+			}
+			if y.end.Before(i.end) {
+				i.end = y.end
+			}
+			result = append(result, i)
+		}
+	}
+	return mergeIntervals(result)
+}
+
+// policyUserIntervals returns when each user is effectively on call for a policy,
+// applying the overrides on top of the scheduled rolls
+func policyUserIntervals(policy ApiEscalationPolicySchedule) map[string][]onCallInterval {
+	rolls := map[string][]onCallInterval{}
+	for _, entry := range policy.Schedule {
+		for _, roll := range entry.Rolls {
+			rolls[roll.OnCallUser.Username] = append(rolls[roll.OnCallUser.Username], onCallInterval{start: roll.Start, end: roll.End})
+		}
+	}
+
+	replaced := map[string][]onCallInterval{}
+	added := map[string][]onCallInterval{}
+	for _, override := range policy.Overrides {
+		i := onCallInterval{start: override.Start, end: override.End}
+		replaced[override.OrigOnCallUser.Username] = append(replaced[override.OrigOnCallUser.Username], i)
+		added[override.OverrideOnCallUser.Username] = append(added[override.OverrideOnCallUser.Username], i)
+	}
+
+	users := map[string][]onCallInterval{}
+	for username, intervals := range rolls {
+		users[username] = subtractIntervals(intervals, replaced[username])
+	}
+	for username, intervals := range added {
+		users[username] = mergeIntervals(append(users[username], intervals...))
+	}
+	delete(users, "")
+	return users
+}
+
+// AnalyzeOnCallSchedules checks team schedules for coverage gaps, overlapping overrides,
+// users on call for multiple teams at the same time and shifts longer than opts.MaxShift
+func AnalyzeOnCallSchedules(schedules []ApiTeamSchedule, opts OnCallAnalysisOptions) *OnCallAnalysis {
+	analysis := OnCallAnalysis{Findings: []OnCallFinding{}}
+
+	// team slug -> username -> intervals
+	teamUsers := map[string]map[string][]onCallInterval{}
+
+	for _, team := range schedules {
+		if teamUsers[team.Team.Slug] == nil {
+			teamUsers[team.Team.Slug] = map[string][]onCallInterval{}
+		}
+
+		for _, policy := range team.Schedules {
+			analysis.Findings = append(analysis.Findings, findCoverageGaps(team.Team, policy, opts)...)
+			analysis.Findings = append(analysis.Findings, findOverlappingOverrides(team.Team, policy)...)
+
+			for username, intervals := range policyUserIntervals(policy) {
+				teamUsers[team.Team.Slug][username] = append(teamUsers[team.Team.Slug][username], intervals...)
+			}
+		}
+	}
+
+	analysis.Findings = append(analysis.Findings, findConcurrentTeams(teamUsers)...)
+	if opts.MaxShift > 0 {
+		analysis.Findings = append(analysis.Findings, findLongShifts(teamUsers, opts.MaxShift)...)
+	}
+
+	sort.SliceStable(analysis.Findings, func(a, b int) bool {
+		fa, fb := analysis.Findings[a], analysis.Findings[b]
+		if !fa.Start.Equal(fb.Start) {
+			return fa.Start.Before(fb.Start)
+		}
+		if fa.Kind != fb.Kind {
+			return fa.Kind < fb.Kind
+		}
+		if fa.Username != fb.Username {
+			return fa.Username < fb.Username
+		}
+		if strings.Join(fa.Teams, ",") != strings.Join(fb.Teams, ",") {
+			return strings.Join(fa.Teams, ",") < strings.Join(fb.Teams, ",")
+		}
+		return fa.Policy < fb.Policy
+	})
+
+	return &analysis
+}
+
+func findCoverageGaps(team ApiTeam, policy ApiEscalationPolicySchedule, opts OnCallAnalysisOptions) []OnCallFinding {
+	var covered []onCallInterval
+	for _, entry := range policy.Schedule {
+		for _, roll := range entry.Rolls {
+			covered = append(covered, onCallInterval{start: roll.Start, end: roll.End})
+		}
+	}
+	covered = mergeIntervals(covered)
+
+	window := onCallInterval{start: opts.Start, end: opts.End}
+	if window.start.IsZero() || window.end.IsZero() {
+		if len(covered) == 0 {
+			return nil
+		}
+		window = onCallInterval{start: covered[0].start, end: covered[len(covered)-1].end}
+	}
+
+	var findings []OnCallFinding
+	for _, gap := range subtractIntervals([]onCallInterval{window}, covered) {
+		findings = append(findings, OnCallFinding{
+			Kind:    OnCallCoverageGap,
+			Teams:   []string{team.Slug},
+			Policy:  policy.Policy.Slug,
+			Start:   gap.start,
+			End:     gap.end,
+			Message: fmt.Sprintf("nobody is on call for policy %s of team %s for %s", policy.Policy.Slug, team.Slug, gap.end.Sub(gap.start)),
+		})
+	}
+	return findings
+}
+
+func findOverlappingOverrides(team ApiTeam, policy ApiEscalationPolicySchedule) []OnCallFinding {
+	var findings []OnCallFinding
+	for i := 0; i < len(policy.Overrides); i++ {
+		for j := i + 1; j < len(policy.Overrides); j++ {
+			a, b := policy.Overrides[i], policy.Overrides[j]
+			if a.OrigOnCallUser.Username != b.OrigOnCallUser.Username {
+				continue
+			}
+			overlap := intersectIntervals(
+				[]onCallInterval{{start: a.Start, end: a.End}},
+				[]onCallInterval{{start: b.Start, end: b.End}},
+			)
+			if len(overlap) == 0 {
+				continue
+			}
+			findings = append(findings, OnCallFinding{
+				Kind:     OnCallOverlappingOverrides,
+				Teams:    []string{team.Slug},
+				Policy:   policy.Policy.Slug,
+				Username: a.OrigOnCallUser.Username,
+				Start:    overlap[0].start,
+				End:      overlap[0].end,
+				Message: fmt.Sprintf("overrides by %s and %s both replace %s in policy %s",
+					a.OverrideOnCallUser.Username, b.OverrideOnCallUser.Username, a.OrigOnCallUser.Username, policy.Policy.Slug),
+			})
+		}
+	}
+	return findings
+}
+
+func findConcurrentTeams(teamUsers map[string]map[string][]onCallInterval) []OnCallFinding {
+	var teams []string
+	for slug := range teamUsers {
+		teams = append(teams, slug)
+	}
+	sort.Strings(teams)
+
+	var findings []OnCallFinding
+	for i := 0; i < len(teams); i++ {
+		for j := i + 1; j < len(teams); j++ {
+			for username, intervals := range teamUsers[teams[i]] {
+				other, ok := teamUsers[teams[j]][username]
+				if !ok {
+					continue
+				}
+				for _, overlap := range intersectIntervals(intervals, other) {
+					findings = append(findings, OnCallFinding{
+						Kind:     OnCallConcurrentTeams,
+						Teams:    []string{teams[i], teams[j]},
+						Username: username,
+						Start:    overlap.start,
+						End:      overlap.end,
+						Message:  fmt.Sprintf("%s is on call for teams %s and %s at the same time", username, teams[i], teams[j]),
+					})
+				}
+			}
+		}
+	}
+	return findings
+}
+
+func findLongShifts(teamUsers map[string]map[string][]onCallInterval, maxShift time.Duration) []OnCallFinding {
+	var findings []OnCallFinding
+	for team, users := range teamUsers {
+		for username, intervals := range users {
+			for _, shift := range mergeIntervals(intervals) {
+				length := shift.end.Sub(shift.start)
+				if length <= maxShift {
+					continue
+				}
+				findings = append(findings, OnCallFinding{
+					Kind:     OnCallLongShift,
+					Teams:    []string{team},
+					Username: username,
+					Start:    shift.start,
+					End:      shift.end,
+					Message:  fmt.Sprintf("%s is on call for team %s for %s, longer than %s", username, team, length, maxShift),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// AnalyzeTeamOnCallSchedules fetches the schedules of the given teams and analyzes them together
+func (c Client) AnalyzeTeamOnCallSchedules(teamSlugs []string, daysForward int, opts OnCallAnalysisOptions) (*OnCallAnalysis, *RequestDetails, error) {
+	var schedules []ApiTeamSchedule
+	var details *RequestDetails
+	for _, slug := range teamSlugs {
+		schedule, d, err := c.GetApiTeamSchedule(slug, daysForward, 0, 0)
+		details = d
+		if err != nil {
+			return nil, details, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+
+	return AnalyzeOnCallSchedules(schedules, opts), details, nil
+}
@@ -0,0 +1,198 @@
+package victorops
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func mustParseTime(t *testing.T, value string) time.Time {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func testRoll(t *testing.T, username string, start string, end string) ApiOnCallRoll {
+	return ApiOnCallRoll{
+		Start:      mustParseTime(t, start),
+		End:        mustParseTime(t, end),
+		OnCallUser: ApiUser{Username: username},
+		IsRoll:     true,
+	}
+}
+
+func TestAnalyzeOnCallSchedulesCoverageGap(t *testing.T) {
+	schedules := []ApiTeamSchedule{
+		{
+			Team: ApiTeam{Slug: "team-abcd"},
+			Schedules: []ApiEscalationPolicySchedule{
+				{
+					Policy: ApiEscalationPolicy{Slug: "pol-abcd"},
+					Schedule: []ApiOnCallEntry{
+						{
+							Rolls: []ApiOnCallRoll{
+								testRoll(t, "janedoe", "2020-03-31T09:00:00Z", "2020-04-01T09:00:00Z"),
+								testRoll(t, "johndoe", "2020-04-01T12:00:00Z", "2020-04-02T09:00:00Z"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	analysis := AnalyzeOnCallSchedules(schedules, OnCallAnalysisOptions{})
+	gaps := analysis.FindingsOfKind(OnCallCoverageGap)
+	if len(gaps) != 1 {
+		t.Fatalf("expected 1 coverage gap, got %#v", analysis.Findings)
+	}
+
+	if !gaps[0].Start.Equal(mustParseTime(t, "2020-04-01T09:00:00Z")) || !gaps[0].End.Equal(mustParseTime(t, "2020-04-01T12:00:00Z")) {
+		t.Errorf("unexpected gap %s - %s", gaps[0].Start, gaps[0].End)
+	}
+
+	if gaps[0].Policy != "pol-abcd" {
+		t.Errorf("unexpected policy %s", gaps[0].Policy)
+	}
+
+	// An explicit window reports uncovered time at its edges too
+	analysis = AnalyzeOnCallSchedules(schedules, OnCallAnalysisOptions{
+		Start: mustParseTime(t, "2020-03-31T00:00:00Z"),
+		End:   mustParseTime(t, "2020-04-02T09:00:00Z"),
+	})
+	if gaps := analysis.FindingsOfKind(OnCallCoverageGap); len(gaps) != 2 {
+		t.Errorf("expected 2 coverage gaps, got %#v", gaps)
+	}
+}
+
+func TestAnalyzeOnCallSchedulesOverrides(t *testing.T) {
+	schedules := []ApiTeamSchedule{
+		{
+			Team: ApiTeam{Slug: "team-abcd"},
+			Schedules: []ApiEscalationPolicySchedule{
+				{
+					Policy: ApiEscalationPolicy{Slug: "pol-abcd"},
+					Schedule: []ApiOnCallEntry{
+						{
+							Rolls: []ApiOnCallRoll{
+								testRoll(t, "janedoe", "2020-03-31T09:00:00Z", "2020-04-07T09:00:00Z"),
+							},
+						},
+					},
+					Overrides: []ApiOnCallOverride{
+						{
+							OrigOnCallUser:     ApiUser{Username: "janedoe"},
+							OverrideOnCallUser: ApiUser{Username: "johndoe"},
+							Start:              mustParseTime(t, "2020-04-01T09:00:00Z"),
+							End:                mustParseTime(t, "2020-04-02T09:00:00Z"),
+						},
+						{
+							OrigOnCallUser:     ApiUser{Username: "janedoe"},
+							OverrideOnCallUser: ApiUser{Username: "bobsmith"},
+							Start:              mustParseTime(t, "2020-04-02T00:00:00Z"),
+							End:                mustParseTime(t, "2020-04-03T00:00:00Z"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	analysis := AnalyzeOnCallSchedules(schedules, OnCallAnalysisOptions{MaxShift: 48 * time.Hour})
+
+	overlaps := analysis.FindingsOfKind(OnCallOverlappingOverrides)
+	if len(overlaps) != 1 {
+		t.Fatalf("expected 1 overlapping override, got %#v", analysis.Findings)
+	}
+	if !overlaps[0].Start.Equal(mustParseTime(t, "2020-04-02T00:00:00Z")) || !overlaps[0].End.Equal(mustParseTime(t, "2020-04-02T09:00:00Z")) {
+		t.Errorf("unexpected overlap %s - %s", overlaps[0].Start, overlaps[0].End)
+	}
+
+	// janedoe is relieved by the overrides, so only the stretch after them is too long
+	long := analysis.FindingsOfKind(OnCallLongShift)
+	if len(long) != 1 {
+		t.Fatalf("expected 1 long shift, got %#v", long)
+	}
+	if long[0].Username != "janedoe" || !long[0].Start.Equal(mustParseTime(t, "2020-04-03T00:00:00Z")) {
+		t.Errorf("unexpected long shift %#v", long[0])
+	}
+}
+
+func TestAnalyzeOnCallSchedulesConcurrentTeams(t *testing.T) {
+	schedules := []ApiTeamSchedule{
+		{
+			Team: ApiTeam{Slug: "team-a"},
+			Schedules: []ApiEscalationPolicySchedule{
+				{
+					Policy: ApiEscalationPolicy{Slug: "pol-a"},
+					Schedule: []ApiOnCallEntry{
+						{Rolls: []ApiOnCallRoll{testRoll(t, "janedoe", "2020-03-31T09:00:00Z", "2020-04-01T09:00:00Z")}},
+					},
+				},
+			},
+		},
+		{
+			Team: ApiTeam{Slug: "team-b"},
+			Schedules: []ApiEscalationPolicySchedule{
+				{
+					Policy: ApiEscalationPolicy{Slug: "pol-b"},
+					Schedule: []ApiOnCallEntry{
+						{Rolls: []ApiOnCallRoll{testRoll(t, "janedoe", "2020-03-31T21:00:00Z", "2020-04-01T21:00:00Z")}},
+					},
+				},
+			},
+		},
+	}
+
+	analysis := AnalyzeOnCallSchedules(schedules, OnCallAnalysisOptions{})
+	if len(analysis.Findings) != 1 {
+		t.Fatalf("expected 1 finding, got %#v", analysis.Findings)
+	}
+
+	finding := analysis.Findings[0]
+	if finding.Kind != OnCallConcurrentTeams || finding.Username != "janedoe" {
+		t.Errorf("unexpected finding %#v", finding)
+	}
+	if finding.End.Sub(finding.Start) != 12*time.Hour {
+		t.Errorf("expected a 12h overlap, got %s", finding.End.Sub(finding.Start))
+	}
+}
+
+func TestAnalyzeTeamOnCallSchedules(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/api-public/v2/team/team-abcd/oncall/schedule", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		w.Write([]byte(`
+        {
+          "team": {"name": "Infrastructure", "slug": "team-abcd"},
+          "schedules": [
+            {
+              "policy": {"name": "High Severity", "slug": "pol-abcd"},
+              "schedule": [
+                {
+                  "onCallUser": {"username": "janedoe"},
+                  "rolls": [
+                    {"start": "2020-03-31T09:00:00Z", "end": "2020-04-07T09:00:00Z", "onCallUser": {"username": "janedoe"}, "isRoll": true}
+                  ]
+                }
+              ],
+              "overrides": []
+            }
+          ]
+        }
+        `))
+	})
+
+	analysis, _, err := testClient.AnalyzeTeamOnCallSchedules([]string{"team-abcd"}, 14, OnCallAnalysisOptions{MaxShift: 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(analysis.FindingsOfKind(OnCallLongShift)) != 1 {
+		t.Errorf("expected a long shift finding, got %#v", analysis.Findings)
+	}
+}
@@ -0,0 +1,120 @@
+package victorops
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// onCallLogPageLimit is the number of user logs requested per page of the on-call log
+const onCallLogPageLimit = 100
+
+// OnCallLogDuration is a length of time as returned by the reporting API
+type OnCallLogDuration struct {
+	Hours   int `json:"hours"`
+	Minutes int `json:"minutes"`
+}
+
+// Duration converts the reported hours and minutes to a time.Duration
+func (d OnCallLogDuration) Duration() time.Duration {
+	return time.Duration(d.Hours)*time.Hour + time.Duration(d.Minutes)*time.Minute
+}
+
+// OnCallLogEntry is a single period a user was on call for an escalation policy
+type OnCallLogEntry struct {
+	On               time.Time           `json:"on"`
+	Off              time.Time           `json:"off"`
+	Duration         OnCallLogDuration   `json:"duration"`
+	EscalationPolicy ApiEscalationPolicy `json:"escalationPolicy"`
+}
+
+// UserOnCallLog holds the periods a user was on call for a team
+type UserOnCallLog struct {
+	UserID        string            `json:"userId"`
+	AdjustedTotal OnCallLogDuration `json:"adjustedTotal"`
+	Total         OnCallLogDuration `json:"total"`
+	Log           []OnCallLogEntry  `json:"log"`
+}
+
+// TeamOnCallLog is the historical on-call log of a team
+type TeamOnCallLog struct {
+	TeamSlug string          `json:"teamSlug"`
+	Start    time.Time       `json:"start"`
+	End      time.Time       `json:"end"`
+	Offset   int             `json:"offset"`
+	Limit    int             `json:"limit"`
+	Total    int             `json:"total"`
+	UserLogs []UserOnCallLog `json:"userLogs"`
+}
+
+func parseTeamOnCallLogResponse(response string) (*TeamOnCallLog, error) {
+	var log TeamOnCallLog
+	err := json.Unmarshal([]byte(response), &log)
+	if err != nil {
+		return nil, err
+	}
+
+	return &log, err
+}
+
+// GetTeamOnCallLog returns who was on call for a team between start and end. All pages
+// of the log are fetched and returned as a single log. Paging stops at the first page
+// without users that weren't on the previous ones, so a server that ignores the offset
+// can't keep it going.
+func (c Client) GetTeamOnCallLog(teamSlug string, start time.Time, end time.Time) (*TeamOnCallLog, *RequestDetails, error) {
+	var onCallLog *TeamOnCallLog
+	var details *RequestDetails
+	seen := map[string]bool{}
+
+	for offset := 0; ; offset += onCallLogPageLimit {
+		var err error
+		details, err = c.makePublicAPICall("GET", fmt.Sprintf("v2/reporting/team/%s/oncall/log", teamSlug), bytes.NewBufferString("{}"), map[string]string{
+			"start":  start.Format(time.RFC3339),
+			"end":    end.Format(time.RFC3339),
+			"limit":  strconv.Itoa(onCallLogPageLimit),
+			"offset": strconv.Itoa(offset),
+		})
+		if err != nil {
+			return nil, details, err
+		}
+
+		page, err := parseTeamOnCallLogResponse(details.ResponseBody)
+		if err != nil {
+			return nil, details, err
+		}
+
+		var userLogs []UserOnCallLog
+		for _, userLog := range page.UserLogs {
+			if !seen[userLog.UserID] {
+				seen[userLog.UserID] = true
+				userLogs = append(userLogs, userLog)
+			}
+		}
+
+		if onCallLog == nil {
+			onCallLog = page
+			onCallLog.UserLogs = userLogs
+		} else {
+			onCallLog.UserLogs = append(onCallLog.UserLogs, userLogs...)
+		}
+
+		if len(page.UserLogs) < onCallLogPageLimit || len(userLogs) == 0 || (page.Total > 0 && len(onCallLog.UserLogs) >= page.Total) {
+			break
+		}
+	}
+
+	onCallLog.Offset = 0
+	onCallLog.Limit = len(onCallLog.UserLogs)
+	return onCallLog, details, nil
+}
+
+// OnCallTime returns the total time the user was on call according to the log entries
+func (l UserOnCallLog) OnCallTime() time.Duration {
+	var total time.Duration
+	for _, entry := range l.Log {
+		total += entry.Duration.Duration()
+	}
+	return total
+}
@@ -0,0 +1,105 @@
+package victorops
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGetTeamOnCallLog(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := 0
+	testMux.HandleFunc("/api-public/v2/reporting/team/team-abcd/oncall/log", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		requests++
+
+		if r.URL.Query().Get("start") != "2020-04-01T00:00:00Z" || r.URL.Query().Get("end") != "2020-05-01T00:00:00Z" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+
+		// Serve 120 user logs over two pages
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		var logs []map[string]interface{}
+		for i := offset; i < offset+limit && i < 120; i++ {
+			logs = append(logs, map[string]interface{}{
+				"userId":        fmt.Sprintf("user%d", i),
+				"total":         map[string]int{"hours": 10, "minutes": 30},
+				"adjustedTotal": map[string]int{"hours": 10, "minutes": 30},
+				"log": []map[string]interface{}{
+					{
+						"on":               "2020-04-01T09:00:00Z",
+						"off":              "2020-04-01T19:30:00Z",
+						"duration":         map[string]int{"hours": 10, "minutes": 30},
+						"escalationPolicy": map[string]string{"name": "High Severity", "slug": "pol-abcd"},
+					},
+				},
+			})
+		}
+
+		body, _ := json.Marshal(map[string]interface{}{
+			"teamSlug": "team-abcd",
+			"start":    "2020-04-01T00:00:00Z",
+			"end":      "2020-05-01T00:00:00Z",
+			"offset":   offset,
+			"limit":    limit,
+			"total":    120,
+			"userLogs": logs,
+		})
+		w.Write(body)
+	})
+
+	start, _ := time.Parse(time.RFC3339, "2020-04-01T00:00:00Z")
+	end, _ := time.Parse(time.RFC3339, "2020-05-01T00:00:00Z")
+	resp, _, err := testClient.GetTeamOnCallLog("team-abcd", start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+
+	if len(resp.UserLogs) != 120 || resp.UserLogs[119].UserID != "user119" {
+		t.Fatalf("unexpected user logs %#v", resp.UserLogs)
+	}
+
+	entry := resp.UserLogs[0].Log[0]
+	if entry.EscalationPolicy.Slug != "pol-abcd" || !entry.Off.Equal(entry.On.Add(entry.Duration.Duration())) {
+		t.Errorf("unexpected log entry %#v", entry)
+	}
+
+	if resp.UserLogs[0].OnCallTime() != 10*time.Hour+30*time.Minute {
+		t.Errorf("unexpected on-call time %s", resp.UserLogs[0].OnCallTime())
+	}
+}
+
+func TestGetTeamOnCallLogStopsWithoutNewUsers(t *testing.T) {
+	setup()
+	defer teardown()
+
+	// A server that ignores the offset and has no total serves the same full page forever
+	requests := 0
+	testMux.HandleFunc("/api-public/v2/reporting/team/team-abcd/oncall/log", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var logs []map[string]interface{}
+		for i := 0; i < onCallLogPageLimit; i++ {
+			logs = append(logs, map[string]interface{}{"userId": fmt.Sprintf("user%d", i)})
+		}
+		body, _ := json.Marshal(map[string]interface{}{"teamSlug": "team-abcd", "userLogs": logs})
+		w.Write(body)
+	})
+
+	resp, _, err := testClient.GetTeamOnCallLog("team-abcd", time.Now().Add(-time.Hour), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 || len(resp.UserLogs) != onCallLogPageLimit {
+		t.Errorf("got %d user logs in %d requests, want %d in 2", len(resp.UserLogs), requests, onCallLogPageLimit)
+	}
+}
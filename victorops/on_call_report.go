@@ -0,0 +1,284 @@
+package victorops
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// OnCallLoadReportOptions controls how an on-call load report is computed
+type OnCallLoadReportOptions struct {
+	// Start and End bound the reported period
+	Start time.Time
+	End   time.Time
+	// Location is used to decide which hours fall on weekends or outside business
+	// hours. Defaults to UTC.
+	Location *time.Location
+	// BusinessHoursStart and BusinessHoursEnd are the hours of the day, in Location,
+	// between which weekday on-call time is not considered after hours. Both zero
+	// means 9 to 17.
+	BusinessHoursStart int
+	BusinessHoursEnd   int
+}
+
+// OnCallLoad holds the on-call load of a single user over the reported period
+type OnCallLoad struct {
+	Username          string  `json:"username"`
+	OnCallHours       float64 `json:"onCallHours"`
+	WeekendHours      float64 `json:"weekendHours"`
+	AfterHoursHours   float64 `json:"afterHoursHours"`
+	PagesReceived     int     `json:"pagesReceived"`
+	PagesAcknowledged int     `json:"pagesAcknowledged"`
+}
+
+// OnCallLoadReport holds the on-call load of every user seen in the schedules or incidents
+type OnCallLoadReport struct {
+	Start time.Time    `json:"start"`
+	End   time.Time    `json:"end"`
+	Users []OnCallLoad `json:"users"`
+}
+
+func (opts OnCallLoadReportOptions) businessHours() (int, int) {
+	if opts.BusinessHoursStart == 0 && opts.BusinessHoursEnd == 0 {
+		return 9, 17
+	}
+	return opts.BusinessHoursStart, opts.BusinessHoursEnd
+}
+
+func (opts OnCallLoadReportOptions) location() *time.Location {
+	if opts.Location == nil {
+		return time.UTC
+	}
+	return opts.Location
+}
+
+// NewOnCallLoadReport computes on-call hours and pages for each user over the period
+// in opts. Weekday hours outside business hours count as after hours, weekend hours
+// are only counted as weekend hours. A page is received by every user paged directly
+// or on call for a paged team when the incident started, and acknowledged by the
+// user who acked it.
+func NewOnCallLoadReport(schedules []ApiTeamSchedule, incidents []Incident, opts OnCallLoadReportOptions) *OnCallLoadReport {
+	// team slug -> username -> intervals
+	teamUsers := map[string]map[string][]onCallInterval{}
+	for _, team := range schedules {
+		if teamUsers[team.Team.Slug] == nil {
+			teamUsers[team.Team.Slug] = map[string][]onCallInterval{}
+		}
+		for _, policy := range team.Schedules {
+			for username, intervals := range policyUserIntervals(policy) {
+				teamUsers[team.Team.Slug][username] = append(teamUsers[team.Team.Slug][username], intervals...)
+			}
+		}
+	}
+	return newOnCallLoadReport(teamUsers, incidents, opts)
+}
+
+// NewOnCallLoadReportFromLogs computes the same report as NewOnCallLoadReport from the
+// on-call logs of teams, which record who was actually on call rather than who was
+// scheduled to be
+func NewOnCallLoadReportFromLogs(logs []TeamOnCallLog, incidents []Incident, opts OnCallLoadReportOptions) *OnCallLoadReport {
+	teamUsers := map[string]map[string][]onCallInterval{}
+	for _, log := range logs {
+		if teamUsers[log.TeamSlug] == nil {
+			teamUsers[log.TeamSlug] = map[string][]onCallInterval{}
+		}
+		for _, userLog := range log.UserLogs {
+			for _, entry := range userLog.Log {
+				off := entry.Off
+				if off.IsZero() {
+					off = entry.On.Add(entry.Duration.Duration())
+				}
+				teamUsers[log.TeamSlug][userLog.UserID] = append(teamUsers[log.TeamSlug][userLog.UserID], onCallInterval{start: entry.On, end: off})
+			}
+		}
+	}
+	return newOnCallLoadReport(teamUsers, incidents, opts)
+}
+
+// newOnCallLoadReport computes the report from the intervals each user was on call for
+// each team, keyed by team slug and username
+func newOnCallLoadReport(teamUsers map[string]map[string][]onCallInterval, incidents []Incident, opts OnCallLoadReportOptions) *OnCallLoadReport {
+	period := onCallInterval{start: opts.Start, end: opts.End}
+	loads := map[string]*OnCallLoad{}
+	load := func(username string) *OnCallLoad {
+		if loads[username] == nil {
+			loads[username] = &OnCallLoad{Username: username}
+		}
+		return loads[username]
+	}
+
+	userIntervals := map[string][]onCallInterval{}
+	for _, users := range teamUsers {
+		for username, intervals := range users {
+			userIntervals[username] = append(userIntervals[username], intervals...)
+		}
+	}
+
+	for username, intervals := range userIntervals {
+		l := load(username)
+		for _, i := range intersectIntervals(intervals, []onCallInterval{period}) {
+			total, weekend, afterHours := splitOnCallHours(i, opts)
+			l.OnCallHours += total
+			l.WeekendHours += weekend
+			l.AfterHoursHours += afterHours
+		}
+	}
+
+	for _, incident := range incidents {
+		if incident.StartTime.Before(period.start) || !incident.StartTime.Before(period.end) {
+			continue
+		}
+
+		paged := map[string]bool{}
+		for _, username := range incident.PagedUsers {
+			paged[username] = true
+		}
+		for _, team := range incidentTeams(incident) {
+			for username, intervals := range teamUsers[team] {
+				if onCallAt(intervals, incident.StartTime) {
+					paged[username] = true
+				}
+			}
+		}
+		for username := range paged {
+			load(username).PagesReceived++
+		}
+
+		for _, transition := range incident.Transitions {
+			if transition.Name == "ACKED" && transition.By != "" {
+				load(transition.By).PagesAcknowledged++
+				break
+			}
+		}
+	}
+
+	report := OnCallLoadReport{Start: opts.Start, End: opts.End, Users: []OnCallLoad{}}
+	for _, l := range loads {
+		l.OnCallHours = roundHours(l.OnCallHours)
+		l.WeekendHours = roundHours(l.WeekendHours)
+		l.AfterHoursHours = roundHours(l.AfterHoursHours)
+		report.Users = append(report.Users, *l)
+	}
+	sort.Slice(report.Users, func(a, b int) bool { return report.Users[a].Username < report.Users[b].Username })
+
+	return &report
+}
+
+// incidentTeams returns the slugs of the teams paged by an incident
+func incidentTeams(incident Incident) []string {
+	teams := append([]string{}, incident.PagedTeams...)
+	for _, policy := range incident.PagedPolicies {
+		teams = append(teams, policy.Team.Slug)
+	}
+	return teams
+}
+
+func onCallAt(intervals []onCallInterval, at time.Time) bool {
+	for _, i := range intervals {
+		if !at.Before(i.start) && at.Before(i.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitOnCallHours returns the total, weekend and weekday after hours length of an interval in hours
+func splitOnCallHours(i onCallInterval, opts OnCallLoadReportOptions) (float64, float64, float64) {
+	loc := opts.location()
+	businessStart, businessEnd := opts.businessHours()
+
+	var total, weekend, afterHours time.Duration
+	for cursor := i.start.In(loc); cursor.Before(i.end); {
+		day := time.Date(cursor.Year(), cursor.Month(), cursor.Day(), 0, 0, 0, 0, loc)
+		next := day.AddDate(0, 0, 1)
+		for _, boundary := range []time.Time{day.Add(time.Duration(businessStart) * time.Hour), day.Add(time.Duration(businessEnd) * time.Hour)} {
+			if boundary.After(cursor) && boundary.Before(next) {
+				next = boundary
+			}
+		}
+		if next.After(i.end) {
+			next = i.end.In(loc)
+		}
+
+		length := next.Sub(cursor)
+		total += length
+		if cursor.Weekday() == time.Saturday || cursor.Weekday() == time.Sunday {
+			weekend += length
+		} else if cursor.Hour() < businessStart || cursor.Hour() >= businessEnd {
+			afterHours += length
+		}
+		cursor = next
+	}
+
+	return total.Hours(), weekend.Hours(), afterHours.Hours()
+}
+
+func roundHours(hours float64) float64 {
+	return math.Round(hours*100) / 100
+}
+
+// WriteJSON writes the report as indented JSON
+func (r OnCallLoadReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteCSV writes the report as CSV with a header row and one row per user
+func (r OnCallLoadReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"username", "on_call_hours", "weekend_hours", "after_hours_hours", "pages_received", "pages_acknowledged"})
+	if err != nil {
+		return err
+	}
+
+	for _, l := range r.Users {
+		err = writer.Write([]string{
+			l.Username,
+			strconv.FormatFloat(l.OnCallHours, 'f', 2, 64),
+			strconv.FormatFloat(l.WeekendHours, 'f', 2, 64),
+			strconv.FormatFloat(l.AfterHoursHours, 'f', 2, 64),
+			strconv.Itoa(l.PagesReceived),
+			strconv.Itoa(l.PagesAcknowledged),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// GetOnCallLoadReport fetches the on-call logs of the given teams and the incident history
+// and computes an on-call load report for the period in opts. The period must have
+// ended, since the logs only record who has already been on call.
+func (c Client) GetOnCallLoadReport(teamSlugs []string, opts OnCallLoadReportOptions) (*OnCallLoadReport, *RequestDetails, error) {
+	if opts.End.After(time.Now()) {
+		return nil, nil, fmt.Errorf("on-call load report period ends in the future at %s", opts.End.Format(time.RFC3339))
+	}
+
+	var logs []TeamOnCallLog
+	for _, slug := range teamSlugs {
+		log, details, err := c.GetTeamOnCallLog(slug, opts.Start, opts.End)
+		if err != nil {
+			return nil, details, err
+		}
+		if log.TeamSlug == "" {
+			log.TeamSlug = slug
+		}
+		logs = append(logs, *log)
+	}
+
+	incidents, details, err := c.GetIncidentHistory(opts.Start, opts.End)
+	if err != nil {
+		return nil, details, err
+	}
+
+	return NewOnCallLoadReportFromLogs(logs, incidents.Incidents, opts), details, nil
+}
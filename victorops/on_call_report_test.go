@@ -0,0 +1,139 @@
+package victorops
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewOnCallLoadReport(t *testing.T) {
+	schedules := []ApiTeamSchedule{
+		{
+			Team: ApiTeam{Slug: "team-abcd"},
+			Schedules: []ApiEscalationPolicySchedule{
+				{
+					Policy: ApiEscalationPolicy{Slug: "pol-abcd"},
+					Schedule: []ApiOnCallEntry{
+						{
+							Rolls: []ApiOnCallRoll{
+								// Friday 09:00 to Monday 09:00
+								testRoll(t, "janedoe", "2020-04-03T09:00:00Z", "2020-04-06T09:00:00Z"),
+								testRoll(t, "johndoe", "2020-04-06T09:00:00Z", "2020-04-13T09:00:00Z"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	incidents := []Incident{
+		{
+			IncidentNumber: "1",
+			StartTime:      mustParseTime(t, "2020-04-04T03:00:00Z"),
+			PagedTeams:     []string{"team-abcd"},
+			Transitions: []Transition{
+				{Name: "ACKED", By: "janedoe", At: mustParseTime(t, "2020-04-04T03:05:00Z")},
+			},
+		},
+		{
+			IncidentNumber: "2",
+			StartTime:      mustParseTime(t, "2020-04-05T03:00:00Z"),
+			PagedUsers:     []string{"johndoe"},
+			PagedTeams:     []string{"team-abcd"},
+		},
+		{
+			// Outside of the reported period
+			IncidentNumber: "3",
+			StartTime:      mustParseTime(t, "2020-04-10T03:00:00Z"),
+			PagedTeams:     []string{"team-abcd"},
+		},
+	}
+
+	report := NewOnCallLoadReport(schedules, incidents, OnCallLoadReportOptions{
+		Start: mustParseTime(t, "2020-04-03T00:00:00Z"),
+		End:   mustParseTime(t, "2020-04-07T00:00:00Z"),
+	})
+
+	want := []OnCallLoad{
+		{Username: "janedoe", OnCallHours: 72, WeekendHours: 48, AfterHoursHours: 16, PagesReceived: 2, PagesAcknowledged: 1},
+		{Username: "johndoe", OnCallHours: 15, WeekendHours: 0, AfterHoursHours: 7, PagesReceived: 1, PagesAcknowledged: 0},
+	}
+
+	if len(report.Users) != len(want) {
+		t.Fatalf("returned \n\n%#v want \n\n%#v", report.Users, want)
+	}
+	for i := range want {
+		if report.Users[i] != want[i] {
+			t.Errorf("returned \n\n%#v want \n\n%#v", report.Users[i], want[i])
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	wantCSV := "username,on_call_hours,weekend_hours,after_hours_hours,pages_received,pages_acknowledged\n" +
+		"janedoe,72.00,48.00,16.00,2,1\n" +
+		"johndoe,15.00,0.00,7.00,1,0\n"
+	if buf.String() != wantCSV {
+		t.Errorf("returned \n\n%s want \n\n%s", buf.String(), wantCSV)
+	}
+
+	buf.Reset()
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"pagesAcknowledged": 1`)) {
+		t.Errorf("unexpected JSON report %s", buf.String())
+	}
+}
+
+func TestGetOnCallLoadReport(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/api-public/v2/reporting/team/team-abcd/oncall/log", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if r.URL.Query().Get("start") != "2020-04-03T00:00:00Z" || r.URL.Query().Get("end") != "2020-04-07T00:00:00Z" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"teamSlug": "team-abcd", "total": 1, "userLogs": [{"userId": "janedoe", "log": [
+			{"on": "2020-04-03T09:00:00Z", "off": "2020-04-06T09:00:00Z", "escalationPolicy": {"slug": "pol-abcd"}}
+		]}]}`)
+	})
+
+	// Resolved incidents from a past period only appear in the incident history
+	testMux.HandleFunc("/api-public/v2/reporting/incidents", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if r.URL.Query().Get("startedAfter") != "2020-04-03T00:00:00Z" || r.URL.Query().Get("startedBefore") != "2020-04-07T00:00:00Z" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"total": 1, "incidents": [{"incidentNumber": "1", "currentPhase": "RESOLVED",
+			"startTime": "2020-04-04T03:00:00Z", "pagedTeams": ["team-abcd"],
+			"transitions": [{"name": "ACKED", "by": "janedoe", "at": "2020-04-04T03:05:00Z"}]}]}`)
+	})
+
+	report, _, err := testClient.GetOnCallLoadReport([]string{"team-abcd"}, OnCallLoadReportOptions{
+		Start: mustParseTime(t, "2020-04-03T00:00:00Z"),
+		End:   mustParseTime(t, "2020-04-07T00:00:00Z"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := OnCallLoad{Username: "janedoe", OnCallHours: 72, WeekendHours: 48, AfterHoursHours: 16, PagesReceived: 1, PagesAcknowledged: 1}
+	if len(report.Users) != 1 || report.Users[0] != want {
+		t.Errorf("returned \n\n%#v want \n\n%#v", report.Users, want)
+	}
+
+	_, _, err = testClient.GetOnCallLoadReport([]string{"team-abcd"}, OnCallLoadReportOptions{
+		Start: time.Now().Add(-time.Hour),
+		End:   time.Now().Add(time.Hour),
+	})
+	if err == nil {
+		t.Error("expected an error for a period ending in the future")
+	}
+}
@@ -0,0 +1,244 @@
+package victorops
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// The notification types used in paging policy rules
+const (
+	NotificationTypePush  = "push"
+	NotificationTypeEmail = "email"
+	NotificationTypePhone = "phone"
+	NotificationTypeSMS   = "sms"
+)
+
+// The kinds of values that can be listed with GetPagingPolicyTypes
+const (
+	PagingPolicyContactTypes      = "contacts"
+	PagingPolicyNotificationTypes = "notifications"
+	PagingPolicyTimeouts          = "timeouts"
+)
+
+// PagingPolicyContact references the contact method a paging policy rule notifies
+type PagingPolicyContact struct {
+	ID   int    `json:"id"`
+	Type string `json:"type,omitempty"`
+}
+
+// PagingPolicyRule is a single notification sent in a paging policy step
+type PagingPolicyRule struct {
+	Index   int                 `json:"index"`
+	Type    string              `json:"type"`
+	Contact PagingPolicyContact `json:"contact"`
+	Timeout int                 `json:"timeout"`
+}
+
+// ContactType returns the type of contact method notified by the rule
+func (r PagingPolicyRule) ContactType() ContactType {
+	return GetContactTypeFromNotificationType(r.Type)
+}
+
+// PagingPolicyStep is a step of a user's paging policy, executed timeout minutes after the previous one
+type PagingPolicyStep struct {
+	Index   int                `json:"index"`
+	Timeout int                `json:"timeout"`
+	Rules   []PagingPolicyRule `json:"rules"`
+}
+
+// PagingPolicy is the personal paging policy of a user
+type PagingPolicy struct {
+	Steps []PagingPolicyStep `json:"steps"`
+}
+
+// PagingPolicyType is a value that can be used in paging policies
+type PagingPolicyType struct {
+	Type        string `json:"type,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Value       int    `json:"value,omitempty"`
+}
+
+// PagingPolicyTypes holds the values returned by GetPagingPolicyTypes
+type PagingPolicyTypes struct {
+	ContactTypes      []PagingPolicyType `json:"contactTypes,omitempty"`
+	NotificationTypes []PagingPolicyType `json:"notificationTypes,omitempty"`
+	Timeouts          []PagingPolicyType `json:"timeouts,omitempty"`
+}
+
+func pagingPolicyEndpoint(username string) string {
+	return "v1/profile/" + url.QueryEscape(username) + "/policies"
+}
+
+func parsePagingPolicyResponse(response string) (*PagingPolicy, error) {
+	var policy PagingPolicy
+	err := json.Unmarshal([]byte(response), &policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, err
+}
+
+func parsePagingPolicyStepResponse(response string) (*PagingPolicyStep, error) {
+	var step PagingPolicyStep
+	err := json.Unmarshal([]byte(response), &step)
+	if err != nil {
+		return nil, err
+	}
+
+	return &step, err
+}
+
+func parsePagingPolicyRuleResponse(response string) (*PagingPolicyRule, error) {
+	var rule PagingPolicyRule
+	err := json.Unmarshal([]byte(response), &rule)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rule, err
+}
+
+// GetPagingPolicy returns the paging policy of a user
+func (c Client) GetPagingPolicy(username string) (*PagingPolicy, *RequestDetails, error) {
+	details, err := c.makePublicAPICall("GET", pagingPolicyEndpoint(username), bytes.NewBufferString("{}"), nil)
+	if err != nil {
+		return nil, details, err
+	}
+
+	policy, err := parsePagingPolicyResponse(details.ResponseBody)
+	if err != nil {
+		return policy, details, err
+	}
+
+	return policy, details, nil
+}
+
+// CreatePagingPolicyStep adds a step to the paging policy of a user
+func (c Client) CreatePagingPolicyStep(username string, step *PagingPolicyStep) (*PagingPolicyStep, *RequestDetails, error) {
+	jsonStep, err := json.Marshal(step)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	details, err := c.makePublicAPICall("POST", pagingPolicyEndpoint(username), bytes.NewBuffer(jsonStep), nil)
+	if err != nil {
+		return nil, details, err
+	}
+
+	newStep, err := parsePagingPolicyStepResponse(details.ResponseBody)
+	if err != nil {
+		return newStep, details, err
+	}
+
+	return newStep, details, nil
+}
+
+// GetPagingPolicyStep returns a single step of the paging policy of a user
+func (c Client) GetPagingPolicyStep(username string, stepIndex int) (*PagingPolicyStep, *RequestDetails, error) {
+	details, err := c.makePublicAPICall("GET", fmt.Sprintf("%s/%d", pagingPolicyEndpoint(username), stepIndex), bytes.NewBufferString("{}"), nil)
+	if err != nil {
+		return nil, details, err
+	}
+
+	step, err := parsePagingPolicyStepResponse(details.ResponseBody)
+	if err != nil {
+		return step, details, err
+	}
+
+	return step, details, nil
+}
+
+// UpdatePagingPolicyStep replaces the step of a user's paging policy with the same index
+func (c Client) UpdatePagingPolicyStep(username string, step *PagingPolicyStep) (*PagingPolicyStep, *RequestDetails, error) {
+	jsonStep, err := json.Marshal(step)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	details, err := c.makePublicAPICall("PUT", fmt.Sprintf("%s/%d", pagingPolicyEndpoint(username), step.Index), bytes.NewBuffer(jsonStep), nil)
+	if err != nil {
+		return nil, details, err
+	}
+
+	updatedStep, err := parsePagingPolicyStepResponse(details.ResponseBody)
+	if err != nil {
+		return updatedStep, details, err
+	}
+
+	return updatedStep, details, nil
+}
+
+// CreatePagingPolicyRule adds a rule to a step of the paging policy of a user
+func (c Client) CreatePagingPolicyRule(username string, stepIndex int, rule *PagingPolicyRule) (*PagingPolicyRule, *RequestDetails, error) {
+	jsonRule, err := json.Marshal(rule)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	details, err := c.makePublicAPICall("POST", fmt.Sprintf("%s/%d", pagingPolicyEndpoint(username), stepIndex), bytes.NewBuffer(jsonRule), nil)
+	if err != nil {
+		return nil, details, err
+	}
+
+	newRule, err := parsePagingPolicyRuleResponse(details.ResponseBody)
+	if err != nil {
+		return newRule, details, err
+	}
+
+	return newRule, details, nil
+}
+
+// UpdatePagingPolicyRule replaces the rule with the same index in a step of the paging policy of a user
+func (c Client) UpdatePagingPolicyRule(username string, stepIndex int, rule *PagingPolicyRule) (*PagingPolicyRule, *RequestDetails, error) {
+	jsonRule, err := json.Marshal(rule)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	details, err := c.makePublicAPICall("PUT", fmt.Sprintf("%s/%d/%d", pagingPolicyEndpoint(username), stepIndex, rule.Index), bytes.NewBuffer(jsonRule), nil)
+	if err != nil {
+		return nil, details, err
+	}
+
+	updatedRule, err := parsePagingPolicyRuleResponse(details.ResponseBody)
+	if err != nil {
+		return updatedRule, details, err
+	}
+
+	return updatedRule, details, nil
+}
+
+// DeletePagingPolicyRule deletes a rule from a step of the paging policy of a user
+func (c Client) DeletePagingPolicyRule(username string, stepIndex int, ruleIndex int) (*RequestDetails, error) {
+	details, err := c.makePublicAPICall("DELETE", fmt.Sprintf("%s/%d/%d", pagingPolicyEndpoint(username), stepIndex, ruleIndex), bytes.NewBufferString("{}"), nil)
+	return details, err
+}
+
+// GetPagingPolicyTypes lists the values that can be used in paging policies. kind is one of
+// PagingPolicyContactTypes, PagingPolicyNotificationTypes or PagingPolicyTimeouts.
+func (c Client) GetPagingPolicyTypes(kind string) (*PagingPolicyTypes, *RequestDetails, error) {
+	details, err := c.makePublicAPICall("GET", "v1/policies/types/"+kind, bytes.NewBufferString("{}"), nil)
+	if err != nil {
+		return nil, details, err
+	}
+
+	var types PagingPolicyTypes
+	err = json.Unmarshal([]byte(details.ResponseBody), &types)
+	if err != nil {
+		return nil, details, err
+	}
+
+	return &types, details, nil
+}
+
+// GetPagingPolicyRuleContact returns the contact method notified by a rule of a user's paging policy
+func (c Client) GetPagingPolicyRuleContact(username string, rule PagingPolicyRule) (*Contact, *RequestDetails, error) {
+	if rule.ContactType() == (ContactType{}) {
+		return nil, nil, fmt.Errorf("unknown notification type %q", rule.Type)
+	}
+	return c.GetContactByID(username, rule.Contact.ID, rule.ContactType())
+}
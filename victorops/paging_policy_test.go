@@ -0,0 +1,118 @@
+package victorops
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestGetPagingPolicy(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/api-public/v1/profile/janedoe/policies", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		w.Write([]byte(`
+		{
+			"steps": [
+				{
+					"index": 0,
+					"timeout": 0,
+					"rules": [
+						{"index": 0, "type": "push", "contact": {"id": 0, "type": "device"}, "timeout": 0},
+						{"index": 1, "type": "sms", "contact": {"id": 42, "type": "phone"}, "timeout": 5}
+					]
+				}
+			]
+		}
+		`))
+	})
+	testMux.HandleFunc("/api-public/v1/user/janedoe/contact-methods/phones", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"contactMethods": [{"id": 42, "label": "Mobile", "value": "+15555555555", "rank": 1}]}`))
+	})
+
+	resp, _, err := testClient.GetPagingPolicy("janedoe")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &PagingPolicy{
+		Steps: []PagingPolicyStep{
+			{
+				Index:   0,
+				Timeout: 0,
+				Rules: []PagingPolicyRule{
+					{Index: 0, Type: NotificationTypePush, Contact: PagingPolicyContact{ID: 0, Type: "device"}, Timeout: 0},
+					{Index: 1, Type: NotificationTypeSMS, Contact: PagingPolicyContact{ID: 42, Type: "phone"}, Timeout: 5},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(resp, want) {
+		t.Errorf("returned \n\n%#v want \n\n%#v", resp, want)
+	}
+
+	if resp.Steps[0].Rules[0].ContactType() != GetContactTypes().Device {
+		t.Errorf("expected push rules to notify devices")
+	}
+
+	contact, _, err := testClient.GetPagingPolicyRuleContact("janedoe", resp.Steps[0].Rules[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contact.Value != "+15555555555" {
+		t.Errorf("unexpected contact %#v", contact)
+	}
+}
+
+func TestUpdatePagingPolicyRule(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/api-public/v1/profile/janedoe/policies/0/1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+
+		var rule PagingPolicyRule
+		if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+			t.Fatal(err)
+		}
+		rule.Contact.Type = "phone"
+		body, _ := json.Marshal(rule)
+		w.Write(body)
+	})
+
+	resp, _, err := testClient.UpdatePagingPolicyRule("janedoe", 0, &PagingPolicyRule{Index: 1, Type: NotificationTypePhone, Contact: PagingPolicyContact{ID: 42}, Timeout: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &PagingPolicyRule{Index: 1, Type: NotificationTypePhone, Contact: PagingPolicyContact{ID: 42, Type: "phone"}, Timeout: 10}
+	if !reflect.DeepEqual(resp, want) {
+		t.Errorf("returned \n\n%#v want \n\n%#v", resp, want)
+	}
+}
+
+func TestGetPagingPolicyTypes(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/api-public/v1/policies/types/notifications", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		w.Write([]byte(`{"notificationTypes": [{"type": "push", "name": "Push"}, {"type": "email", "name": "Email"}]}`))
+	})
+
+	resp, _, err := testClient.GetPagingPolicyTypes(PagingPolicyNotificationTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, notificationType := range resp.NotificationTypes {
+		if GetContactTypeFromNotificationType(notificationType.Type) == (ContactType{}) {
+			t.Errorf("no contact type for notification type %s", notificationType.Type)
+		}
+	}
+	if len(resp.NotificationTypes) != 2 {
+		t.Errorf("unexpected notification types %#v", resp.NotificationTypes)
+	}
+}
@@ -0,0 +1,297 @@
+package victorops
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RoutingGraph holds everything needed to draw how incidents are routed in the org
+type RoutingGraph struct {
+	Teams       []Team
+	Policies    []EscalationPolicy
+	RoutingKeys []RoutingKey
+}
+
+// RoutingGraphOptions controls what is drawn by WriteDOT and WriteMermaid
+type RoutingGraphOptions struct {
+	// Teams limits the graph to the policies of the given team slugs and the routing keys
+	// targeting them. Policies of other teams that are handed off to are drawn without
+	// their steps. Empty draws everything.
+	Teams []string
+}
+
+type routingGraphNode struct {
+	id    string
+	label string
+	shape string
+	team  string
+}
+
+type routingGraphEdge struct {
+	from  string
+	to    string
+	label string
+}
+
+type routingGraphModel struct {
+	nodes map[string]routingGraphNode
+	edges []routingGraphEdge
+	teams map[string]string
+}
+
+var routingGraphIDPattern = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+func routingGraphID(kind string, name string) string {
+	return kind + "_" + routingGraphIDPattern.ReplaceAllString(name, "_")
+}
+
+// GetRoutingGraph fetches the teams, escalation policies and routing keys of the org
+func (c Client) GetRoutingGraph() (*RoutingGraph, *RequestDetails, error) {
+	teams, details, err := c.GetAllTeams()
+	if err != nil {
+		return nil, details, err
+	}
+
+	policies, details, err := c.GetAllEscalationPoliciesDetailed()
+	if err != nil {
+		return nil, details, err
+	}
+
+	routingKeys, details, err := c.GetAllRoutingKeys()
+	if err != nil {
+		return nil, details, err
+	}
+
+	return &RoutingGraph{Teams: *teams, Policies: policies, RoutingKeys: routingKeys.RoutingKeys}, details, nil
+}
+
+func (g RoutingGraph) model(opts RoutingGraphOptions) *routingGraphModel {
+	m := routingGraphModel{nodes: map[string]routingGraphNode{}, teams: map[string]string{}}
+	for _, team := range g.Teams {
+		m.teams[team.Slug] = team.Name
+	}
+
+	included := func(teamSlug string) bool {
+		if len(opts.Teams) == 0 {
+			return true
+		}
+		for _, slug := range opts.Teams {
+			if slug == teamSlug {
+				return true
+			}
+		}
+		return false
+	}
+
+	policies := map[string]EscalationPolicy{}
+	for _, policy := range g.Policies {
+		policies[policy.ID] = policy
+	}
+
+	addPolicy := func(slug string, name string) string {
+		id := routingGraphID("policy", slug)
+		if policy, ok := policies[slug]; ok {
+			name = policy.Name
+		}
+		if name == "" {
+			name = slug
+		}
+		m.nodes[id] = routingGraphNode{id: id, label: name, shape: "policy", team: policies[slug].TeamID}
+		return id
+	}
+
+	for _, key := range g.RoutingKeys {
+		keyID := routingGraphID("key", key.RoutingKey)
+		for _, target := range key.Targets {
+			teamSlug := target.TeamSlug()
+			if policy, ok := policies[target.PolicySlug]; ok {
+				teamSlug = policy.TeamID
+			}
+			if !included(teamSlug) {
+				continue
+			}
+			m.nodes[keyID] = routingGraphNode{id: keyID, label: key.RoutingKey, shape: "key"}
+			m.edges = append(m.edges, routingGraphEdge{from: keyID, to: addPolicy(target.PolicySlug, target.PolicyName)})
+		}
+	}
+
+	for _, policy := range g.Policies {
+		if !included(policy.TeamID) {
+			continue
+		}
+		policyID := addPolicy(policy.ID, policy.Name)
+
+		for i, step := range policy.Steps {
+			stepID := fmt.Sprintf("%s_step%d", policyID, i+1)
+			m.nodes[stepID] = routingGraphNode{id: stepID, label: fmt.Sprintf("Step %d", i+1), shape: "step", team: policy.TeamID}
+			edgeLabel := "immediately"
+			if step.Timeout > 0 {
+				edgeLabel = fmt.Sprintf("after %dm", step.Timeout)
+			}
+			from := policyID
+			if i > 0 {
+				from = fmt.Sprintf("%s_step%d", policyID, i)
+			}
+			m.edges = append(m.edges, routingGraphEdge{from: from, to: stepID, label: edgeLabel})
+
+			for _, entry := range step.Entries {
+				target, _, _ := entry.target()
+				var targetID string
+				switch entry.ExecutionType {
+				case ExecutionTypeUser:
+					targetID = routingGraphID("user", target)
+					m.nodes[targetID] = routingGraphNode{id: targetID, label: target, shape: "user"}
+				case ExecutionTypeRotationGroup, ExecutionTypeRotationGroupNext, ExecutionTypeRotationGroupPrevious:
+					targetID = routingGraphID("rotation", target)
+					label := target
+					if entry.RotationGroup != nil && entry.RotationGroup.Label != "" {
+						label = entry.RotationGroup.Label
+					}
+					m.nodes[targetID] = routingGraphNode{id: targetID, label: label, shape: "rotation", team: policy.TeamID}
+				case ExecutionTypeWebhook:
+					targetID = routingGraphID("webhook", target)
+					m.nodes[targetID] = routingGraphNode{id: targetID, label: target, shape: "webhook"}
+				case ExecutionTypeEmail:
+					targetID = routingGraphID("email", target)
+					m.nodes[targetID] = routingGraphNode{id: targetID, label: target, shape: "email"}
+				case ExecutionTypePolicyRouting:
+					targetID = addPolicy(target, "")
+				default:
+					continue
+				}
+				m.edges = append(m.edges, routingGraphEdge{from: stepID, to: targetID, label: strings.Replace(string(entry.ExecutionType), "rotation_group_", "", 1)})
+			}
+		}
+	}
+
+	sort.SliceStable(m.edges, func(a, b int) bool {
+		if m.edges[a].from != m.edges[b].from {
+			return m.edges[a].from < m.edges[b].from
+		}
+		return m.edges[a].to < m.edges[b].to
+	})
+	return &m
+}
+
+// sortedNodes returns the nodes of the model grouped by team slug, with the team-less
+// nodes under the empty slug
+func (m routingGraphModel) sortedNodes() ([]string, map[string][]routingGraphNode) {
+	byTeam := map[string][]routingGraphNode{}
+	for _, node := range m.nodes {
+		byTeam[node.team] = append(byTeam[node.team], node)
+	}
+
+	var teams []string
+	for team, nodes := range byTeam {
+		teams = append(teams, team)
+		sort.Slice(nodes, func(a, b int) bool { return nodes[a].id < nodes[b].id })
+	}
+	sort.Strings(teams)
+	return teams, byTeam
+}
+
+func (m routingGraphModel) teamName(slug string) string {
+	if name, ok := m.teams[slug]; ok && name != "" {
+		return name
+	}
+	return slug
+}
+
+var dotShapes = map[string]string{
+	"key":      "cds",
+	"policy":   "box",
+	"step":     "ellipse",
+	"user":     "oval",
+	"rotation": "hexagon",
+	"webhook":  "component",
+	"email":    "note",
+}
+
+func dotQuote(value string) string {
+	return `"` + strings.Replace(strings.Replace(value, `\`, `\\`, -1), `"`, `\"`, -1) + `"`
+}
+
+// WriteDOT writes the routing graph in the Graphviz DOT language
+func (g RoutingGraph) WriteDOT(w io.Writer, opts RoutingGraphOptions) error {
+	m := g.model(opts)
+	var b strings.Builder
+
+	b.WriteString("digraph routing {\n")
+	b.WriteString("  rankdir=LR;\n")
+	teams, byTeam := m.sortedNodes()
+	for _, team := range teams {
+		indent := "  "
+		if team != "" {
+			fmt.Fprintf(&b, "  subgraph %s {\n", routingGraphID("cluster", team))
+			fmt.Fprintf(&b, "    label=%s;\n", dotQuote(m.teamName(team)))
+			indent = "    "
+		}
+		for _, node := range byTeam[team] {
+			fmt.Fprintf(&b, "%s%s [label=%s, shape=%s];\n", indent, node.id, dotQuote(node.label), dotShapes[node.shape])
+		}
+		if team != "" {
+			b.WriteString("  }\n")
+		}
+	}
+	for _, edge := range m.edges {
+		if edge.label == "" {
+			fmt.Fprintf(&b, "  %s -> %s;\n", edge.from, edge.to)
+		} else {
+			fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", edge.from, edge.to, dotQuote(edge.label))
+		}
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var mermaidShapes = map[string][2]string{
+	"key":      {">", "]"},
+	"policy":   {"[", "]"},
+	"step":     {"(", ")"},
+	"user":     {"([", "])"},
+	"rotation": {"{{", "}}"},
+	"webhook":  {"[[", "]]"},
+	"email":    {"[/", "/]"},
+}
+
+func mermaidQuote(value string) string {
+	return `"` + strings.Replace(value, `"`, "#quot;", -1) + `"`
+}
+
+// WriteMermaid writes the routing graph as a Mermaid flowchart
+func (g RoutingGraph) WriteMermaid(w io.Writer, opts RoutingGraphOptions) error {
+	m := g.model(opts)
+	var b strings.Builder
+
+	b.WriteString("flowchart LR\n")
+	teams, byTeam := m.sortedNodes()
+	for _, team := range teams {
+		indent := "  "
+		if team != "" {
+			fmt.Fprintf(&b, "  subgraph %s [%s]\n", routingGraphID("team", team), mermaidQuote(m.teamName(team)))
+			indent = "    "
+		}
+		for _, node := range byTeam[team] {
+			shape := mermaidShapes[node.shape]
+			fmt.Fprintf(&b, "%s%s%s%s%s\n", indent, node.id, shape[0], mermaidQuote(node.label), shape[1])
+		}
+		if team != "" {
+			b.WriteString("  end\n")
+		}
+	}
+	for _, edge := range m.edges {
+		if edge.label == "" {
+			fmt.Fprintf(&b, "  %s --> %s\n", edge.from, edge.to)
+		} else {
+			fmt.Fprintf(&b, "  %s -->|%s| %s\n", edge.from, mermaidQuote(edge.label), edge.to)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,90 @@
+package victorops
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func testRoutingGraph() RoutingGraph {
+	primary, _ := NewPolicy("Primary").
+		Team("team-abcd").
+		Step(0).NotifyRotation("rtg-abcd").
+		Step(15).NotifyUser("janedoe").RouteToPolicy("pol-b").
+		Build()
+	primary.ID = "pol-a"
+	primary.Steps[0].Entries[0].RotationGroup.Label = "Primary \"on call\""
+
+	management, _ := NewPolicy("Management").
+		Team("team-efgh").
+		Step(0).NotifyEmail("managers@example.com").
+		Build()
+	management.ID = "pol-b"
+
+	return RoutingGraph{
+		Teams: []Team{{Name: "Infrastructure", Slug: "team-abcd"}, {Name: "Management", Slug: "team-efgh"}},
+		Policies: []EscalationPolicy{
+			*primary,
+			*management,
+		},
+		RoutingKeys: []RoutingKey{
+			{RoutingKey: "database", Targets: []RoutingKeyTarget{{PolicySlug: "pol-a"}}},
+			{RoutingKey: "finance", Targets: []RoutingKeyTarget{{PolicySlug: "pol-b"}}},
+		},
+	}
+}
+
+func TestRoutingGraphWriteDOT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testRoutingGraph().WriteDOT(&buf, RoutingGraphOptions{Teams: []string{"team-abcd"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `digraph routing {
+  rankdir=LR;
+  key_database [label="database", shape=cds];
+  user_janedoe [label="janedoe", shape=oval];
+  subgraph cluster_team_abcd {
+    label="Infrastructure";
+    policy_pol_a [label="Primary", shape=box];
+    policy_pol_a_step1 [label="Step 1", shape=ellipse];
+    policy_pol_a_step2 [label="Step 2", shape=ellipse];
+    rotation_rtg_abcd [label="Primary \"on call\"", shape=hexagon];
+  }
+  subgraph cluster_team_efgh {
+    label="Management";
+    policy_pol_b [label="Management", shape=box];
+  }
+  key_database -> policy_pol_a;
+  policy_pol_a -> policy_pol_a_step1 [label="immediately"];
+  policy_pol_a_step1 -> policy_pol_a_step2 [label="after 15m"];
+  policy_pol_a_step1 -> rotation_rtg_abcd [label="rotation_group"];
+  policy_pol_a_step2 -> policy_pol_b [label="policy_routing"];
+  policy_pol_a_step2 -> user_janedoe [label="user"];
+}
+`
+	if buf.String() != want {
+		t.Errorf("returned \n\n%s want \n\n%s", buf.String(), want)
+	}
+}
+
+func TestRoutingGraphWriteMermaid(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testRoutingGraph().WriteMermaid(&buf, RoutingGraphOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"flowchart LR\n",
+		`  key_finance>"finance"]`,
+		`  subgraph team_team_efgh ["Management"]`,
+		`  email_managers_example_com[/"managers@example.com"/]`,
+		`    rotation_rtg_abcd{{"Primary #quot;on call#quot;"}}`,
+		`  key_finance --> policy_pol_b`,
+		`  policy_pol_a_step1 -->|"after 15m"| policy_pol_a_step2`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected mermaid output to contain %q, got \n\n%s", want, buf.String())
+		}
+	}
+}
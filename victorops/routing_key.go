@@ -3,15 +3,96 @@ package victorops
 import (
 	"bytes"
 	"encoding/json"
+	"net/url"
+	"path"
+	"sort"
 )
 
-// RoutingKey is a struct to hold the data for a victorops routing key.
+const routingKeyEndpoint = "v1/org/routing-keys"
+
+// RoutingKeyTarget is an escalation policy targeted by a routing key. Only the policy
+// slug is sent when creating or updating a routing key, reads also return the name of
+// the policy and the URL of its team.
+type RoutingKeyTarget struct {
+	PolicySlug string `json:"policySlug,omitempty"`
+	PolicyName string `json:"policyName,omitempty"`
+	TeamURL    string `json:"_teamUrl,omitempty"`
+}
+
+// UnmarshalJSON parses a routing key target, which is just the policy slug in the
+// responses to creating or updating a routing key
+func (t *RoutingKeyTarget) UnmarshalJSON(data []byte) error {
+	var slug string
+	if json.Unmarshal(data, &slug) == nil {
+		*t = RoutingKeyTarget{PolicySlug: slug}
+		return nil
+	}
+
+	type target RoutingKeyTarget
+	var parsed target
+	err := json.Unmarshal(data, &parsed)
+	if err != nil {
+		return err
+	}
+	*t = RoutingKeyTarget(parsed)
+	return nil
+}
+
+// TeamSlug returns the slug of the team of the targeted policy, parsed from TeamURL
+func (t RoutingKeyTarget) TeamSlug() string {
+	if t.TeamURL == "" {
+		return ""
+	}
+	return path.Base(t.TeamURL)
+}
+
+// RoutingKey is a struct to hold the data for a victorops routing key. Routing keys read
+// from the API can be passed back to CreateRoutingKey as they are.
 type RoutingKey struct {
+	RoutingKey string `json:"routingKey,omitempty"`
+	// Targets were policy slugs before RoutingKey replaced RoutingKeyResponse. Use
+	// NewRoutingKey to create a key from slugs, and PolicySlugs to read them.
+	Targets   []RoutingKeyTarget `json:"targets,omitempty"`
+	IsDefault bool               `json:"isDefault,omitempty"`
+}
+
+// RoutingKeyList is a struct to hold the response from the list routing keys API call
+type RoutingKeyList struct {
+	RoutingKeys []RoutingKey `json:"routingKeys,omitempty"`
+}
+
+// RoutingKeyResponse is the former name of RoutingKey.
+//
+// Deprecated: Use RoutingKey, which is returned by reads as well.
+type RoutingKeyResponse = RoutingKey
+
+// RoutingKeyResponseList is the former name of RoutingKeyList.
+//
+// Deprecated: Use RoutingKeyList.
+type RoutingKeyResponseList = RoutingKeyList
+
+// RoutingKeyResponseTargets is the former name of RoutingKeyTarget.
+//
+// Deprecated: Use RoutingKeyTarget.
+type RoutingKeyResponseTargets = RoutingKeyTarget
+
+// NewRoutingKey returns a routing key targeting the escalation policies with the given slugs
+func NewRoutingKey(name string, policySlugs []string) *RoutingKey {
+	rk := RoutingKey{RoutingKey: name}
+	for _, slug := range policySlugs {
+		rk.Targets = append(rk.Targets, RoutingKeyTarget{PolicySlug: slug})
+	}
+	return &rk
+}
+
+// routingKeyRequest is the body of the requests creating and updating routing keys, which
+// take the targets as policy slugs
+type routingKeyRequest struct {
 	RoutingKey string   `json:"routingKey,omitempty"`
 	Targets    []string `json:"targets,omitempty"`
 }
 
-func parseRoutingKeyResponse(response string) (*RoutingKey, error) {
+func parseRoutingKey(response string) (*RoutingKey, error) {
 	// Parse the response and return the user object
 	var rk RoutingKey
 	err := json.Unmarshal([]byte(response), &rk)
@@ -22,25 +103,36 @@ func parseRoutingKeyResponse(response string) (*RoutingKey, error) {
 	return &rk, err
 }
 
-// In the request to create a routing key, we supply a list of strings which are
-// target escalaption policy slugs
-// In the response while getting routing keys, we get a different type of result which is
-// a map of values like so: {"policyName":"Moderate Severity","policySlug":"pol-tq09wTVkG7BzuMY0","_teamUrl":"/api-public/v1/team/team-Iei67wjVsD14Pe4O"}
-// So these structs exist to represent read responses rather that create requests.
-type RoutingKeyResponse struct {
-	RoutingKey string                      `json:"routingKey,omitempty"`
-	Targets    []RoutingKeyResponseTargets `json:"targets,omitempty"`
-}
-type RoutingKeyResponseList struct {
-	RoutingKeys []RoutingKeyResponse `json:"routingKeys,omitempty"`
+// PolicySlugs returns the slugs of the escalation policies targeted by the routing key
+func (rk RoutingKey) PolicySlugs() []string {
+	slugs := []string{}
+	for _, target := range rk.Targets {
+		slugs = append(slugs, target.PolicySlug)
+	}
+	return slugs
 }
-type RoutingKeyResponseTargets struct {
-	PolicySlug string `json:"policySlug,omitempty"`
+
+// SameTargets returns true if both routing keys target the same policies, in any order
+func (rk RoutingKey) SameTargets(other RoutingKey) bool {
+	if len(rk.Targets) != len(other.Targets) {
+		return false
+	}
+
+	a := rk.PolicySlugs()
+	b := other.PolicySlugs()
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
 }
 
-func parseRoutingKeyListResponse(response string) (*RoutingKeyResponseList, error) {
+func parseRoutingKeyListResponse(response string) (*RoutingKeyList, error) {
 	// Parse the response and return the user object
-	var rkrl RoutingKeyResponseList
+	var rkrl RoutingKeyList
 	err := json.Unmarshal([]byte(response), &rkrl)
 	if err != nil {
 		return nil, err
@@ -51,18 +143,18 @@ func parseRoutingKeyListResponse(response string) (*RoutingKeyResponseList, erro
 
 // CreateRoutingKey creates a routingkey in the victorops organization
 func (c Client) CreateRoutingKey(routingKey *RoutingKey) (*RoutingKey, *RequestDetails, error) {
-	jsonRk, err := json.Marshal(routingKey)
+	jsonRk, err := json.Marshal(routingKeyRequest{RoutingKey: routingKey.RoutingKey, Targets: routingKey.PolicySlugs()})
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// Make the request
-	details, err := c.makePublicAPICall("POST", "v1/org/routing-keys", bytes.NewBuffer(jsonRk), nil)
+	details, err := c.makePublicAPICall("POST", routingKeyEndpoint, bytes.NewBuffer(jsonRk), nil)
 	if err != nil {
 		return nil, details, err
 	}
 
-	newKey, err := parseRoutingKeyResponse(details.ResponseBody)
+	newKey, err := parseRoutingKey(details.ResponseBody)
 	if err != nil {
 		return newKey, details, err
 	}
@@ -71,7 +163,7 @@ func (c Client) CreateRoutingKey(routingKey *RoutingKey) (*RoutingKey, *RequestD
 }
 
 // GetRoutingKey returns a specific routingkey within this victorops organization
-func (c Client) GetRoutingKey(keyname string) (*RoutingKeyResponse, *RequestDetails, error) {
+func (c Client) GetRoutingKey(keyname string) (*RoutingKey, *RequestDetails, error) {
 
 	rkList, details, err := c.GetAllRoutingKeys()
 	// Check for errors
@@ -89,9 +181,9 @@ func (c Client) GetRoutingKey(keyname string) (*RoutingKeyResponse, *RequestDeta
 }
 
 // GetAllRoutingKeys returns a list of all of the routing keys for an account
-func (c Client) GetAllRoutingKeys() (*RoutingKeyResponseList, *RequestDetails, error) {
+func (c Client) GetAllRoutingKeys() (*RoutingKeyList, *RequestDetails, error) {
 	// Make the request
-	details, err := c.makePublicAPICall("GET", "v1/org/routing-keys", bytes.NewBufferString("{}"), nil)
+	details, err := c.makePublicAPICall("GET", routingKeyEndpoint, bytes.NewBufferString("{}"), nil)
 	if err != nil {
 		return nil, details, err
 	}
@@ -103,3 +195,47 @@ func (c Client) GetAllRoutingKeys() (*RoutingKeyResponseList, *RequestDetails, e
 
 	return rkList, details, nil
 }
+
+// GetDefaultRoutingKey returns the routing key incidents are routed with when no other key matches
+func (c Client) GetDefaultRoutingKey() (*RoutingKey, *RequestDetails, error) {
+	rkList, details, err := c.GetAllRoutingKeys()
+	if err != nil {
+		return nil, details, err
+	}
+
+	for _, key := range rkList.RoutingKeys {
+		if key.IsDefault {
+			return &key, details, nil
+		}
+	}
+
+	return nil, details, nil
+}
+
+// UpdateRoutingKeyTargets replaces the escalation policies targeted by a routing key
+func (c Client) UpdateRoutingKeyTargets(keyname string, policySlugs []string) (*RoutingKey, *RequestDetails, error) {
+	jsonRk, err := json.Marshal(routingKeyRequest{Targets: policySlugs})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Make the request
+	details, err := c.makePublicAPICall("PUT", routingKeyEndpoint+"/"+url.PathEscape(keyname), bytes.NewBuffer(jsonRk), nil)
+	if err != nil {
+		return nil, details, err
+	}
+
+	updatedKey, err := parseRoutingKey(details.ResponseBody)
+	if err != nil {
+		return updatedKey, details, err
+	}
+
+	return updatedKey, details, nil
+}
+
+// DeleteRoutingKey deletes a routing key from the victorops organization
+func (c Client) DeleteRoutingKey(keyname string) (*RequestDetails, error) {
+	// Make the request
+	details, err := c.makePublicAPICall("DELETE", routingKeyEndpoint+"/"+url.PathEscape(keyname), bytes.NewBufferString("{}"), nil)
+	return details, err
+}
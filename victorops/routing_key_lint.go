@@ -0,0 +1,119 @@
+package victorops
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// RoutingKeyLintRule identifies the rule broken by a routing key
+type RoutingKeyLintRule string
+
+const (
+	// RoutingKeyMissingPolicy is reported when a routing key targets a policy that doesn't exist
+	RoutingKeyMissingPolicy RoutingKeyLintRule = "missing_policy"
+	// RoutingKeyNoTargets is reported when a routing key doesn't target any policy
+	RoutingKeyNoTargets RoutingKeyLintRule = "no_targets"
+	// RoutingKeyEmptyTeam is reported when a routing key targets a policy of a team without members
+	RoutingKeyEmptyTeam RoutingKeyLintRule = "empty_team"
+	// RoutingKeyNaming is reported when a routing key doesn't match the naming convention
+	RoutingKeyNaming RoutingKeyLintRule = "naming"
+)
+
+// RoutingKeyLintFinding is a single problem found with a routing key
+type RoutingKeyLintFinding struct {
+	RoutingKey string             `json:"routingKey"`
+	Rule       RoutingKeyLintRule `json:"rule"`
+	PolicySlug string             `json:"policySlug,omitempty"`
+	TeamSlug   string             `json:"teamSlug,omitempty"`
+	Message    string             `json:"message"`
+}
+
+func (f RoutingKeyLintFinding) String() string {
+	return fmt.Sprintf("%s: %s", f.RoutingKey, f.Message)
+}
+
+// RoutingKeyLintOptions controls the checks done by LintRoutingKeys
+type RoutingKeyLintOptions struct {
+	// NamePattern is the naming convention routing keys must match. Nil disables the check.
+	NamePattern *regexp.Regexp
+}
+
+// LintRoutingKeys checks routing keys against the escalation policies and teams of the org,
+// reporting keys without targets, targets that don't exist or belong to teams without
+// members and names not matching opts.NamePattern
+func LintRoutingKeys(routingKeys []RoutingKey, policies []EscalationPolicyListElement, teams []Team, opts RoutingKeyLintOptions) []RoutingKeyLintFinding {
+	policyTeams := map[string]string{}
+	for _, element := range policies {
+		policyTeams[element.Policy.Slug] = element.Team.Slug
+	}
+	teamMembers := map[string]int{}
+	for _, team := range teams {
+		teamMembers[team.Slug] = team.MemberCount
+	}
+
+	findings := []RoutingKeyLintFinding{}
+	for _, key := range routingKeys {
+		if opts.NamePattern != nil && !opts.NamePattern.MatchString(key.RoutingKey) {
+			findings = append(findings, RoutingKeyLintFinding{
+				RoutingKey: key.RoutingKey,
+				Rule:       RoutingKeyNaming,
+				Message:    fmt.Sprintf("name doesn't match %s", opts.NamePattern),
+			})
+		}
+
+		if len(key.Targets) == 0 {
+			findings = append(findings, RoutingKeyLintFinding{
+				RoutingKey: key.RoutingKey,
+				Rule:       RoutingKeyNoTargets,
+				Message:    "doesn't target any escalation policy",
+			})
+		}
+
+		for _, target := range key.Targets {
+			teamSlug, ok := policyTeams[target.PolicySlug]
+			if !ok {
+				findings = append(findings, RoutingKeyLintFinding{
+					RoutingKey: key.RoutingKey,
+					Rule:       RoutingKeyMissingPolicy,
+					PolicySlug: target.PolicySlug,
+					Message:    fmt.Sprintf("targets escalation policy %s which doesn't exist", target.PolicySlug),
+				})
+				continue
+			}
+
+			if members, ok := teamMembers[teamSlug]; ok && members == 0 {
+				findings = append(findings, RoutingKeyLintFinding{
+					RoutingKey: key.RoutingKey,
+					Rule:       RoutingKeyEmptyTeam,
+					PolicySlug: target.PolicySlug,
+					TeamSlug:   teamSlug,
+					Message:    fmt.Sprintf("targets escalation policy %s of team %s which has no members", target.PolicySlug, teamSlug),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(a, b int) bool { return findings[a].RoutingKey < findings[b].RoutingKey })
+	return findings
+}
+
+// LintAllRoutingKeys fetches the routing keys, escalation policies and teams of the org and lints them
+func (c Client) LintAllRoutingKeys(opts RoutingKeyLintOptions) ([]RoutingKeyLintFinding, *RequestDetails, error) {
+	routingKeys, details, err := c.GetAllRoutingKeys()
+	if err != nil {
+		return nil, details, err
+	}
+
+	policies, details, err := c.GetAllEscalationPolicies()
+	if err != nil {
+		return nil, details, err
+	}
+
+	teams, details, err := c.GetAllTeams()
+	if err != nil {
+		return nil, details, err
+	}
+
+	return LintRoutingKeys(routingKeys.RoutingKeys, policies.Policies, *teams, opts), details, nil
+}
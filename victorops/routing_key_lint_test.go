@@ -0,0 +1,60 @@
+package victorops
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestLintAllRoutingKeys(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/api-public/v1/org/routing-keys", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`
+		{
+			"routingKeys": [
+				{"routingKey": "team-infra-database", "targets": [{"policySlug": "pol-abcd"}]},
+				{"routingKey": "Legacy_Key", "targets": []},
+				{"routingKey": "team-infra-deleted", "targets": [{"policySlug": "pol-deleted"}]},
+				{"routingKey": "team-empty-alerts", "targets": [{"policySlug": "pol-efgh"}]}
+			]
+		}
+		`))
+	})
+	testMux.HandleFunc("/api-public/v1/policies", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`
+		{
+			"policies": [
+				{"policy": {"name": "High Severity", "slug": "pol-abcd"}, "team": {"name": "Infrastructure", "slug": "team-abcd"}},
+				{"policy": {"name": "Alerts", "slug": "pol-efgh"}, "team": {"name": "Empty", "slug": "team-efgh"}}
+			]
+		}
+		`))
+	})
+	testMux.HandleFunc("/api-public/v1/team", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`
+		[
+			{"name": "Infrastructure", "slug": "team-abcd", "memberCount": 3},
+			{"name": "Empty", "slug": "team-efgh", "memberCount": 0}
+		]
+		`))
+	})
+
+	findings, _, err := testClient.LintAllRoutingKeys(RoutingKeyLintOptions{NamePattern: regexp.MustCompile(`^team-[a-z]+-[a-z]+$`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []RoutingKeyLintFinding{
+		{RoutingKey: "Legacy_Key", Rule: RoutingKeyNaming, Message: "name doesn't match ^team-[a-z]+-[a-z]+$"},
+		{RoutingKey: "Legacy_Key", Rule: RoutingKeyNoTargets, Message: "doesn't target any escalation policy"},
+		{RoutingKey: "team-empty-alerts", Rule: RoutingKeyEmptyTeam, PolicySlug: "pol-efgh", TeamSlug: "team-efgh", Message: "targets escalation policy pol-efgh of team team-efgh which has no members"},
+		{RoutingKey: "team-infra-deleted", Rule: RoutingKeyMissingPolicy, PolicySlug: "pol-deleted", Message: "targets escalation policy pol-deleted which doesn't exist"},
+	}
+
+	if !reflect.DeepEqual(findings, want) {
+		t.Errorf("returned \n\n%#v want \n\n%#v", findings, want)
+	}
+}
@@ -0,0 +1,200 @@
+package victorops
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestGetDefaultRoutingKey(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/api-public/v1/org/routing-keys", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		w.Write([]byte(`
+		{
+			"routingKeys": [
+				{"routingKey": "database", "targets": [{"policySlug": "pol-abcd"}], "isDefault": false},
+				{"routingKey": "everything", "targets": [{"policySlug": "pol-efgh"}], "isDefault": true}
+			]
+		}
+		`))
+	})
+
+	resp, _, err := testClient.GetDefaultRoutingKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &RoutingKey{
+		RoutingKey: "everything",
+		Targets:    []RoutingKeyTarget{{PolicySlug: "pol-efgh"}},
+		IsDefault:  true,
+	}
+	if !reflect.DeepEqual(resp, want) {
+		t.Errorf("returned \n\n%#v want \n\n%#v", resp, want)
+	}
+}
+
+func TestUpdateRoutingKeyTargets(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/api-public/v1/org/routing-keys/database", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(body["targets"], []interface{}{"pol-abcd", "pol-efgh"}) {
+			t.Errorf("unexpected targets sent %v", body["targets"])
+		}
+
+		w.Write([]byte(`{"routingKey": "database", "targets": ["pol-abcd", "pol-efgh"]}`))
+	})
+
+	resp, _, err := testClient.UpdateRoutingKeyTargets("database", []string{"pol-abcd", "pol-efgh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := NewRoutingKey("database", []string{"pol-abcd", "pol-efgh"})
+	if !reflect.DeepEqual(resp, want) {
+		t.Errorf("returned \n\n%#v want \n\n%#v", resp, want)
+	}
+}
+
+func TestDeleteRoutingKey(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/api-public/v1/org/routing-keys/database", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "DELETE")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	details, err := testClient.DeleteRoutingKey("database")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details.StatusCode != http.StatusNoContent {
+		t.Errorf("unexpected status code %d", details.StatusCode)
+	}
+}
+
+func TestRoutingKeyWithSpace(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var paths []string
+	testMux.HandleFunc("/api-public/v1/org/routing-keys/", func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.EscapedPath())
+		if r.Method == "DELETE" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Write([]byte(`{"routingKey": "data base", "targets": ["pol-abcd"]}`))
+	})
+
+	_, _, err := testClient.UpdateRoutingKeyTargets("data base", []string{"pol-abcd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = testClient.DeleteRoutingKey("data base")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"PUT /api-public/v1/org/routing-keys/data%20base",
+		"DELETE /api-public/v1/org/routing-keys/data%20base",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("requested %v, want %v", paths, want)
+	}
+}
+
+func TestRoutingKeyRoundTrip(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/api-public/v1/org/routing-keys", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(`
+			{
+				"routingKeys": [
+					{
+						"routingKey": "database",
+						"targets": [
+							{"policyName": "Moderate Severity", "policySlug": "pol-tq09wTVkG7BzuMY0", "_teamUrl": "/api-public/v1/team/team-Iei67wjVsD14Pe4O"},
+							{"policyName": "High Severity", "policySlug": "pol-abcd", "_teamUrl": "/api-public/v1/team/team-abcd"}
+						],
+						"isDefault": false
+					}
+				]
+			}
+			`))
+		case "POST":
+			// Create requests and responses hold the targets as policy slugs
+			var body map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			want := map[string]interface{}{"routingKey": "database", "targets": []interface{}{"pol-tq09wTVkG7BzuMY0", "pol-abcd"}}
+			if !reflect.DeepEqual(body, want) {
+				t.Errorf("sent \n\n%#v want \n\n%#v", body, want)
+			}
+			w.Write([]byte(`{"routingKey": "database", "targets": ["pol-tq09wTVkG7BzuMY0", "pol-abcd"]}`))
+		default:
+			t.Errorf("unexpected method %s", r.Method)
+		}
+	})
+
+	rk, _, err := testClient.GetRoutingKey("database")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := RoutingKeyTarget{
+		PolicySlug: "pol-tq09wTVkG7BzuMY0",
+		PolicyName: "Moderate Severity",
+		TeamURL:    "/api-public/v1/team/team-Iei67wjVsD14Pe4O",
+	}
+	if !reflect.DeepEqual(rk.Targets[0], want) {
+		t.Errorf("returned \n\n%#v want \n\n%#v", rk.Targets[0], want)
+	}
+	if rk.Targets[0].TeamSlug() != "team-Iei67wjVsD14Pe4O" {
+		t.Errorf("unexpected team slug %q", rk.Targets[0].TeamSlug())
+	}
+
+	// The team slug is derived, so it isn't written with the target
+	marshalled, err := json.Marshal(rk.Targets[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantJSON := `{"policySlug":"pol-tq09wTVkG7BzuMY0","policyName":"Moderate Severity","_teamUrl":"/api-public/v1/team/team-Iei67wjVsD14Pe4O"}`
+	if string(marshalled) != wantJSON {
+		t.Errorf("returned \n\n%s want \n\n%s", marshalled, wantJSON)
+	}
+
+	created, _, err := testClient.CreateRoutingKey(rk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created.SameTargets(*rk) {
+		t.Errorf("expected %v and %v to have the same targets", created.PolicySlugs(), rk.PolicySlugs())
+	}
+
+	desired := NewRoutingKey("database", []string{"pol-abcd", "pol-tq09wTVkG7BzuMY0"})
+	if !rk.SameTargets(*desired) {
+		t.Errorf("expected %v and %v to have the same targets", rk.PolicySlugs(), desired.PolicySlugs())
+	}
+	desired = NewRoutingKey("database", []string{"pol-abcd"})
+	if rk.SameTargets(*desired) {
+		t.Errorf("expected %v and %v to have different targets", rk.PolicySlugs(), desired.PolicySlugs())
+	}
+}
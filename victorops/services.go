@@ -0,0 +1,107 @@
+package victorops
+
+import "time"
+
+// UserService manages the users of the org and their personal paging policies
+type UserService interface {
+	CreateUser(user *User) (*User, *RequestDetails, error)
+	GetUser(username string) (*User, *RequestDetails, error)
+	UpdateUser(user *User) (*User, *RequestDetails, error)
+	DeleteUser(username string, replacementUser string) (*RequestDetails, error)
+	GetAllUsers() (*UserList, *RequestDetails, error)
+	GetAllUserV2() (*UserListV2, *RequestDetails, error)
+	GetUserByEmail(email string) (*UserListV2, *RequestDetails, error)
+	GetUserTeams(username string) (*[]Team, *RequestDetails, error)
+	GetUserDefaultEmailContactID(username string) (float64, *RequestDetails, error)
+
+	GetPagingPolicy(username string) (*PagingPolicy, *RequestDetails, error)
+	CreatePagingPolicyStep(username string, step *PagingPolicyStep) (*PagingPolicyStep, *RequestDetails, error)
+	GetPagingPolicyStep(username string, stepIndex int) (*PagingPolicyStep, *RequestDetails, error)
+	UpdatePagingPolicyStep(username string, step *PagingPolicyStep) (*PagingPolicyStep, *RequestDetails, error)
+	CreatePagingPolicyRule(username string, stepIndex int, rule *PagingPolicyRule) (*PagingPolicyRule, *RequestDetails, error)
+	UpdatePagingPolicyRule(username string, stepIndex int, rule *PagingPolicyRule) (*PagingPolicyRule, *RequestDetails, error)
+	DeletePagingPolicyRule(username string, stepIndex int, ruleIndex int) (*RequestDetails, error)
+	GetPagingPolicyTypes(kind string) (*PagingPolicyTypes, *RequestDetails, error)
+}
+
+// ContactService manages the contact methods of users
+type ContactService interface {
+	CreateContact(username string, contact *Contact) (*Contact, *RequestDetails, error)
+	GetContact(username string, contactExtID string, contactType ContactType) (*Contact, *RequestDetails, error)
+	GetAllContacts(username string) (*AllContactResponse, *RequestDetails, error)
+	DeleteContact(username string, contactExtID string, contactType ContactType) (*RequestDetails, error)
+	GetContactByID(username string, id int, contactType ContactType) (*Contact, *RequestDetails, error)
+	GetPagingPolicyRuleContact(username string, rule PagingPolicyRule) (*Contact, *RequestDetails, error)
+}
+
+// TeamService manages teams, their members and their admins
+type TeamService interface {
+	CreateTeam(team *Team) (*Team, *RequestDetails, error)
+	GetTeam(teamID string) (*Team, *RequestDetails, error)
+	GetAllTeams() (*[]Team, *RequestDetails, error)
+	UpdateTeam(team *Team) (*Team, *RequestDetails, error)
+	DeleteTeam(teamID string) (*RequestDetails, error)
+	GetTeamMembers(teamID string) (*TeamMembers, *RequestDetails, error)
+	AddTeamMember(teamID string, username string) (*RequestDetails, error)
+	RemoveTeamMember(teamID string, username string, replacement string) (*RequestDetails, error)
+	IsTeamMember(teamID string, username string) (bool, *RequestDetails, error)
+	GetTeamAdmins(teamID string) (*TeamAdmins, *RequestDetails, error)
+	AddTeamAdmin(teamID string, username string) (*RequestDetails, error)
+	RemoveTeamAdmin(teamID string, username string) (*RequestDetails, error)
+	LoadMembershipIndex() (*MembershipIndex, *RequestDetails, error)
+}
+
+// IncidentService reads, acknowledges and resolves incidents
+type IncidentService interface {
+	GetIncident(incidentID int) (*Incident, *RequestDetails, error)
+	GetIncidents() (*IncidentResponse, *RequestDetails, error)
+	GetIncidentHistory(start time.Time, end time.Time) (*IncidentResponse, *RequestDetails, error)
+	AckIncidents(username string, incidentNumbers []int, message string) (*IncidentUpdateResponse, *RequestDetails, error)
+	ResolveIncidents(username string, incidentNumbers []int, message string) (*IncidentUpdateResponse, *RequestDetails, error)
+}
+
+// OnCallService reads on-call schedules and history, and takes on-call shifts
+type OnCallService interface {
+	GetApiTeamSchedule(teamSlug string, daysForward int, daysSkip int, step int) (*ApiTeamSchedule, *RequestDetails, error)
+	GetUserOnCallSchedule(userName string, daysForward int, daysSkip int, step int) (*ApiUserSchedule, *RequestDetails, error)
+	TakeOnCallForTeam(teamSlug string, req *TakeRequest) (*TakeResponse, *RequestDetails, error)
+	TakeOnCallForPolicy(policySlug string, req *TakeRequest) (*TakeResponse, *RequestDetails, error)
+	GetTeamOnCallLog(teamSlug string, start time.Time, end time.Time) (*TeamOnCallLog, *RequestDetails, error)
+	AnalyzeTeamOnCallSchedules(teamSlugs []string, daysForward int, opts OnCallAnalysisOptions) (*OnCallAnalysis, *RequestDetails, error)
+	GetOnCallLoadReport(teamSlugs []string, opts OnCallLoadReportOptions) (*OnCallLoadReport, *RequestDetails, error)
+}
+
+// PolicyService manages escalation policies
+type PolicyService interface {
+	CreateEscalationPolicy(escalationPolicy *EscalationPolicy) (*EscalationPolicy, *RequestDetails, error)
+	GetAllEscalationPolicies() (*EscalationPolicyList, *RequestDetails, error)
+	GetTeamEscalationPolicies(teamSlug string) (*TeamEscalationPolicyList, *RequestDetails, error)
+	GetEscalationPolicy(escalationPolicyID string) (*EscalationPolicy, *RequestDetails, error)
+	UpdateEscalationPolicy(escalationPolicy *EscalationPolicy) (*EscalationPolicy, *RequestDetails, error)
+	DeleteEscalationPolicy(escalationPolicyID string) (*RequestDetails, error)
+	GetAllEscalationPoliciesDetailed() ([]EscalationPolicy, *RequestDetails, error)
+	GetEscalationPolicyGraph() (*EscalationPolicyGraph, *RequestDetails, error)
+}
+
+// RoutingKeyService manages routing keys and the policies they route incidents to
+type RoutingKeyService interface {
+	CreateRoutingKey(routingKey *RoutingKey) (*RoutingKey, *RequestDetails, error)
+	GetRoutingKey(keyname string) (*RoutingKey, *RequestDetails, error)
+	GetAllRoutingKeys() (*RoutingKeyList, *RequestDetails, error)
+	GetDefaultRoutingKey() (*RoutingKey, *RequestDetails, error)
+	UpdateRoutingKeyTargets(keyname string, policySlugs []string) (*RoutingKey, *RequestDetails, error)
+	DeleteRoutingKey(keyname string) (*RequestDetails, error)
+	LintAllRoutingKeys(opts RoutingKeyLintOptions) ([]RoutingKeyLintFinding, *RequestDetails, error)
+	GetRoutingGraph() (*RoutingGraph, *RequestDetails, error)
+}
+
+// Client implements every service
+var (
+	_ UserService       = (*Client)(nil)
+	_ ContactService    = (*Client)(nil)
+	_ TeamService       = (*Client)(nil)
+	_ IncidentService   = (*Client)(nil)
+	_ OnCallService     = (*Client)(nil)
+	_ PolicyService     = (*Client)(nil)
+	_ RoutingKeyService = (*Client)(nil)
+)
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"net/url"
-	"strings"
 )
 
 // Team is a struct to hold the data for a victorops Team
@@ -21,7 +20,7 @@ type TeamMembers struct {
 	Members []User `json:"members,omitempty"`
 }
 
-// User is a user in the VictorOps org.
+// Admin is an administrator of a team in the VictorOps org.
 type Admin struct {
 	Username  string `json:"username,omitempty"`
 	FirstName string `json:"firstName,omitempty"`
@@ -29,11 +28,45 @@ type Admin struct {
 	SelfUrl   string `json:"_selfUrl,omitempty"`
 }
 
+// UserURL returns the API link to the admin's user, built from the username when the
+// response didn't include it
+func (a Admin) UserURL() string {
+	if a.SelfUrl != "" {
+		return a.SelfUrl
+	}
+	return "/api-public/" + userV1Endpoint + "/" + url.QueryEscape(a.Username)
+}
+
 // TeamAdmins contains administrators for a team
 type TeamAdmins struct {
 	TeamAdmins []Admin `json:"admin,omitempty"`
 }
 
+// UnmarshalJSON parses the admins of a team from either the "teamAdmins" key returned by
+// the API or the legacy "admin" key
+func (t *TeamAdmins) UnmarshalJSON(data []byte) error {
+	var parsed struct {
+		Admin      []Admin `json:"admin"`
+		TeamAdmins []Admin `json:"teamAdmins"`
+	}
+	err := json.Unmarshal(data, &parsed)
+	if err != nil {
+		return err
+	}
+
+	t.TeamAdmins = append(parsed.Admin, parsed.TeamAdmins...)
+	return nil
+}
+
+// Usernames returns the usernames of the admins
+func (t TeamAdmins) Usernames() []string {
+	usernames := []string{}
+	for _, admin := range t.TeamAdmins {
+		usernames = append(usernames, admin.Username)
+	}
+	return usernames
+}
+
 func parseTeamResponse(response string) (*Team, error) {
 	// Parse the response and return the user object
 	var team Team
@@ -187,16 +220,19 @@ func (c Client) RemoveTeamMember(teamID string, username string, replacement str
 	return details, err
 }
 
-// IsTeamMember Returns wether or not a user is in a specific victorops team
-// TODO: Maybe we should do this using the v1/user/{username}/teams endpoint instead
+// IsTeamMember Returns wether or not a user is in a specific victorops team. It makes a
+// single request for the teams of the user (see GetUserTeams) instead of listing the
+// members of the team, and compares teamID with their slugs. Anything else that
+// identifies the team, such as its name, is not a match, even where the team endpoints
+// accept it.
 func (c Client) IsTeamMember(teamID string, username string) (bool, *RequestDetails, error) {
-	members, details, err := c.GetTeamMembers(teamID)
+	teams, details, err := c.GetUserTeams(username)
 	if err != nil {
 		return false, details, err
 	}
 
-	for _, member := range members.Members {
-		if strings.EqualFold(member.Username, username) {
+	for _, team := range *teams {
+		if team.Slug == teamID {
 			return true, details, nil
 		}
 	}
@@ -220,3 +256,24 @@ func (c Client) GetTeamAdmins(teamID string) (*TeamAdmins, *RequestDetails, erro
 
 	return teamAdmins, details, err
 }
+
+type teamAdminRequest struct {
+	Username string `json:"username"`
+}
+
+// AddTeamAdmin promotes a member of a victorops team to team admin
+func (c Client) AddTeamAdmin(teamID string, username string) (*RequestDetails, error) {
+	jsonAdmin, err := json.Marshal(teamAdminRequest{Username: username})
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := c.makePublicAPICall("POST", "v1/team/"+teamID+"/admins", bytes.NewBuffer(jsonAdmin), nil)
+	return details, err
+}
+
+// RemoveTeamAdmin demotes a team admin of a victorops team back to a regular member
+func (c Client) RemoveTeamAdmin(teamID string, username string) (*RequestDetails, error) {
+	details, err := c.makePublicAPICall("DELETE", "v1/team/"+teamID+"/admins/"+url.QueryEscape(username), bytes.NewBufferString("{}"), nil)
+	return details, err
+}
@@ -1,6 +1,7 @@
 package victorops
 
 import (
+	"encoding/json"
 	"net/http"
 	"reflect"
 	"testing"
@@ -111,3 +112,100 @@ func TestCreateTeamInvalidResponse(t *testing.T) {
 		t.Errorf("expected CreateUser to error out on an invalid response from the server")
 	}
 }
+
+func TestGetTeamAdmins(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/api-public/v1/team/team-abcd/admins", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		w.Write([]byte(`
+		{
+			"teamAdmins": [
+				{"username": "janedoe", "firstName": "Jane", "lastName": "Doe", "_selfUrl": "/api-public/v1/user/janedoe"},
+				{"username": "johndoe", "firstName": "John", "lastName": "Doe"}
+			]
+		}
+		`))
+	})
+
+	resp, _, err := testClient.GetTeamAdmins("team-abcd")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &TeamAdmins{
+		TeamAdmins: []Admin{
+			{Username: "janedoe", FirstName: "Jane", LastName: "Doe", SelfUrl: "/api-public/v1/user/janedoe"},
+			{Username: "johndoe", FirstName: "John", LastName: "Doe"},
+		},
+	}
+	if !reflect.DeepEqual(resp, want) {
+		t.Errorf("returned \n\n%#v want \n\n%#v", resp, want)
+	}
+
+	if !reflect.DeepEqual(resp.Usernames(), []string{"janedoe", "johndoe"}) {
+		t.Errorf("unexpected usernames %v", resp.Usernames())
+	}
+	if resp.TeamAdmins[1].UserURL() != "/api-public/v1/user/johndoe" {
+		t.Errorf("unexpected user url %s", resp.TeamAdmins[1].UserURL())
+	}
+}
+
+func TestAddAndRemoveTeamAdmin(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/api-public/v1/team/team-abcd/admins", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+
+		var req map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		if req["username"] != "janedoe" {
+			t.Errorf("unexpected request %v", req)
+		}
+	})
+	testMux.HandleFunc("/api-public/v1/team/team-abcd/admins/janedoe", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "DELETE")
+	})
+
+	if _, err := testClient.AddTeamAdmin("team-abcd", "janedoe"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testClient.RemoveTeamAdmin("team-abcd", "janedoe"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIsTeamMember(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var requests []string
+	testMux.HandleFunc("/api-public/", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.URL.Path != "/api-public/v1/user/janedoe/teams" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"teams": [{"name": "Infrastructure", "slug": "team-abcd"}]}`))
+	})
+
+	// Teams are matched by slug only, not by any other identifier such as the name
+	for teamID, want := range map[string]bool{"team-abcd": true, "team-efgh": false, "Infrastructure": false} {
+		isMember, _, err := testClient.IsTeamMember(teamID, "janedoe")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isMember != want {
+			t.Errorf("IsTeamMember(%q) returned %t, want %t", teamID, isMember, want)
+		}
+	}
+
+	want := []string{"GET /api-public/v1/user/janedoe/teams", "GET /api-public/v1/user/janedoe/teams", "GET /api-public/v1/user/janedoe/teams"}
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("requested %v, want one request per call %v", requests, want)
+	}
+}
@@ -163,6 +163,27 @@ func (c Client) UpdateUser(user *User) (*User, *RequestDetails, error) {
 	return newUser, details, nil
 }
 
+type userTeamsResponse struct {
+	Teams []Team `json:"teams"`
+}
+
+// GetUserTeams returns the teams a user is a member of
+func (c Client) GetUserTeams(username string) (*[]Team, *RequestDetails, error) {
+	// Make the request
+	details, err := c.makePublicAPICall("GET", userV1Endpoint+"/"+url.QueryEscape(username)+"/teams", bytes.NewBufferString("{}"), nil)
+	if err != nil {
+		return nil, details, err
+	}
+
+	var utr userTeamsResponse
+	err = json.Unmarshal([]byte(details.ResponseBody), &utr)
+	if err != nil {
+		return nil, details, err
+	}
+
+	return &utr.Teams, details, nil
+}
+
 type emailsResponse struct {
 	ContactMethods []map[string]interface{} `json:"contactMethods"`
 }
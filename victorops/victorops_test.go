@@ -43,11 +43,11 @@ func testMethod(t *testing.T, r *http.Request, want string) {
 }
 
 func TestConfigurableClient(t *testing.T) {
-	args := ClientArgs{timeoutSeconds: 30}
+	httpClient := http.Client{Timeout: 30 * time.Second}
 	testMux = http.NewServeMux()
 	testServer = httptest.NewServer(testMux)
 
-	testConfigurableClient := NewConfigurableClient("apiID", "apiKey", testServer.URL, args)
+	testConfigurableClient := NewConfigurableClient("apiID", "apiKey", testServer.URL, httpClient)
 	log.Printf("Client instantiated: %s", testConfigurableClient.publicBaseURL)
 	if testConfigurableClient.GetHTTPClient() == nil {
 		t.Errorf("http client is nil")
@@ -55,7 +55,7 @@ func TestConfigurableClient(t *testing.T) {
 }
 
 func TestConfigurableClientTimeout(t *testing.T) {
-	args := ClientArgs{timeoutSeconds: 1}
+	httpClient := http.Client{Timeout: 1 * time.Second}
 	testMux = http.NewServeMux()
 	testServer = httptest.NewServer(testMux)
 
@@ -63,7 +63,7 @@ func TestConfigurableClientTimeout(t *testing.T) {
 		time.Sleep(2 * time.Second)
 	})
 
-	testConfigurableClient := NewConfigurableClient("apiID", "apiKey", testServer.URL, args)
+	testConfigurableClient := NewConfigurableClient("apiID", "apiKey", testServer.URL, httpClient)
 	log.Printf("Client instantiated: %s", testConfigurableClient.publicBaseURL)
 	_, _, err := testConfigurableClient.GetAllUsers()
 
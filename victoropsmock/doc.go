@@ -0,0 +1,41 @@
+// Package victoropsmock provides mocks of the service interfaces of the victorops
+// package, for unit testing code that depends on them without an API server. Set the func
+// field of each method a test expects to be called:
+//
+//	users := &victoropsmock.UserService{
+//		GetUserFunc: func(username string) (*victorops.User, *victorops.RequestDetails, error) {
+//			return &victorops.User{Username: username}, &victorops.RequestDetails{StatusCode: 200}, nil
+//		},
+//	}
+//
+// Every mock records its calls, which Calls returns in order.
+package victoropsmock
+
+//go:generate go run ../internal/mockgen -source ../victorops/services.go -out mocks.go
+
+import "sync"
+
+// Call is a call made to a mock
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// recorder records the calls made to a mock
+type recorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+func (r *recorder) record(method string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls made to the mock, in the order they were made
+func (r *recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
@@ -0,0 +1,712 @@
+// Code generated by internal/mockgen from victorops/services.go. DO NOT EDIT.
+
+package victoropsmock
+
+import (
+	"time"
+
+	"github.com/victorops/go-victorops/victorops"
+)
+
+// UserService is a mock of victorops.UserService. Calling a method whose func field is
+// nil panics.
+type UserService struct {
+	recorder
+
+	CreateUserFunc                   func(user *victorops.User) (*victorops.User, *victorops.RequestDetails, error)
+	GetUserFunc                      func(username string) (*victorops.User, *victorops.RequestDetails, error)
+	UpdateUserFunc                   func(user *victorops.User) (*victorops.User, *victorops.RequestDetails, error)
+	DeleteUserFunc                   func(username string, replacementUser string) (*victorops.RequestDetails, error)
+	GetAllUsersFunc                  func() (*victorops.UserList, *victorops.RequestDetails, error)
+	GetAllUserV2Func                 func() (*victorops.UserListV2, *victorops.RequestDetails, error)
+	GetUserByEmailFunc               func(email string) (*victorops.UserListV2, *victorops.RequestDetails, error)
+	GetUserTeamsFunc                 func(username string) (*[]victorops.Team, *victorops.RequestDetails, error)
+	GetUserDefaultEmailContactIDFunc func(username string) (float64, *victorops.RequestDetails, error)
+	GetPagingPolicyFunc              func(username string) (*victorops.PagingPolicy, *victorops.RequestDetails, error)
+	CreatePagingPolicyStepFunc       func(username string, step *victorops.PagingPolicyStep) (*victorops.PagingPolicyStep, *victorops.RequestDetails, error)
+	GetPagingPolicyStepFunc          func(username string, stepIndex int) (*victorops.PagingPolicyStep, *victorops.RequestDetails, error)
+	UpdatePagingPolicyStepFunc       func(username string, step *victorops.PagingPolicyStep) (*victorops.PagingPolicyStep, *victorops.RequestDetails, error)
+	CreatePagingPolicyRuleFunc       func(username string, stepIndex int, rule *victorops.PagingPolicyRule) (*victorops.PagingPolicyRule, *victorops.RequestDetails, error)
+	UpdatePagingPolicyRuleFunc       func(username string, stepIndex int, rule *victorops.PagingPolicyRule) (*victorops.PagingPolicyRule, *victorops.RequestDetails, error)
+	DeletePagingPolicyRuleFunc       func(username string, stepIndex int, ruleIndex int) (*victorops.RequestDetails, error)
+	GetPagingPolicyTypesFunc         func(kind string) (*victorops.PagingPolicyTypes, *victorops.RequestDetails, error)
+}
+
+var _ victorops.UserService = (*UserService)(nil)
+
+// CreateUser records the call and calls CreateUserFunc
+func (m *UserService) CreateUser(user *victorops.User) (*victorops.User, *victorops.RequestDetails, error) {
+	m.record("CreateUser", user)
+	if m.CreateUserFunc == nil {
+		panic("victoropsmock: unexpected call to UserService.CreateUser")
+	}
+	return m.CreateUserFunc(user)
+}
+
+// GetUser records the call and calls GetUserFunc
+func (m *UserService) GetUser(username string) (*victorops.User, *victorops.RequestDetails, error) {
+	m.record("GetUser", username)
+	if m.GetUserFunc == nil {
+		panic("victoropsmock: unexpected call to UserService.GetUser")
+	}
+	return m.GetUserFunc(username)
+}
+
+// UpdateUser records the call and calls UpdateUserFunc
+func (m *UserService) UpdateUser(user *victorops.User) (*victorops.User, *victorops.RequestDetails, error) {
+	m.record("UpdateUser", user)
+	if m.UpdateUserFunc == nil {
+		panic("victoropsmock: unexpected call to UserService.UpdateUser")
+	}
+	return m.UpdateUserFunc(user)
+}
+
+// DeleteUser records the call and calls DeleteUserFunc
+func (m *UserService) DeleteUser(username string, replacementUser string) (*victorops.RequestDetails, error) {
+	m.record("DeleteUser", username, replacementUser)
+	if m.DeleteUserFunc == nil {
+		panic("victoropsmock: unexpected call to UserService.DeleteUser")
+	}
+	return m.DeleteUserFunc(username, replacementUser)
+}
+
+// GetAllUsers records the call and calls GetAllUsersFunc
+func (m *UserService) GetAllUsers() (*victorops.UserList, *victorops.RequestDetails, error) {
+	m.record("GetAllUsers")
+	if m.GetAllUsersFunc == nil {
+		panic("victoropsmock: unexpected call to UserService.GetAllUsers")
+	}
+	return m.GetAllUsersFunc()
+}
+
+// GetAllUserV2 records the call and calls GetAllUserV2Func
+func (m *UserService) GetAllUserV2() (*victorops.UserListV2, *victorops.RequestDetails, error) {
+	m.record("GetAllUserV2")
+	if m.GetAllUserV2Func == nil {
+		panic("victoropsmock: unexpected call to UserService.GetAllUserV2")
+	}
+	return m.GetAllUserV2Func()
+}
+
+// GetUserByEmail records the call and calls GetUserByEmailFunc
+func (m *UserService) GetUserByEmail(email string) (*victorops.UserListV2, *victorops.RequestDetails, error) {
+	m.record("GetUserByEmail", email)
+	if m.GetUserByEmailFunc == nil {
+		panic("victoropsmock: unexpected call to UserService.GetUserByEmail")
+	}
+	return m.GetUserByEmailFunc(email)
+}
+
+// GetUserTeams records the call and calls GetUserTeamsFunc
+func (m *UserService) GetUserTeams(username string) (*[]victorops.Team, *victorops.RequestDetails, error) {
+	m.record("GetUserTeams", username)
+	if m.GetUserTeamsFunc == nil {
+		panic("victoropsmock: unexpected call to UserService.GetUserTeams")
+	}
+	return m.GetUserTeamsFunc(username)
+}
+
+// GetUserDefaultEmailContactID records the call and calls GetUserDefaultEmailContactIDFunc
+func (m *UserService) GetUserDefaultEmailContactID(username string) (float64, *victorops.RequestDetails, error) {
+	m.record("GetUserDefaultEmailContactID", username)
+	if m.GetUserDefaultEmailContactIDFunc == nil {
+		panic("victoropsmock: unexpected call to UserService.GetUserDefaultEmailContactID")
+	}
+	return m.GetUserDefaultEmailContactIDFunc(username)
+}
+
+// GetPagingPolicy records the call and calls GetPagingPolicyFunc
+func (m *UserService) GetPagingPolicy(username string) (*victorops.PagingPolicy, *victorops.RequestDetails, error) {
+	m.record("GetPagingPolicy", username)
+	if m.GetPagingPolicyFunc == nil {
+		panic("victoropsmock: unexpected call to UserService.GetPagingPolicy")
+	}
+	return m.GetPagingPolicyFunc(username)
+}
+
+// CreatePagingPolicyStep records the call and calls CreatePagingPolicyStepFunc
+func (m *UserService) CreatePagingPolicyStep(username string, step *victorops.PagingPolicyStep) (*victorops.PagingPolicyStep, *victorops.RequestDetails, error) {
+	m.record("CreatePagingPolicyStep", username, step)
+	if m.CreatePagingPolicyStepFunc == nil {
+		panic("victoropsmock: unexpected call to UserService.CreatePagingPolicyStep")
+	}
+	return m.CreatePagingPolicyStepFunc(username, step)
+}
+
+// GetPagingPolicyStep records the call and calls GetPagingPolicyStepFunc
+func (m *UserService) GetPagingPolicyStep(username string, stepIndex int) (*victorops.PagingPolicyStep, *victorops.RequestDetails, error) {
+	m.record("GetPagingPolicyStep", username, stepIndex)
+	if m.GetPagingPolicyStepFunc == nil {
+		panic("victoropsmock: unexpected call to UserService.GetPagingPolicyStep")
+	}
+	return m.GetPagingPolicyStepFunc(username, stepIndex)
+}
+
+// UpdatePagingPolicyStep records the call and calls UpdatePagingPolicyStepFunc
+func (m *UserService) UpdatePagingPolicyStep(username string, step *victorops.PagingPolicyStep) (*victorops.PagingPolicyStep, *victorops.RequestDetails, error) {
+	m.record("UpdatePagingPolicyStep", username, step)
+	if m.UpdatePagingPolicyStepFunc == nil {
+		panic("victoropsmock: unexpected call to UserService.UpdatePagingPolicyStep")
+	}
+	return m.UpdatePagingPolicyStepFunc(username, step)
+}
+
+// CreatePagingPolicyRule records the call and calls CreatePagingPolicyRuleFunc
+func (m *UserService) CreatePagingPolicyRule(username string, stepIndex int, rule *victorops.PagingPolicyRule) (*victorops.PagingPolicyRule, *victorops.RequestDetails, error) {
+	m.record("CreatePagingPolicyRule", username, stepIndex, rule)
+	if m.CreatePagingPolicyRuleFunc == nil {
+		panic("victoropsmock: unexpected call to UserService.CreatePagingPolicyRule")
+	}
+	return m.CreatePagingPolicyRuleFunc(username, stepIndex, rule)
+}
+
+// UpdatePagingPolicyRule records the call and calls UpdatePagingPolicyRuleFunc
+func (m *UserService) UpdatePagingPolicyRule(username string, stepIndex int, rule *victorops.PagingPolicyRule) (*victorops.PagingPolicyRule, *victorops.RequestDetails, error) {
+	m.record("UpdatePagingPolicyRule", username, stepIndex, rule)
+	if m.UpdatePagingPolicyRuleFunc == nil {
+		panic("victoropsmock: unexpected call to UserService.UpdatePagingPolicyRule")
+	}
+	return m.UpdatePagingPolicyRuleFunc(username, stepIndex, rule)
+}
+
+// DeletePagingPolicyRule records the call and calls DeletePagingPolicyRuleFunc
+func (m *UserService) DeletePagingPolicyRule(username string, stepIndex int, ruleIndex int) (*victorops.RequestDetails, error) {
+	m.record("DeletePagingPolicyRule", username, stepIndex, ruleIndex)
+	if m.DeletePagingPolicyRuleFunc == nil {
+		panic("victoropsmock: unexpected call to UserService.DeletePagingPolicyRule")
+	}
+	return m.DeletePagingPolicyRuleFunc(username, stepIndex, ruleIndex)
+}
+
+// GetPagingPolicyTypes records the call and calls GetPagingPolicyTypesFunc
+func (m *UserService) GetPagingPolicyTypes(kind string) (*victorops.PagingPolicyTypes, *victorops.RequestDetails, error) {
+	m.record("GetPagingPolicyTypes", kind)
+	if m.GetPagingPolicyTypesFunc == nil {
+		panic("victoropsmock: unexpected call to UserService.GetPagingPolicyTypes")
+	}
+	return m.GetPagingPolicyTypesFunc(kind)
+}
+
+// ContactService is a mock of victorops.ContactService. Calling a method whose func field is
+// nil panics.
+type ContactService struct {
+	recorder
+
+	CreateContactFunc              func(username string, contact *victorops.Contact) (*victorops.Contact, *victorops.RequestDetails, error)
+	GetContactFunc                 func(username string, contactExtID string, contactType victorops.ContactType) (*victorops.Contact, *victorops.RequestDetails, error)
+	GetAllContactsFunc             func(username string) (*victorops.AllContactResponse, *victorops.RequestDetails, error)
+	DeleteContactFunc              func(username string, contactExtID string, contactType victorops.ContactType) (*victorops.RequestDetails, error)
+	GetContactByIDFunc             func(username string, id int, contactType victorops.ContactType) (*victorops.Contact, *victorops.RequestDetails, error)
+	GetPagingPolicyRuleContactFunc func(username string, rule victorops.PagingPolicyRule) (*victorops.Contact, *victorops.RequestDetails, error)
+}
+
+var _ victorops.ContactService = (*ContactService)(nil)
+
+// CreateContact records the call and calls CreateContactFunc
+func (m *ContactService) CreateContact(username string, contact *victorops.Contact) (*victorops.Contact, *victorops.RequestDetails, error) {
+	m.record("CreateContact", username, contact)
+	if m.CreateContactFunc == nil {
+		panic("victoropsmock: unexpected call to ContactService.CreateContact")
+	}
+	return m.CreateContactFunc(username, contact)
+}
+
+// GetContact records the call and calls GetContactFunc
+func (m *ContactService) GetContact(username string, contactExtID string, contactType victorops.ContactType) (*victorops.Contact, *victorops.RequestDetails, error) {
+	m.record("GetContact", username, contactExtID, contactType)
+	if m.GetContactFunc == nil {
+		panic("victoropsmock: unexpected call to ContactService.GetContact")
+	}
+	return m.GetContactFunc(username, contactExtID, contactType)
+}
+
+// GetAllContacts records the call and calls GetAllContactsFunc
+func (m *ContactService) GetAllContacts(username string) (*victorops.AllContactResponse, *victorops.RequestDetails, error) {
+	m.record("GetAllContacts", username)
+	if m.GetAllContactsFunc == nil {
+		panic("victoropsmock: unexpected call to ContactService.GetAllContacts")
+	}
+	return m.GetAllContactsFunc(username)
+}
+
+// DeleteContact records the call and calls DeleteContactFunc
+func (m *ContactService) DeleteContact(username string, contactExtID string, contactType victorops.ContactType) (*victorops.RequestDetails, error) {
+	m.record("DeleteContact", username, contactExtID, contactType)
+	if m.DeleteContactFunc == nil {
+		panic("victoropsmock: unexpected call to ContactService.DeleteContact")
+	}
+	return m.DeleteContactFunc(username, contactExtID, contactType)
+}
+
+// GetContactByID records the call and calls GetContactByIDFunc
+func (m *ContactService) GetContactByID(username string, id int, contactType victorops.ContactType) (*victorops.Contact, *victorops.RequestDetails, error) {
+	m.record("GetContactByID", username, id, contactType)
+	if m.GetContactByIDFunc == nil {
+		panic("victoropsmock: unexpected call to ContactService.GetContactByID")
+	}
+	return m.GetContactByIDFunc(username, id, contactType)
+}
+
+// GetPagingPolicyRuleContact records the call and calls GetPagingPolicyRuleContactFunc
+func (m *ContactService) GetPagingPolicyRuleContact(username string, rule victorops.PagingPolicyRule) (*victorops.Contact, *victorops.RequestDetails, error) {
+	m.record("GetPagingPolicyRuleContact", username, rule)
+	if m.GetPagingPolicyRuleContactFunc == nil {
+		panic("victoropsmock: unexpected call to ContactService.GetPagingPolicyRuleContact")
+	}
+	return m.GetPagingPolicyRuleContactFunc(username, rule)
+}
+
+// TeamService is a mock of victorops.TeamService. Calling a method whose func field is
+// nil panics.
+type TeamService struct {
+	recorder
+
+	CreateTeamFunc          func(team *victorops.Team) (*victorops.Team, *victorops.RequestDetails, error)
+	GetTeamFunc             func(teamID string) (*victorops.Team, *victorops.RequestDetails, error)
+	GetAllTeamsFunc         func() (*[]victorops.Team, *victorops.RequestDetails, error)
+	UpdateTeamFunc          func(team *victorops.Team) (*victorops.Team, *victorops.RequestDetails, error)
+	DeleteTeamFunc          func(teamID string) (*victorops.RequestDetails, error)
+	GetTeamMembersFunc      func(teamID string) (*victorops.TeamMembers, *victorops.RequestDetails, error)
+	AddTeamMemberFunc       func(teamID string, username string) (*victorops.RequestDetails, error)
+	RemoveTeamMemberFunc    func(teamID string, username string, replacement string) (*victorops.RequestDetails, error)
+	IsTeamMemberFunc        func(teamID string, username string) (bool, *victorops.RequestDetails, error)
+	GetTeamAdminsFunc       func(teamID string) (*victorops.TeamAdmins, *victorops.RequestDetails, error)
+	AddTeamAdminFunc        func(teamID string, username string) (*victorops.RequestDetails, error)
+	RemoveTeamAdminFunc     func(teamID string, username string) (*victorops.RequestDetails, error)
+	LoadMembershipIndexFunc func() (*victorops.MembershipIndex, *victorops.RequestDetails, error)
+}
+
+var _ victorops.TeamService = (*TeamService)(nil)
+
+// CreateTeam records the call and calls CreateTeamFunc
+func (m *TeamService) CreateTeam(team *victorops.Team) (*victorops.Team, *victorops.RequestDetails, error) {
+	m.record("CreateTeam", team)
+	if m.CreateTeamFunc == nil {
+		panic("victoropsmock: unexpected call to TeamService.CreateTeam")
+	}
+	return m.CreateTeamFunc(team)
+}
+
+// GetTeam records the call and calls GetTeamFunc
+func (m *TeamService) GetTeam(teamID string) (*victorops.Team, *victorops.RequestDetails, error) {
+	m.record("GetTeam", teamID)
+	if m.GetTeamFunc == nil {
+		panic("victoropsmock: unexpected call to TeamService.GetTeam")
+	}
+	return m.GetTeamFunc(teamID)
+}
+
+// GetAllTeams records the call and calls GetAllTeamsFunc
+func (m *TeamService) GetAllTeams() (*[]victorops.Team, *victorops.RequestDetails, error) {
+	m.record("GetAllTeams")
+	if m.GetAllTeamsFunc == nil {
+		panic("victoropsmock: unexpected call to TeamService.GetAllTeams")
+	}
+	return m.GetAllTeamsFunc()
+}
+
+// UpdateTeam records the call and calls UpdateTeamFunc
+func (m *TeamService) UpdateTeam(team *victorops.Team) (*victorops.Team, *victorops.RequestDetails, error) {
+	m.record("UpdateTeam", team)
+	if m.UpdateTeamFunc == nil {
+		panic("victoropsmock: unexpected call to TeamService.UpdateTeam")
+	}
+	return m.UpdateTeamFunc(team)
+}
+
+// DeleteTeam records the call and calls DeleteTeamFunc
+func (m *TeamService) DeleteTeam(teamID string) (*victorops.RequestDetails, error) {
+	m.record("DeleteTeam", teamID)
+	if m.DeleteTeamFunc == nil {
+		panic("victoropsmock: unexpected call to TeamService.DeleteTeam")
+	}
+	return m.DeleteTeamFunc(teamID)
+}
+
+// GetTeamMembers records the call and calls GetTeamMembersFunc
+func (m *TeamService) GetTeamMembers(teamID string) (*victorops.TeamMembers, *victorops.RequestDetails, error) {
+	m.record("GetTeamMembers", teamID)
+	if m.GetTeamMembersFunc == nil {
+		panic("victoropsmock: unexpected call to TeamService.GetTeamMembers")
+	}
+	return m.GetTeamMembersFunc(teamID)
+}
+
+// AddTeamMember records the call and calls AddTeamMemberFunc
+func (m *TeamService) AddTeamMember(teamID string, username string) (*victorops.RequestDetails, error) {
+	m.record("AddTeamMember", teamID, username)
+	if m.AddTeamMemberFunc == nil {
+		panic("victoropsmock: unexpected call to TeamService.AddTeamMember")
+	}
+	return m.AddTeamMemberFunc(teamID, username)
+}
+
+// RemoveTeamMember records the call and calls RemoveTeamMemberFunc
+func (m *TeamService) RemoveTeamMember(teamID string, username string, replacement string) (*victorops.RequestDetails, error) {
+	m.record("RemoveTeamMember", teamID, username, replacement)
+	if m.RemoveTeamMemberFunc == nil {
+		panic("victoropsmock: unexpected call to TeamService.RemoveTeamMember")
+	}
+	return m.RemoveTeamMemberFunc(teamID, username, replacement)
+}
+
+// IsTeamMember records the call and calls IsTeamMemberFunc
+func (m *TeamService) IsTeamMember(teamID string, username string) (bool, *victorops.RequestDetails, error) {
+	m.record("IsTeamMember", teamID, username)
+	if m.IsTeamMemberFunc == nil {
+		panic("victoropsmock: unexpected call to TeamService.IsTeamMember")
+	}
+	return m.IsTeamMemberFunc(teamID, username)
+}
+
+// GetTeamAdmins records the call and calls GetTeamAdminsFunc
+func (m *TeamService) GetTeamAdmins(teamID string) (*victorops.TeamAdmins, *victorops.RequestDetails, error) {
+	m.record("GetTeamAdmins", teamID)
+	if m.GetTeamAdminsFunc == nil {
+		panic("victoropsmock: unexpected call to TeamService.GetTeamAdmins")
+	}
+	return m.GetTeamAdminsFunc(teamID)
+}
+
+// AddTeamAdmin records the call and calls AddTeamAdminFunc
+func (m *TeamService) AddTeamAdmin(teamID string, username string) (*victorops.RequestDetails, error) {
+	m.record("AddTeamAdmin", teamID, username)
+	if m.AddTeamAdminFunc == nil {
+		panic("victoropsmock: unexpected call to TeamService.AddTeamAdmin")
+	}
+	return m.AddTeamAdminFunc(teamID, username)
+}
+
+// RemoveTeamAdmin records the call and calls RemoveTeamAdminFunc
+func (m *TeamService) RemoveTeamAdmin(teamID string, username string) (*victorops.RequestDetails, error) {
+	m.record("RemoveTeamAdmin", teamID, username)
+	if m.RemoveTeamAdminFunc == nil {
+		panic("victoropsmock: unexpected call to TeamService.RemoveTeamAdmin")
+	}
+	return m.RemoveTeamAdminFunc(teamID, username)
+}
+
+// LoadMembershipIndex records the call and calls LoadMembershipIndexFunc
+func (m *TeamService) LoadMembershipIndex() (*victorops.MembershipIndex, *victorops.RequestDetails, error) {
+	m.record("LoadMembershipIndex")
+	if m.LoadMembershipIndexFunc == nil {
+		panic("victoropsmock: unexpected call to TeamService.LoadMembershipIndex")
+	}
+	return m.LoadMembershipIndexFunc()
+}
+
+// IncidentService is a mock of victorops.IncidentService. Calling a method whose func field is
+// nil panics.
+type IncidentService struct {
+	recorder
+
+	GetIncidentFunc        func(incidentID int) (*victorops.Incident, *victorops.RequestDetails, error)
+	GetIncidentsFunc       func() (*victorops.IncidentResponse, *victorops.RequestDetails, error)
+	GetIncidentHistoryFunc func(start time.Time, end time.Time) (*victorops.IncidentResponse, *victorops.RequestDetails, error)
+	AckIncidentsFunc       func(username string, incidentNumbers []int, message string) (*victorops.IncidentUpdateResponse, *victorops.RequestDetails, error)
+	ResolveIncidentsFunc   func(username string, incidentNumbers []int, message string) (*victorops.IncidentUpdateResponse, *victorops.RequestDetails, error)
+}
+
+var _ victorops.IncidentService = (*IncidentService)(nil)
+
+// GetIncident records the call and calls GetIncidentFunc
+func (m *IncidentService) GetIncident(incidentID int) (*victorops.Incident, *victorops.RequestDetails, error) {
+	m.record("GetIncident", incidentID)
+	if m.GetIncidentFunc == nil {
+		panic("victoropsmock: unexpected call to IncidentService.GetIncident")
+	}
+	return m.GetIncidentFunc(incidentID)
+}
+
+// GetIncidents records the call and calls GetIncidentsFunc
+func (m *IncidentService) GetIncidents() (*victorops.IncidentResponse, *victorops.RequestDetails, error) {
+	m.record("GetIncidents")
+	if m.GetIncidentsFunc == nil {
+		panic("victoropsmock: unexpected call to IncidentService.GetIncidents")
+	}
+	return m.GetIncidentsFunc()
+}
+
+// GetIncidentHistory records the call and calls GetIncidentHistoryFunc
+func (m *IncidentService) GetIncidentHistory(start time.Time, end time.Time) (*victorops.IncidentResponse, *victorops.RequestDetails, error) {
+	m.record("GetIncidentHistory", start, end)
+	if m.GetIncidentHistoryFunc == nil {
+		panic("victoropsmock: unexpected call to IncidentService.GetIncidentHistory")
+	}
+	return m.GetIncidentHistoryFunc(start, end)
+}
+
+// AckIncidents records the call and calls AckIncidentsFunc
+func (m *IncidentService) AckIncidents(username string, incidentNumbers []int, message string) (*victorops.IncidentUpdateResponse, *victorops.RequestDetails, error) {
+	m.record("AckIncidents", username, incidentNumbers, message)
+	if m.AckIncidentsFunc == nil {
+		panic("victoropsmock: unexpected call to IncidentService.AckIncidents")
+	}
+	return m.AckIncidentsFunc(username, incidentNumbers, message)
+}
+
+// ResolveIncidents records the call and calls ResolveIncidentsFunc
+func (m *IncidentService) ResolveIncidents(username string, incidentNumbers []int, message string) (*victorops.IncidentUpdateResponse, *victorops.RequestDetails, error) {
+	m.record("ResolveIncidents", username, incidentNumbers, message)
+	if m.ResolveIncidentsFunc == nil {
+		panic("victoropsmock: unexpected call to IncidentService.ResolveIncidents")
+	}
+	return m.ResolveIncidentsFunc(username, incidentNumbers, message)
+}
+
+// OnCallService is a mock of victorops.OnCallService. Calling a method whose func field is
+// nil panics.
+type OnCallService struct {
+	recorder
+
+	GetApiTeamScheduleFunc         func(teamSlug string, daysForward int, daysSkip int, step int) (*victorops.ApiTeamSchedule, *victorops.RequestDetails, error)
+	GetUserOnCallScheduleFunc      func(userName string, daysForward int, daysSkip int, step int) (*victorops.ApiUserSchedule, *victorops.RequestDetails, error)
+	TakeOnCallForTeamFunc          func(teamSlug string, req *victorops.TakeRequest) (*victorops.TakeResponse, *victorops.RequestDetails, error)
+	TakeOnCallForPolicyFunc        func(policySlug string, req *victorops.TakeRequest) (*victorops.TakeResponse, *victorops.RequestDetails, error)
+	GetTeamOnCallLogFunc           func(teamSlug string, start time.Time, end time.Time) (*victorops.TeamOnCallLog, *victorops.RequestDetails, error)
+	AnalyzeTeamOnCallSchedulesFunc func(teamSlugs []string, daysForward int, opts victorops.OnCallAnalysisOptions) (*victorops.OnCallAnalysis, *victorops.RequestDetails, error)
+	GetOnCallLoadReportFunc        func(teamSlugs []string, opts victorops.OnCallLoadReportOptions) (*victorops.OnCallLoadReport, *victorops.RequestDetails, error)
+}
+
+var _ victorops.OnCallService = (*OnCallService)(nil)
+
+// GetApiTeamSchedule records the call and calls GetApiTeamScheduleFunc
+func (m *OnCallService) GetApiTeamSchedule(teamSlug string, daysForward int, daysSkip int, step int) (*victorops.ApiTeamSchedule, *victorops.RequestDetails, error) {
+	m.record("GetApiTeamSchedule", teamSlug, daysForward, daysSkip, step)
+	if m.GetApiTeamScheduleFunc == nil {
+		panic("victoropsmock: unexpected call to OnCallService.GetApiTeamSchedule")
+	}
+	return m.GetApiTeamScheduleFunc(teamSlug, daysForward, daysSkip, step)
+}
+
+// GetUserOnCallSchedule records the call and calls GetUserOnCallScheduleFunc
+func (m *OnCallService) GetUserOnCallSchedule(userName string, daysForward int, daysSkip int, step int) (*victorops.ApiUserSchedule, *victorops.RequestDetails, error) {
+	m.record("GetUserOnCallSchedule", userName, daysForward, daysSkip, step)
+	if m.GetUserOnCallScheduleFunc == nil {
+		panic("victoropsmock: unexpected call to OnCallService.GetUserOnCallSchedule")
+	}
+	return m.GetUserOnCallScheduleFunc(userName, daysForward, daysSkip, step)
+}
+
+// TakeOnCallForTeam records the call and calls TakeOnCallForTeamFunc
+func (m *OnCallService) TakeOnCallForTeam(teamSlug string, req *victorops.TakeRequest) (*victorops.TakeResponse, *victorops.RequestDetails, error) {
+	m.record("TakeOnCallForTeam", teamSlug, req)
+	if m.TakeOnCallForTeamFunc == nil {
+		panic("victoropsmock: unexpected call to OnCallService.TakeOnCallForTeam")
+	}
+	return m.TakeOnCallForTeamFunc(teamSlug, req)
+}
+
+// TakeOnCallForPolicy records the call and calls TakeOnCallForPolicyFunc
+func (m *OnCallService) TakeOnCallForPolicy(policySlug string, req *victorops.TakeRequest) (*victorops.TakeResponse, *victorops.RequestDetails, error) {
+	m.record("TakeOnCallForPolicy", policySlug, req)
+	if m.TakeOnCallForPolicyFunc == nil {
+		panic("victoropsmock: unexpected call to OnCallService.TakeOnCallForPolicy")
+	}
+	return m.TakeOnCallForPolicyFunc(policySlug, req)
+}
+
+// GetTeamOnCallLog records the call and calls GetTeamOnCallLogFunc
+func (m *OnCallService) GetTeamOnCallLog(teamSlug string, start time.Time, end time.Time) (*victorops.TeamOnCallLog, *victorops.RequestDetails, error) {
+	m.record("GetTeamOnCallLog", teamSlug, start, end)
+	if m.GetTeamOnCallLogFunc == nil {
+		panic("victoropsmock: unexpected call to OnCallService.GetTeamOnCallLog")
+	}
+	return m.GetTeamOnCallLogFunc(teamSlug, start, end)
+}
+
+// AnalyzeTeamOnCallSchedules records the call and calls AnalyzeTeamOnCallSchedulesFunc
+func (m *OnCallService) AnalyzeTeamOnCallSchedules(teamSlugs []string, daysForward int, opts victorops.OnCallAnalysisOptions) (*victorops.OnCallAnalysis, *victorops.RequestDetails, error) {
+	m.record("AnalyzeTeamOnCallSchedules", teamSlugs, daysForward, opts)
+	if m.AnalyzeTeamOnCallSchedulesFunc == nil {
+		panic("victoropsmock: unexpected call to OnCallService.AnalyzeTeamOnCallSchedules")
+	}
+	return m.AnalyzeTeamOnCallSchedulesFunc(teamSlugs, daysForward, opts)
+}
+
+// GetOnCallLoadReport records the call and calls GetOnCallLoadReportFunc
+func (m *OnCallService) GetOnCallLoadReport(teamSlugs []string, opts victorops.OnCallLoadReportOptions) (*victorops.OnCallLoadReport, *victorops.RequestDetails, error) {
+	m.record("GetOnCallLoadReport", teamSlugs, opts)
+	if m.GetOnCallLoadReportFunc == nil {
+		panic("victoropsmock: unexpected call to OnCallService.GetOnCallLoadReport")
+	}
+	return m.GetOnCallLoadReportFunc(teamSlugs, opts)
+}
+
+// PolicyService is a mock of victorops.PolicyService. Calling a method whose func field is
+// nil panics.
+type PolicyService struct {
+	recorder
+
+	CreateEscalationPolicyFunc           func(escalationPolicy *victorops.EscalationPolicy) (*victorops.EscalationPolicy, *victorops.RequestDetails, error)
+	GetAllEscalationPoliciesFunc         func() (*victorops.EscalationPolicyList, *victorops.RequestDetails, error)
+	GetTeamEscalationPoliciesFunc        func(teamSlug string) (*victorops.TeamEscalationPolicyList, *victorops.RequestDetails, error)
+	GetEscalationPolicyFunc              func(escalationPolicyID string) (*victorops.EscalationPolicy, *victorops.RequestDetails, error)
+	UpdateEscalationPolicyFunc           func(escalationPolicy *victorops.EscalationPolicy) (*victorops.EscalationPolicy, *victorops.RequestDetails, error)
+	DeleteEscalationPolicyFunc           func(escalationPolicyID string) (*victorops.RequestDetails, error)
+	GetAllEscalationPoliciesDetailedFunc func() ([]victorops.EscalationPolicy, *victorops.RequestDetails, error)
+	GetEscalationPolicyGraphFunc         func() (*victorops.EscalationPolicyGraph, *victorops.RequestDetails, error)
+}
+
+var _ victorops.PolicyService = (*PolicyService)(nil)
+
+// CreateEscalationPolicy records the call and calls CreateEscalationPolicyFunc
+func (m *PolicyService) CreateEscalationPolicy(escalationPolicy *victorops.EscalationPolicy) (*victorops.EscalationPolicy, *victorops.RequestDetails, error) {
+	m.record("CreateEscalationPolicy", escalationPolicy)
+	if m.CreateEscalationPolicyFunc == nil {
+		panic("victoropsmock: unexpected call to PolicyService.CreateEscalationPolicy")
+	}
+	return m.CreateEscalationPolicyFunc(escalationPolicy)
+}
+
+// GetAllEscalationPolicies records the call and calls GetAllEscalationPoliciesFunc
+func (m *PolicyService) GetAllEscalationPolicies() (*victorops.EscalationPolicyList, *victorops.RequestDetails, error) {
+	m.record("GetAllEscalationPolicies")
+	if m.GetAllEscalationPoliciesFunc == nil {
+		panic("victoropsmock: unexpected call to PolicyService.GetAllEscalationPolicies")
+	}
+	return m.GetAllEscalationPoliciesFunc()
+}
+
+// GetTeamEscalationPolicies records the call and calls GetTeamEscalationPoliciesFunc
+func (m *PolicyService) GetTeamEscalationPolicies(teamSlug string) (*victorops.TeamEscalationPolicyList, *victorops.RequestDetails, error) {
+	m.record("GetTeamEscalationPolicies", teamSlug)
+	if m.GetTeamEscalationPoliciesFunc == nil {
+		panic("victoropsmock: unexpected call to PolicyService.GetTeamEscalationPolicies")
+	}
+	return m.GetTeamEscalationPoliciesFunc(teamSlug)
+}
+
+// GetEscalationPolicy records the call and calls GetEscalationPolicyFunc
+func (m *PolicyService) GetEscalationPolicy(escalationPolicyID string) (*victorops.EscalationPolicy, *victorops.RequestDetails, error) {
+	m.record("GetEscalationPolicy", escalationPolicyID)
+	if m.GetEscalationPolicyFunc == nil {
+		panic("victoropsmock: unexpected call to PolicyService.GetEscalationPolicy")
+	}
+	return m.GetEscalationPolicyFunc(escalationPolicyID)
+}
+
+// UpdateEscalationPolicy records the call and calls UpdateEscalationPolicyFunc
+func (m *PolicyService) UpdateEscalationPolicy(escalationPolicy *victorops.EscalationPolicy) (*victorops.EscalationPolicy, *victorops.RequestDetails, error) {
+	m.record("UpdateEscalationPolicy", escalationPolicy)
+	if m.UpdateEscalationPolicyFunc == nil {
+		panic("victoropsmock: unexpected call to PolicyService.UpdateEscalationPolicy")
+	}
+	return m.UpdateEscalationPolicyFunc(escalationPolicy)
+}
+
+// DeleteEscalationPolicy records the call and calls DeleteEscalationPolicyFunc
+func (m *PolicyService) DeleteEscalationPolicy(escalationPolicyID string) (*victorops.RequestDetails, error) {
+	m.record("DeleteEscalationPolicy", escalationPolicyID)
+	if m.DeleteEscalationPolicyFunc == nil {
+		panic("victoropsmock: unexpected call to PolicyService.DeleteEscalationPolicy")
+	}
+	return m.DeleteEscalationPolicyFunc(escalationPolicyID)
+}
+
+// GetAllEscalationPoliciesDetailed records the call and calls GetAllEscalationPoliciesDetailedFunc
+func (m *PolicyService) GetAllEscalationPoliciesDetailed() ([]victorops.EscalationPolicy, *victorops.RequestDetails, error) {
+	m.record("GetAllEscalationPoliciesDetailed")
+	if m.GetAllEscalationPoliciesDetailedFunc == nil {
+		panic("victoropsmock: unexpected call to PolicyService.GetAllEscalationPoliciesDetailed")
+	}
+	return m.GetAllEscalationPoliciesDetailedFunc()
+}
+
+// GetEscalationPolicyGraph records the call and calls GetEscalationPolicyGraphFunc
+func (m *PolicyService) GetEscalationPolicyGraph() (*victorops.EscalationPolicyGraph, *victorops.RequestDetails, error) {
+	m.record("GetEscalationPolicyGraph")
+	if m.GetEscalationPolicyGraphFunc == nil {
+		panic("victoropsmock: unexpected call to PolicyService.GetEscalationPolicyGraph")
+	}
+	return m.GetEscalationPolicyGraphFunc()
+}
+
+// RoutingKeyService is a mock of victorops.RoutingKeyService. Calling a method whose func field is
+// nil panics.
+type RoutingKeyService struct {
+	recorder
+
+	CreateRoutingKeyFunc        func(routingKey *victorops.RoutingKey) (*victorops.RoutingKey, *victorops.RequestDetails, error)
+	GetRoutingKeyFunc           func(keyname string) (*victorops.RoutingKey, *victorops.RequestDetails, error)
+	GetAllRoutingKeysFunc       func() (*victorops.RoutingKeyList, *victorops.RequestDetails, error)
+	GetDefaultRoutingKeyFunc    func() (*victorops.RoutingKey, *victorops.RequestDetails, error)
+	UpdateRoutingKeyTargetsFunc func(keyname string, policySlugs []string) (*victorops.RoutingKey, *victorops.RequestDetails, error)
+	DeleteRoutingKeyFunc        func(keyname string) (*victorops.RequestDetails, error)
+	LintAllRoutingKeysFunc      func(opts victorops.RoutingKeyLintOptions) ([]victorops.RoutingKeyLintFinding, *victorops.RequestDetails, error)
+	GetRoutingGraphFunc         func() (*victorops.RoutingGraph, *victorops.RequestDetails, error)
+}
+
+var _ victorops.RoutingKeyService = (*RoutingKeyService)(nil)
+
+// CreateRoutingKey records the call and calls CreateRoutingKeyFunc
+func (m *RoutingKeyService) CreateRoutingKey(routingKey *victorops.RoutingKey) (*victorops.RoutingKey, *victorops.RequestDetails, error) {
+	m.record("CreateRoutingKey", routingKey)
+	if m.CreateRoutingKeyFunc == nil {
+		panic("victoropsmock: unexpected call to RoutingKeyService.CreateRoutingKey")
+	}
+	return m.CreateRoutingKeyFunc(routingKey)
+}
+
+// GetRoutingKey records the call and calls GetRoutingKeyFunc
+func (m *RoutingKeyService) GetRoutingKey(keyname string) (*victorops.RoutingKey, *victorops.RequestDetails, error) {
+	m.record("GetRoutingKey", keyname)
+	if m.GetRoutingKeyFunc == nil {
+		panic("victoropsmock: unexpected call to RoutingKeyService.GetRoutingKey")
+	}
+	return m.GetRoutingKeyFunc(keyname)
+}
+
+// GetAllRoutingKeys records the call and calls GetAllRoutingKeysFunc
+func (m *RoutingKeyService) GetAllRoutingKeys() (*victorops.RoutingKeyList, *victorops.RequestDetails, error) {
+	m.record("GetAllRoutingKeys")
+	if m.GetAllRoutingKeysFunc == nil {
+		panic("victoropsmock: unexpected call to RoutingKeyService.GetAllRoutingKeys")
+	}
+	return m.GetAllRoutingKeysFunc()
+}
+
+// GetDefaultRoutingKey records the call and calls GetDefaultRoutingKeyFunc
+func (m *RoutingKeyService) GetDefaultRoutingKey() (*victorops.RoutingKey, *victorops.RequestDetails, error) {
+	m.record("GetDefaultRoutingKey")
+	if m.GetDefaultRoutingKeyFunc == nil {
+		panic("victoropsmock: unexpected call to RoutingKeyService.GetDefaultRoutingKey")
+	}
+	return m.GetDefaultRoutingKeyFunc()
+}
+
+// UpdateRoutingKeyTargets records the call and calls UpdateRoutingKeyTargetsFunc
+func (m *RoutingKeyService) UpdateRoutingKeyTargets(keyname string, policySlugs []string) (*victorops.RoutingKey, *victorops.RequestDetails, error) {
+	m.record("UpdateRoutingKeyTargets", keyname, policySlugs)
+	if m.UpdateRoutingKeyTargetsFunc == nil {
+		panic("victoropsmock: unexpected call to RoutingKeyService.UpdateRoutingKeyTargets")
+	}
+	return m.UpdateRoutingKeyTargetsFunc(keyname, policySlugs)
+}
+
+// DeleteRoutingKey records the call and calls DeleteRoutingKeyFunc
+func (m *RoutingKeyService) DeleteRoutingKey(keyname string) (*victorops.RequestDetails, error) {
+	m.record("DeleteRoutingKey", keyname)
+	if m.DeleteRoutingKeyFunc == nil {
+		panic("victoropsmock: unexpected call to RoutingKeyService.DeleteRoutingKey")
+	}
+	return m.DeleteRoutingKeyFunc(keyname)
+}
+
+// LintAllRoutingKeys records the call and calls LintAllRoutingKeysFunc
+func (m *RoutingKeyService) LintAllRoutingKeys(opts victorops.RoutingKeyLintOptions) ([]victorops.RoutingKeyLintFinding, *victorops.RequestDetails, error) {
+	m.record("LintAllRoutingKeys", opts)
+	if m.LintAllRoutingKeysFunc == nil {
+		panic("victoropsmock: unexpected call to RoutingKeyService.LintAllRoutingKeys")
+	}
+	return m.LintAllRoutingKeysFunc(opts)
+}
+
+// GetRoutingGraph records the call and calls GetRoutingGraphFunc
+func (m *RoutingKeyService) GetRoutingGraph() (*victorops.RoutingGraph, *victorops.RequestDetails, error) {
+	m.record("GetRoutingGraph")
+	if m.GetRoutingGraphFunc == nil {
+		panic("victoropsmock: unexpected call to RoutingKeyService.GetRoutingGraph")
+	}
+	return m.GetRoutingGraphFunc()
+}
@@ -0,0 +1,63 @@
+package victoropsmock
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/victorops/go-victorops/victorops"
+)
+
+// ackAll acknowledges every unacknowledged incident, the way a service using the
+// interfaces would
+func ackAll(incidents victorops.IncidentService, username string) error {
+	response, _, err := incidents.GetIncidents()
+	if err != nil {
+		return err
+	}
+	numbers := []int{}
+	for i, incident := range response.Incidents {
+		if incident.CurrentPhase == "UNACKED" {
+			numbers = append(numbers, i+1)
+		}
+	}
+	_, _, err = incidents.AckIncidents(username, numbers, "acked")
+	return err
+}
+
+func TestIncidentService(t *testing.T) {
+	mock := &IncidentService{
+		GetIncidentsFunc: func() (*victorops.IncidentResponse, *victorops.RequestDetails, error) {
+			return &victorops.IncidentResponse{Incidents: []victorops.Incident{
+				{IncidentNumber: "1", CurrentPhase: "ACKED"},
+				{IncidentNumber: "2", CurrentPhase: "UNACKED"},
+			}}, &victorops.RequestDetails{StatusCode: 200}, nil
+		},
+		AckIncidentsFunc: func(username string, incidentNumbers []int, message string) (*victorops.IncidentUpdateResponse, *victorops.RequestDetails, error) {
+			return nil, nil, errors.New("ack failed")
+		},
+	}
+
+	err := ackAll(mock, "jdoe")
+	if err == nil || err.Error() != "ack failed" {
+		t.Errorf("ackAll() returned %v, want the error of AckIncidents", err)
+	}
+
+	want := []Call{
+		{Method: "GetIncidents"},
+		{Method: "AckIncidents", Args: []interface{}{"jdoe", []int{2}, "acked"}},
+	}
+	if got := mock.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Calls() returned %+v, want %+v", got, want)
+	}
+}
+
+func TestUnexpectedCall(t *testing.T) {
+	defer func() {
+		if r := recover(); r != "victoropsmock: unexpected call to TeamService.DeleteTeam" {
+			t.Errorf("recovered %v", r)
+		}
+	}()
+	var teams victorops.TeamService = &TeamService{}
+	teams.DeleteTeam("team-1")
+}
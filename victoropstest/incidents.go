@@ -0,0 +1,181 @@
+package victoropstest
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/victorops/go-victorops/victorops"
+)
+
+// The phases of an incident
+const (
+	PhaseUnacked  = "UNACKED"
+	PhaseAcked    = "ACKED"
+	PhaseResolved = "RESOLVED"
+)
+
+// AddIncident adds an incident to the org and returns it. The incident is numbered if it
+// has no number, unacknowledged if it has no phase and started now if it has no start time.
+func (s *Server) AddIncident(incident victorops.Incident) victorops.Incident {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if incident.IncidentNumber == "" {
+		number := 1
+		for existing := range s.incidents {
+			if n, err := strconv.Atoi(existing); err == nil && n >= number {
+				number = n + 1
+			}
+		}
+		incident.IncidentNumber = strconv.Itoa(number)
+	}
+	if incident.CurrentPhase == "" {
+		incident.CurrentPhase = PhaseUnacked
+	}
+	if incident.StartTime.IsZero() {
+		incident.StartTime = s.Now().UTC()
+	}
+	s.incidents[incident.IncidentNumber] = &incident
+	return incident
+}
+
+// SetSchedule sets the on-call schedule returned for a team. Teams without one have an
+// empty schedule for each of their escalation policies. The daysForward, daysSkip and step
+// parameters of schedule requests are ignored.
+func (s *Server) SetSchedule(teamSlug string, schedules ...victorops.ApiEscalationPolicySchedule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedules[teamSlug] = schedules
+}
+
+func (s *Server) listIncidents(r *request) (int, interface{}) {
+	response := victorops.IncidentResponse{Incidents: []victorops.Incident{}}
+	for _, incident := range s.incidents {
+		response.Incidents = append(response.Incidents, *incident)
+	}
+	sort.Slice(response.Incidents, func(i, j int) bool {
+		a, _ := strconv.Atoi(response.Incidents[i].IncidentNumber)
+		b, _ := strconv.Atoi(response.Incidents[j].IncidentNumber)
+		return a < b
+	})
+	return http.StatusOK, response
+}
+
+func (s *Server) getIncident(r *request) (int, interface{}) {
+	incident, found := s.incidents[r.params[0]]
+	if !found {
+		return errorf(http.StatusNotFound, "Incident %s not found", r.params[0])
+	}
+	return http.StatusOK, incident
+}
+
+func (s *Server) ackIncidents(r *request) (int, interface{}) {
+	return s.updateIncidents(r, PhaseAcked)
+}
+
+func (s *Server) resolveIncidents(r *request) (int, interface{}) {
+	return s.updateIncidents(r, PhaseResolved)
+}
+
+// updateIncidents moves incidents to phase, recording the transition. Incidents that are
+// missing or already past the phase are reported as not accepted.
+func (s *Server) updateIncidents(r *request, phase string) (int, interface{}) {
+	var body victorops.IncidentUpdateRequest
+	err := r.decode(&body)
+	if err != nil {
+		return errorf(http.StatusBadRequest, "Invalid request: %v", err)
+	}
+	if _, found := s.users[body.UserName]; !found {
+		return errorf(http.StatusBadRequest, "User %s not found", body.UserName)
+	}
+
+	response := victorops.IncidentUpdateResponse{Results: []victorops.IncidentUpdateResult{}}
+	for _, number := range body.IncidentNumbers {
+		result := victorops.IncidentUpdateResult{IncidentNumber: number}
+		incident, found := s.incidents[number]
+		switch {
+		case !found:
+			result.Message = "Incident not found"
+		case incident.CurrentPhase == phase || incident.CurrentPhase == PhaseResolved:
+			result.EntityID = incident.EntityID
+			result.Message = "Incident is already " + incident.CurrentPhase
+		default:
+			incident.CurrentPhase = phase
+			incident.Transitions = append(incident.Transitions, victorops.Transition{
+				Name:     phase,
+				At:       s.Now().UTC(),
+				Message:  body.Message,
+				By:       body.UserName,
+				Manually: true,
+			})
+			result.EntityID = incident.EntityID
+			result.CmdAccepted = true
+			result.Message = "Incident " + phase
+		}
+		response.Results = append(response.Results, result)
+	}
+	return http.StatusOK, response
+}
+
+// teamSchedule returns the on-call schedule of a team
+func (s *Server) teamSchedule(t *team) victorops.ApiTeamSchedule {
+	schedule := victorops.ApiTeamSchedule{Team: victorops.ApiTeam{Name: t.Name, Slug: t.Slug}}
+	if schedules, found := s.schedules[t.Slug]; found {
+		schedule.Schedules = append(schedule.Schedules, schedules...)
+		return schedule
+	}
+	for _, policy := range s.sortedPolicies() {
+		if policy.TeamID == t.Slug {
+			schedule.Schedules = append(schedule.Schedules, victorops.ApiEscalationPolicySchedule{
+				Policy: victorops.ApiEscalationPolicy{Name: policy.Name, Slug: policy.ID},
+			})
+		}
+	}
+	return schedule
+}
+
+func (s *Server) getTeamSchedule(r *request) (int, interface{}) {
+	t, found := s.team(r)
+	if !found {
+		return errorf(http.StatusNotFound, "Team %s not found", r.params[0])
+	}
+	return http.StatusOK, s.teamSchedule(t)
+}
+
+// onCall returns true if username is on call in the schedule, or takes over a shift in it
+func onCall(schedule victorops.ApiEscalationPolicySchedule, username string) bool {
+	for _, entry := range schedule.Schedule {
+		if entry.OnCallUser.Username == username || entry.OverrideOnCallUser.Username == username {
+			return true
+		}
+		for _, roll := range entry.Rolls {
+			if roll.OnCallUser.Username == username {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *Server) getUserSchedule(r *request) (int, interface{}) {
+	username := r.params[0]
+	if _, found := s.users[username]; !found {
+		return errorf(http.StatusNotFound, "User %s not found", username)
+	}
+
+	response := victorops.ApiUserSchedule{}
+	for _, t := range s.sortedTeams() {
+		teamSchedule := s.teamSchedule(t)
+		userSchedule := victorops.ApiTeamSchedule{Team: teamSchedule.Team}
+		for _, schedule := range teamSchedule.Schedules {
+			if onCall(schedule, username) {
+				userSchedule.Schedules = append(userSchedule.Schedules, schedule)
+			}
+		}
+		if len(userSchedule.Schedules) > 0 {
+			response.Schedules = append(response.Schedules, userSchedule)
+		}
+	}
+	return http.StatusOK, response
+}
@@ -0,0 +1,242 @@
+package victoropstest
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/victorops/go-victorops/victorops"
+)
+
+type routingKey struct {
+	targets   []string
+	isDefault bool
+}
+
+// SetDefaultRoutingKey creates the routing key incidents are routed with when no other
+// key matches, targeting the given policies. The default key can't be deleted.
+func (s *Server) SetDefaultRoutingKey(key string, policySlugs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rk := range s.routingKeys {
+		rk.isDefault = false
+	}
+	s.routingKeys[key] = &routingKey{targets: append([]string{}, policySlugs...), isDefault: true}
+}
+
+func (s *Server) sortedPolicies() []*victorops.EscalationPolicy {
+	policies := []*victorops.EscalationPolicy{}
+	for _, policy := range s.policies {
+		policies = append(policies, policy)
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		a, b := s.teams[policies[i].TeamID], s.teams[policies[j].TeamID]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return policies[i].Name < policies[j].Name
+	})
+	return policies
+}
+
+func (s *Server) listPolicies(r *request) (int, interface{}) {
+	list := victorops.EscalationPolicyList{Policies: []victorops.EscalationPolicyListElement{}}
+	for _, policy := range s.sortedPolicies() {
+		t := s.teams[policy.TeamID]
+		list.Policies = append(list.Policies, victorops.EscalationPolicyListElement{
+			Policy: victorops.EscalationPolicyListDetail{Name: policy.Name, Slug: policy.ID},
+			Team:   victorops.EscalationPolicyListDetail{Name: t.Name, Slug: t.Slug},
+		})
+	}
+	return http.StatusOK, list
+}
+
+func (s *Server) listTeamPolicies(r *request) (int, interface{}) {
+	t, found := s.team(r)
+	if !found {
+		return errorf(http.StatusNotFound, "Team %s not found", r.params[0])
+	}
+	list := victorops.TeamEscalationPolicyList{
+		Team:     victorops.EscalationPolicyListDetail{Name: t.Name, Slug: t.Slug},
+		Policies: []victorops.EscalationPolicyListDetail{},
+	}
+	for _, policy := range s.sortedPolicies() {
+		if policy.TeamID == t.Slug {
+			list.Policies = append(list.Policies, victorops.EscalationPolicyListDetail{Name: policy.Name, Slug: policy.ID})
+		}
+	}
+	return http.StatusOK, list
+}
+
+// checkPolicy validates the team and step targets of a policy, returning an error response
+// if they don't exist
+func (s *Server) checkPolicy(policy *victorops.EscalationPolicy) (int, interface{}) {
+	if policy.Name == "" {
+		return errorf(http.StatusBadRequest, "A policy name is required")
+	}
+	if _, found := s.teams[policy.TeamID]; !found {
+		return errorf(http.StatusBadRequest, "Team %s not found", policy.TeamID)
+	}
+	for _, step := range policy.Steps {
+		for _, entry := range step.Entries {
+			if entry.User != nil {
+				if _, found := s.users[entry.User.Username]; !found {
+					return errorf(http.StatusBadRequest, "User %s not found", entry.User.Username)
+				}
+			}
+			if entry.TargetPolicy != nil {
+				if _, found := s.policies[entry.TargetPolicy.PolicySlug]; !found {
+					return errorf(http.StatusBadRequest, "Escalation policy %s not found", entry.TargetPolicy.PolicySlug)
+				}
+			}
+		}
+	}
+	return 0, nil
+}
+
+func (s *Server) createPolicy(r *request) (int, interface{}) {
+	var policy victorops.EscalationPolicy
+	err := r.decode(&policy)
+	if err != nil {
+		return errorf(http.StatusBadRequest, "Invalid escalation policy: %v", err)
+	}
+	if status, body := s.checkPolicy(&policy); status != 0 {
+		return status, body
+	}
+
+	policy.ID = s.newID("pol")
+	s.policies[policy.ID] = &policy
+	return http.StatusOK, policy
+}
+
+func (s *Server) getPolicy(r *request) (int, interface{}) {
+	policy, found := s.policies[r.params[0]]
+	if !found {
+		return errorf(http.StatusNotFound, "Escalation policy %s not found", r.params[0])
+	}
+	return http.StatusOK, policy
+}
+
+func (s *Server) updatePolicy(r *request) (int, interface{}) {
+	if _, found := s.policies[r.params[0]]; !found {
+		return errorf(http.StatusNotFound, "Escalation policy %s not found", r.params[0])
+	}
+	var policy victorops.EscalationPolicy
+	err := r.decode(&policy)
+	if err != nil {
+		return errorf(http.StatusBadRequest, "Invalid escalation policy: %v", err)
+	}
+	if status, body := s.checkPolicy(&policy); status != 0 {
+		return status, body
+	}
+
+	policy.ID = r.params[0]
+	s.policies[policy.ID] = &policy
+	return http.StatusOK, policy
+}
+
+// removePolicy deletes a policy and the routing key targets pointing at it
+func (s *Server) removePolicy(slug string) {
+	delete(s.policies, slug)
+	for _, rk := range s.routingKeys {
+		rk.targets = without(rk.targets, slug)
+	}
+}
+
+func (s *Server) deletePolicy(r *request) (int, interface{}) {
+	if _, found := s.policies[r.params[0]]; !found {
+		return errorf(http.StatusNotFound, "Escalation policy %s not found", r.params[0])
+	}
+	s.removePolicy(r.params[0])
+	return http.StatusNoContent, nil
+}
+
+func (s *Server) listRoutingKeys(r *request) (int, interface{}) {
+	var keys []string
+	for key := range s.routingKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	list := victorops.RoutingKeyList{RoutingKeys: []victorops.RoutingKey{}}
+	for _, key := range keys {
+		rk := s.routingKeys[key]
+		response := victorops.RoutingKey{RoutingKey: key, IsDefault: rk.isDefault}
+		for _, slug := range rk.targets {
+			target := victorops.RoutingKeyTarget{PolicySlug: slug}
+			if policy, found := s.policies[slug]; found {
+				target.PolicyName = policy.Name
+				target.TeamURL = apiPrefix + "v1/team/" + policy.TeamID
+			}
+			response.Targets = append(response.Targets, target)
+		}
+		list.RoutingKeys = append(list.RoutingKeys, response)
+	}
+	return http.StatusOK, list
+}
+
+// checkTargets returns an error response if a targeted policy doesn't exist
+func (s *Server) checkTargets(targets []string) (int, interface{}) {
+	for _, slug := range targets {
+		if _, found := s.policies[slug]; !found {
+			return errorf(http.StatusBadRequest, "Escalation policy %s not found", slug)
+		}
+	}
+	return 0, nil
+}
+
+// routingKeyBody is the body of routing key create and update requests and responses,
+// which hold the targets as policy slugs
+type routingKeyBody struct {
+	RoutingKey string   `json:"routingKey,omitempty"`
+	Targets    []string `json:"targets,omitempty"`
+}
+
+func (s *Server) createRoutingKey(r *request) (int, interface{}) {
+	var body routingKeyBody
+	err := r.decode(&body)
+	if err != nil {
+		return errorf(http.StatusBadRequest, "Invalid routing key: %v", err)
+	}
+	if body.RoutingKey == "" {
+		return errorf(http.StatusBadRequest, "A routing key is required")
+	}
+	if _, found := s.routingKeys[body.RoutingKey]; found {
+		return errorf(http.StatusConflict, "Routing key %s already exists", body.RoutingKey)
+	}
+	if status, response := s.checkTargets(body.Targets); status != 0 {
+		return status, response
+	}
+
+	s.routingKeys[body.RoutingKey] = &routingKey{targets: append([]string{}, body.Targets...)}
+	return http.StatusOK, body
+}
+
+func (s *Server) updateRoutingKey(r *request) (int, interface{}) {
+	rk, found := s.routingKeys[r.params[0]]
+	if !found {
+		return errorf(http.StatusNotFound, "Routing key %s not found", r.params[0])
+	}
+	var body routingKeyBody
+	err := r.decode(&body)
+	if err != nil {
+		return errorf(http.StatusBadRequest, "Invalid routing key: %v", err)
+	}
+	if status, response := s.checkTargets(body.Targets); status != 0 {
+		return status, response
+	}
+
+	rk.targets = append([]string{}, body.Targets...)
+	return http.StatusOK, routingKeyBody{RoutingKey: r.params[0], Targets: rk.targets}
+}
+
+func (s *Server) deleteRoutingKey(r *request) (int, interface{}) {
+	rk, found := s.routingKeys[r.params[0]]
+	if !found {
+		return errorf(http.StatusNotFound, "Routing key %s not found", r.params[0])
+	}
+	if rk.isDefault {
+		return errorf(http.StatusBadRequest, "The default routing key can't be deleted")
+	}
+	delete(s.routingKeys, r.params[0])
+	return http.StatusNoContent, nil
+}
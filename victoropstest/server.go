@@ -0,0 +1,319 @@
+// Package victoropstest provides an in-memory fake of the VictorOps public API, so code
+// using the victorops client can be integration tested offline.
+//
+//	server := victoropstest.NewServer()
+//	defer server.Close()
+//	client := server.Client()
+//
+// The fake keeps the users, contact methods, teams, members, admins, escalation policies,
+// routing keys, incidents and on-call schedules of a single org. Requests must carry the
+// server's API ID and key. Incidents and schedules can't be created through the public
+// API, so they are added with AddIncident and SetSchedule.
+package victoropstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/victorops/go-victorops/victorops"
+)
+
+// The credentials NewServer accepts
+const (
+	APIID  = "test-api-id"
+	APIKey = "test-api-key"
+)
+
+const apiPrefix = "/api-public/"
+
+// Server is a fake VictorOps public API listening on a local address
+type Server struct {
+	*httptest.Server
+
+	// APIID and APIKey are the credentials requests must carry
+	APIID  string
+	APIKey string
+	// Now returns the time recorded on created users and incident transitions
+	Now func() time.Time
+
+	mu          sync.Mutex
+	nextID      int
+	requests    []string
+	faults      []*Fault
+	users       map[string]*victorops.User
+	contacts    map[string]map[string][]victorops.Contact
+	teams       map[string]*team
+	policies    map[string]*victorops.EscalationPolicy
+	routingKeys map[string]*routingKey
+	incidents   map[string]*victorops.Incident
+	schedules   map[string][]victorops.ApiEscalationPolicySchedule
+}
+
+// Fault makes the requests it matches slow, failing or both
+type Fault struct {
+	// Method and Path select the requests, where Path is a prefix of the path after
+	// /api-public/ such as "v1/team". Empty values match every request.
+	Method string
+	Path   string
+
+	// Latency delays the response
+	Latency time.Duration
+	// StatusCode, when set, is returned with Body instead of handling the request
+	StatusCode int
+	Body       string
+
+	// Times is how many requests the fault applies to, or every request when zero
+	Times int
+}
+
+func (f *Fault) matches(method string, path string) bool {
+	return (f.Method == "" || f.Method == method) && strings.HasPrefix(path, f.Path)
+}
+
+// NewServer starts a fake with an empty org, accepting the APIID and APIKey credentials
+func NewServer() *Server {
+	s := &Server{
+		APIID:       APIID,
+		APIKey:      APIKey,
+		Now:         time.Now,
+		users:       map[string]*victorops.User{},
+		contacts:    map[string]map[string][]victorops.Contact{},
+		teams:       map[string]*team{},
+		policies:    map[string]*victorops.EscalationPolicy{},
+		routingKeys: map[string]*routingKey{},
+		incidents:   map[string]*victorops.Incident{},
+		schedules:   map[string][]victorops.ApiEscalationPolicySchedule{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a client of the fake
+func (s *Server) Client() *victorops.Client {
+	return victorops.NewClient(s.APIID, s.APIKey, s.URL)
+}
+
+// InjectFault adds a fault, checked in the order they were added
+func (s *Server) InjectFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault)
+}
+
+// ClearFaults removes the injected faults
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests returns the requests received so far, such as "GET v1/team"
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.requests...)
+}
+
+// newID returns a slug or ID that hasn't been used yet, such as "team-3"
+func (s *Server) newID(prefix string) string {
+	s.nextID++
+	return fmt.Sprintf("%s-%d", prefix, s.nextID)
+}
+
+// request is an API call, with the wildcard path segments of the route it matched
+type request struct {
+	params []string
+	query  url.Values
+	body   []byte
+}
+
+// decode parses the JSON body of the request into v
+func (r *request) decode(v interface{}) error {
+	if len(r.body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(r.body, v)
+}
+
+// handler handles a request with the server locked, returning the status and the value
+// encoded as the response body
+type handler func(s *Server, r *request) (int, interface{})
+
+type route struct {
+	method  string
+	pattern []string
+	handle  handler
+}
+
+func newRoute(method string, pattern string, h handler) route {
+	return route{method: method, pattern: strings.Split(pattern, "/"), handle: h}
+}
+
+// routes maps the API endpoints to handlers, where * matches any path segment
+var routes = []route{
+	newRoute("GET", "v1/user", (*Server).listUsers),
+	newRoute("POST", "v1/user", (*Server).createUser),
+	newRoute("GET", "v2/user", (*Server).listUsersV2),
+	newRoute("GET", "v1/user/*", (*Server).getUser),
+	newRoute("PUT", "v1/user/*", (*Server).updateUser),
+	newRoute("DELETE", "v1/user/*", (*Server).deleteUser),
+	newRoute("GET", "v1/user/*/teams", (*Server).getUserTeams),
+	newRoute("GET", "v1/user/*/contact-methods", (*Server).listContacts),
+	newRoute("GET", "v1/user/*/contact-methods/*", (*Server).listContactsOfType),
+	newRoute("POST", "v1/user/*/contact-methods/*", (*Server).createContact),
+	newRoute("GET", "v1/user/*/contact-methods/*/*", (*Server).getContact),
+	newRoute("DELETE", "v1/user/*/contact-methods/*/*", (*Server).deleteContact),
+	newRoute("GET", "v2/user/*/oncall/schedule", (*Server).getUserSchedule),
+
+	newRoute("GET", "v1/team", (*Server).listTeams),
+	newRoute("POST", "v1/team", (*Server).createTeam),
+	newRoute("GET", "v1/team/*", (*Server).getTeam),
+	newRoute("PUT", "v1/team/*", (*Server).updateTeam),
+	newRoute("DELETE", "v1/team/*", (*Server).deleteTeam),
+	newRoute("GET", "v1/team/*/members", (*Server).getTeamMembers),
+	newRoute("POST", "v1/team/*/members", (*Server).addTeamMember),
+	newRoute("DELETE", "v1/team/*/members/*", (*Server).removeTeamMember),
+	newRoute("GET", "v1/team/*/admins", (*Server).getTeamAdmins),
+	newRoute("POST", "v1/team/*/admins", (*Server).addTeamAdmin),
+	newRoute("DELETE", "v1/team/*/admins/*", (*Server).removeTeamAdmin),
+	newRoute("GET", "v1/team/*/policies", (*Server).listTeamPolicies),
+	newRoute("GET", "v2/team/*/oncall/schedule", (*Server).getTeamSchedule),
+
+	newRoute("GET", "v1/policies", (*Server).listPolicies),
+	newRoute("POST", "v1/policies", (*Server).createPolicy),
+	newRoute("GET", "v1/policies/*", (*Server).getPolicy),
+	newRoute("PUT", "v1/policies/*", (*Server).updatePolicy),
+	newRoute("DELETE", "v1/policies/*", (*Server).deletePolicy),
+
+	newRoute("GET", "v1/org/routing-keys", (*Server).listRoutingKeys),
+	newRoute("POST", "v1/org/routing-keys", (*Server).createRoutingKey),
+	newRoute("PUT", "v1/org/routing-keys/*", (*Server).updateRoutingKey),
+	newRoute("DELETE", "v1/org/routing-keys/*", (*Server).deleteRoutingKey),
+
+	newRoute("GET", "v1/incidents", (*Server).listIncidents),
+	newRoute("GET", "v1/incidents/*", (*Server).getIncident),
+	newRoute("PATCH", "v1/incidents/ack", (*Server).ackIncidents),
+	newRoute("PATCH", "v1/incidents/resolve", (*Server).resolveIncidents),
+}
+
+func (rt route) match(method string, segments []string) ([]string, bool) {
+	if method != rt.method || len(segments) != len(rt.pattern) {
+		return nil, false
+	}
+	var params []string
+	for i, segment := range rt.pattern {
+		if segment == "*" {
+			params = append(params, segments[i])
+		} else if segment != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// errorf returns an error response in the format of the API
+func errorf(status int, format string, args ...interface{}) (int, interface{}) {
+	return status, apiError{Error: fmt.Sprintf(format, args...)}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, apiPrefix)
+	status, body := s.serve(r, path)
+	if status != http.StatusNoContent {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// encode returns the response body for a handler's value, which is written as is if it
+// is a string
+func encode(status int, body interface{}) (int, []byte) {
+	if body == nil {
+		return status, nil
+	}
+	if raw, ok := body.(string); ok {
+		return status, []byte(raw)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return http.StatusInternalServerError, []byte(err.Error())
+	}
+	return status, data
+}
+
+func (s *Server) serve(r *http.Request, path string) (int, []byte) {
+	s.mu.Lock()
+	call := r.Method + " " + path
+	if r.URL.RawQuery != "" {
+		call += "?" + r.URL.RawQuery
+	}
+	s.requests = append(s.requests, call)
+	var fault *Fault
+	for i, f := range s.faults {
+		if f.matches(r.Method, path) {
+			fault = f
+			if f.Times > 0 {
+				f.Times--
+				if f.Times == 0 {
+					s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+				}
+			}
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if fault != nil && fault.Latency > 0 {
+		select {
+		case <-time.After(fault.Latency):
+		case <-r.Context().Done():
+			return encode(errorf(http.StatusServiceUnavailable, "Request cancelled"))
+		}
+	}
+	if fault != nil && fault.StatusCode != 0 {
+		if fault.Body != "" {
+			return fault.StatusCode, []byte(fault.Body)
+		}
+		return encode(errorf(fault.StatusCode, "%s", http.StatusText(fault.StatusCode)))
+	}
+
+	if r.Header.Get("X-VO-Api-Id") != s.APIID || r.Header.Get("X-VO-Api-Key") != s.APIKey {
+		return encode(errorf(http.StatusUnauthorized, "Authentication failed"))
+	}
+	if !strings.HasPrefix(r.URL.Path, apiPrefix) {
+		return encode(errorf(http.StatusNotFound, "Not found"))
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return encode(errorf(http.StatusBadRequest, "%v", err))
+	}
+	if len(body) > 0 && !json.Valid(body) {
+		return encode(errorf(http.StatusBadRequest, "Invalid JSON body"))
+	}
+	segments := strings.Split(path, "/")
+	for _, rt := range routes {
+		params, ok := rt.match(r.Method, segments)
+		if !ok {
+			continue
+		}
+
+		// Responses are encoded before unlocking as they may point into the org
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return encode(rt.handle(s, &request{params: params, query: r.URL.Query(), body: body}))
+	}
+	return encode(errorf(http.StatusNotFound, "Not found"))
+}
@@ -0,0 +1,385 @@
+package victoropstest
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/victorops/go-victorops/reconcile"
+	"github.com/victorops/go-victorops/victorops"
+)
+
+func newTestServer(t *testing.T) (*Server, *victorops.Client) {
+	s := NewServer()
+	t.Cleanup(s.Close)
+	s.Now = func() time.Time { return time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC) }
+	return s, s.Client()
+}
+
+func checkStatus(t *testing.T, details *victorops.RequestDetails, err error, want int) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details.StatusCode != want {
+		t.Fatalf("API returned %d, want %d: %s", details.StatusCode, want, details.ResponseBody)
+	}
+}
+
+func createUser(t *testing.T, client *victorops.Client, username string) {
+	t.Helper()
+	_, details, err := client.CreateUser(&victorops.User{Username: username, FirstName: strings.ToUpper(username[:1]) + username[1:], Email: username + "@example.com"})
+	checkStatus(t, details, err, http.StatusOK)
+}
+
+func createTeam(t *testing.T, client *victorops.Client, name string, members ...string) string {
+	t.Helper()
+	team, details, err := client.CreateTeam(&victorops.Team{Name: name})
+	checkStatus(t, details, err, http.StatusOK)
+	for _, username := range members {
+		details, err := client.AddTeamMember(team.Slug, username)
+		checkStatus(t, details, err, http.StatusOK)
+	}
+	return team.Slug
+}
+
+func userPolicy(name string, teamSlug string, username string) *victorops.EscalationPolicy {
+	return &victorops.EscalationPolicy{Name: name, TeamID: teamSlug, Steps: []victorops.EscalationPolicySteps{
+		{Entries: []victorops.EscalationPolicyStepEntry{{ExecutionType: victorops.ExecutionTypeUser, User: &victorops.EscalationPolicyUserTarget{Username: username}}}},
+	}}
+}
+
+func TestUsersAndContacts(t *testing.T) {
+	_, client := newTestServer(t)
+	createUser(t, client, "bob")
+	createUser(t, client, "alice")
+
+	_, details, err := client.CreateUser(&victorops.User{Username: "alice", Email: "other@example.com"})
+	checkStatus(t, details, err, http.StatusConflict)
+
+	user, details, err := client.GetUser("alice")
+	checkStatus(t, details, err, http.StatusOK)
+	if user.Email != "alice@example.com" || user.CreatedAt != "2021-03-01T12:00:00Z" {
+		t.Errorf("unexpected user %+v", user)
+	}
+
+	user.LastName = "Smith"
+	_, details, err = client.UpdateUser(user)
+	checkStatus(t, details, err, http.StatusOK)
+	users, details, err := client.GetUserByEmail("alice@example.com")
+	checkStatus(t, details, err, http.StatusOK)
+	if len(users.Users) != 1 || users.Users[0].LastName != "Smith" {
+		t.Errorf("unexpected users %+v", users.Users)
+	}
+	all, _, _ := client.GetAllUserV2()
+	if len(all.Users) != 2 || all.Users[0].Username != "alice" {
+		t.Errorf("unexpected users %+v", all.Users)
+	}
+
+	phone, details, err := client.CreateContact("alice", &victorops.Contact{PhoneNumber: "+15555550100", Label: "Mobile"})
+	checkStatus(t, details, err, http.StatusOK)
+	contacts, details, err := client.GetAllContacts("alice")
+	checkStatus(t, details, err, http.StatusOK)
+	if len(contacts.Phones.ContactMethods) != 1 || contacts.Phones.ContactMethods[0].Value != "+15555550100" {
+		t.Errorf("unexpected phones %+v", contacts.Phones)
+	}
+	if len(contacts.Emails.ContactMethods) != 1 || contacts.Emails.ContactMethods[0].Value != "alice@example.com" {
+		t.Errorf("unexpected emails %+v", contacts.Emails)
+	}
+	id, details, err := client.GetUserDefaultEmailContactID("alice")
+	checkStatus(t, details, err, http.StatusOK)
+	if int(id) != contacts.Emails.ContactMethods[0].ID {
+		t.Errorf("default email contact is %v, want %d", id, contacts.Emails.ContactMethods[0].ID)
+	}
+
+	phoneType := victorops.GetContactTypes().Phone
+	got, details, err := client.GetContactByID("alice", phone.ID, phoneType)
+	checkStatus(t, details, err, http.StatusOK)
+	if got.ExtID != phone.ExtID {
+		t.Errorf("got contact %+v, want %+v", got, phone)
+	}
+	details, err = client.DeleteContact("alice", phone.ExtID, phoneType)
+	checkStatus(t, details, err, http.StatusNoContent)
+	_, details, err = client.GetContact("alice", phone.ExtID, phoneType)
+	checkStatus(t, details, err, http.StatusNotFound)
+
+	details, err = client.DeleteUser("alice", "bob")
+	checkStatus(t, details, err, http.StatusNoContent)
+	_, details, err = client.GetUser("alice")
+	checkStatus(t, details, err, http.StatusNotFound)
+}
+
+func TestTeams(t *testing.T) {
+	s, client := newTestServer(t)
+	createUser(t, client, "alice")
+	createUser(t, client, "bob")
+	ops := createTeam(t, client, "Ops", "alice", "bob")
+	dev := createTeam(t, client, "Dev", "bob")
+
+	details, err := client.AddTeamMember(ops, "carol")
+	checkStatus(t, details, err, http.StatusNotFound)
+	details, err = client.AddTeamAdmin(dev, "alice")
+	checkStatus(t, details, err, http.StatusBadRequest)
+	details, err = client.AddTeamAdmin(ops, "alice")
+	checkStatus(t, details, err, http.StatusOK)
+
+	admins, details, err := client.GetTeamAdmins(ops)
+	checkStatus(t, details, err, http.StatusOK)
+	if !reflect.DeepEqual(admins.Usernames(), []string{"alice"}) || admins.TeamAdmins[0].UserURL() != "/api-public/v1/user/alice" {
+		t.Errorf("unexpected admins %+v", admins)
+	}
+
+	teams, details, err := client.GetUserTeams("bob")
+	checkStatus(t, details, err, http.StatusOK)
+	if len(*teams) != 2 || (*teams)[0].Name != "Dev" || (*teams)[1].MemberCount != 2 {
+		t.Errorf("unexpected teams %+v", *teams)
+	}
+
+	details, err = client.RemoveTeamMember(ops, "alice", "bob")
+	checkStatus(t, details, err, http.StatusNoContent)
+	isMember, _, _ := client.IsTeamMember(ops, "alice")
+	admins, _, _ = client.GetTeamAdmins(ops)
+	if isMember || len(admins.TeamAdmins) != 0 {
+		t.Errorf("alice is still a member or admin of %s", ops)
+	}
+
+	// UpdateTeam addresses the team by name
+	updated, details, err := client.UpdateTeam(&victorops.Team{Name: "Dev"})
+	checkStatus(t, details, err, http.StatusOK)
+	if updated.Slug != dev || updated.Version != 2 {
+		t.Errorf("unexpected team %+v", updated)
+	}
+
+	if !s.SetDefaultTeam(ops) {
+		t.Fatal("failed to set the default team")
+	}
+	details, err = client.DeleteTeam(ops)
+	checkStatus(t, details, err, http.StatusBadRequest)
+	details, err = client.DeleteTeam(dev)
+	checkStatus(t, details, err, http.StatusNoContent)
+	all, _, _ := client.GetAllTeams()
+	if len(*all) != 1 || !(*all)[0].IsDefaultTeam {
+		t.Errorf("unexpected teams %+v", *all)
+	}
+}
+
+func TestPoliciesAndRoutingKeys(t *testing.T) {
+	s, client := newTestServer(t)
+	createUser(t, client, "alice")
+	ops := createTeam(t, client, "Ops", "alice")
+
+	_, details, err := client.CreateEscalationPolicy(userPolicy("Primary", ops, "carol"))
+	checkStatus(t, details, err, http.StatusBadRequest)
+	primary, details, err := client.CreateEscalationPolicy(userPolicy("Primary", ops, "alice"))
+	checkStatus(t, details, err, http.StatusOK)
+	secondary, details, err := client.CreateEscalationPolicy(userPolicy("Secondary", ops, "alice"))
+	checkStatus(t, details, err, http.StatusOK)
+
+	primary.Steps = append(primary.Steps, victorops.EscalationPolicySteps{Timeout: 15, Entries: []victorops.EscalationPolicyStepEntry{
+		{ExecutionType: victorops.ExecutionTypePolicyRouting, TargetPolicy: &victorops.EscalationPolicyPolicyTarget{PolicySlug: secondary.ID}},
+	}})
+	_, details, err = client.UpdateEscalationPolicy(primary)
+	checkStatus(t, details, err, http.StatusOK)
+	got, _, _ := client.GetEscalationPolicy(primary.ID)
+	if len(got.Steps) != 2 || got.ID != primary.ID {
+		t.Errorf("unexpected policy %+v", got)
+	}
+
+	policies, details, err := client.GetTeamEscalationPolicies(ops)
+	checkStatus(t, details, err, http.StatusOK)
+	if len(policies.Policies) != 2 || policies.Policies[0].Name != "Primary" || policies.Team.Name != "Ops" {
+		t.Errorf("unexpected policies %+v", policies)
+	}
+
+	_, details, err = client.CreateRoutingKey(victorops.NewRoutingKey("ops", []string{"pol-missing"}))
+	checkStatus(t, details, err, http.StatusBadRequest)
+	_, details, err = client.CreateRoutingKey(victorops.NewRoutingKey("ops", []string{primary.ID}))
+	checkStatus(t, details, err, http.StatusOK)
+	_, details, err = client.UpdateRoutingKeyTargets("ops", []string{primary.ID, secondary.ID})
+	checkStatus(t, details, err, http.StatusOK)
+	s.SetDefaultRoutingKey("default", secondary.ID)
+
+	key, _, _ := client.GetRoutingKey("ops")
+	if !reflect.DeepEqual(key.PolicySlugs(), []string{primary.ID, secondary.ID}) || key.Targets[0].PolicyName != "Primary" || key.Targets[0].TeamSlug() != ops {
+		t.Errorf("unexpected routing key %+v", key)
+	}
+
+	// Deleting a policy drops it from the routing keys targeting it
+	details, err = client.DeleteEscalationPolicy(secondary.ID)
+	checkStatus(t, details, err, http.StatusNoContent)
+	key, _, _ = client.GetRoutingKey("ops")
+	if !reflect.DeepEqual(key.PolicySlugs(), []string{primary.ID}) {
+		t.Errorf("routing key still targets %v", key.PolicySlugs())
+	}
+
+	details, err = client.DeleteRoutingKey("default")
+	checkStatus(t, details, err, http.StatusBadRequest)
+	details, err = client.DeleteRoutingKey("ops")
+	checkStatus(t, details, err, http.StatusNoContent)
+	keys, _, _ := client.GetAllRoutingKeys()
+	if len(keys.RoutingKeys) != 1 || !keys.RoutingKeys[0].IsDefault {
+		t.Errorf("unexpected routing keys %+v", keys.RoutingKeys)
+	}
+}
+
+func TestIncidents(t *testing.T) {
+	s, client := newTestServer(t)
+	createUser(t, client, "alice")
+	first := s.AddIncident(victorops.Incident{Service: "db down"})
+	s.AddIncident(victorops.Incident{Service: "disk full"})
+	if first.IncidentNumber != "1" || first.CurrentPhase != PhaseUnacked {
+		t.Errorf("unexpected incident %+v", first)
+	}
+
+	response, details, err := client.AckIncidents("alice", []int{1, 3}, "on it")
+	checkStatus(t, details, err, http.StatusOK)
+	want := []victorops.IncidentUpdateResult{
+		{IncidentNumber: "1", CmdAccepted: true, Message: "Incident ACKED"},
+		{IncidentNumber: "3", Message: "Incident not found"},
+	}
+	if !reflect.DeepEqual(response.Results, want) {
+		t.Errorf("got results %+v, want %+v", response.Results, want)
+	}
+
+	_, details, err = client.ResolveIncidents("alice", []int{1}, "")
+	checkStatus(t, details, err, http.StatusOK)
+	response, _, _ = client.AckIncidents("alice", []int{1}, "")
+	if response.Results[0].CmdAccepted {
+		t.Error("acknowledged a resolved incident")
+	}
+	_, details, err = client.AckIncidents("carol", []int{2}, "")
+	checkStatus(t, details, err, http.StatusBadRequest)
+
+	incident, details, err := client.GetIncident(1)
+	checkStatus(t, details, err, http.StatusOK)
+	var transitions []string
+	for _, transition := range incident.Transitions {
+		transitions = append(transitions, transition.Name+" by "+transition.By+": "+transition.Message)
+	}
+	if incident.CurrentPhase != PhaseResolved || !reflect.DeepEqual(transitions, []string{"ACKED by alice: on it", "RESOLVED by alice: "}) {
+		t.Errorf("incident is %s with transitions %q", incident.CurrentPhase, transitions)
+	}
+
+	incidents, _, _ := client.GetIncidents()
+	if len(incidents.Incidents) != 2 || incidents.Incidents[1].CurrentPhase != PhaseUnacked {
+		t.Errorf("unexpected incidents %+v", incidents.Incidents)
+	}
+}
+
+func TestSchedules(t *testing.T) {
+	s, client := newTestServer(t)
+	createUser(t, client, "alice")
+	createUser(t, client, "bob")
+	ops := createTeam(t, client, "Ops", "alice", "bob")
+	dev := createTeam(t, client, "Dev", "bob")
+	policy, _, _ := client.CreateEscalationPolicy(userPolicy("Primary", dev, "bob"))
+
+	s.SetSchedule(ops, victorops.ApiEscalationPolicySchedule{
+		Policy: victorops.ApiEscalationPolicy{Name: "Primary", Slug: "pol-ops"},
+		Schedule: []victorops.ApiOnCallEntry{
+			{OnCallUser: victorops.ApiUser{Username: "bob"}, OverrideOnCallUser: victorops.ApiUser{Username: "alice"}},
+		},
+	})
+
+	schedule, details, err := client.GetApiTeamSchedule(ops, 7, 0, 0)
+	checkStatus(t, details, err, http.StatusOK)
+	if schedule.Team.Name != "Ops" || len(schedule.Schedules) != 1 || schedule.Schedules[0].Schedule[0].OverrideOnCallUser.Username != "alice" {
+		t.Errorf("unexpected schedule %+v", schedule)
+	}
+
+	// Teams without a schedule have an empty one per policy
+	schedule, _, _ = client.GetApiTeamSchedule(dev, 0, 0, 0)
+	if len(schedule.Schedules) != 1 || schedule.Schedules[0].Policy.Slug != policy.ID || len(schedule.Schedules[0].Schedule) != 0 {
+		t.Errorf("unexpected schedule %+v", schedule)
+	}
+
+	userSchedule, details, err := client.GetUserOnCallSchedule("alice", 7, 0, 0)
+	checkStatus(t, details, err, http.StatusOK)
+	if len(userSchedule.Schedules) != 1 || userSchedule.Schedules[0].Team.Slug != ops {
+		t.Errorf("unexpected user schedule %+v", userSchedule)
+	}
+	_, details, err = client.GetApiTeamSchedule("team-missing", 0, 0, 0)
+	checkStatus(t, details, err, http.StatusNotFound)
+}
+
+func TestAuthAndFaults(t *testing.T) {
+	s, client := newTestServer(t)
+
+	_, details, err := victorops.NewClient(s.APIID, "wrong", s.URL).GetAllTeams()
+	if err == nil || details.StatusCode != http.StatusUnauthorized {
+		t.Errorf("got %v and %+v, want a 401", err, details)
+	}
+
+	s.InjectFault(Fault{Method: "GET", Path: "v1/team", StatusCode: http.StatusServiceUnavailable, Times: 1})
+	_, details, err = client.GetAllTeams()
+	if err == nil || details.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got %v and %+v, want a 503", err, details)
+	}
+	_, details, err = client.GetAllTeams()
+	checkStatus(t, details, err, http.StatusOK)
+
+	s.InjectFault(Fault{Path: "v1/incidents", StatusCode: http.StatusTooManyRequests, Body: `{"error":"slow down"}`})
+	_, details, err = client.GetIncidents()
+	checkStatus(t, details, err, http.StatusTooManyRequests)
+	if !strings.Contains(details.ResponseBody, "slow down") {
+		t.Errorf("unexpected body %s", details.ResponseBody)
+	}
+	s.ClearFaults()
+
+	s.InjectFault(Fault{Latency: time.Second})
+	impatient := victorops.NewConfigurableClient(s.APIID, s.APIKey, s.URL, http.Client{Timeout: 50 * time.Millisecond})
+	_, _, err = impatient.GetAllTeams()
+	if err == nil {
+		t.Error("expected the request to time out")
+	}
+	s.ClearFaults()
+
+	want := []string{"GET v1/team", "GET v1/team", "GET v1/team", "GET v1/incidents", "GET v1/team"}
+	if got := s.Requests(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got requests %q, want %q", got, want)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	_, client := newTestServer(t)
+	// The declared email contacts are the ones created along with the users
+	desired := reconcile.State{
+		Users: []reconcile.User{
+			{Username: "alice", FirstName: "Alice", Email: "alice@example.com", Contacts: []reconcile.Contact{
+				{Type: reconcile.ContactEmail, Value: "alice@example.com"},
+				{Type: reconcile.ContactPhone, Value: "+15555550100"},
+			}},
+			{Username: "bob", FirstName: "Bob", Email: "bob@example.com", Contacts: []reconcile.Contact{{Type: reconcile.ContactEmail, Value: "bob@example.com"}}},
+		},
+		Teams: []reconcile.Team{{Name: "Ops", Members: []string{"alice", "bob"}}},
+		EscalationPolicies: []reconcile.EscalationPolicy{
+			{Name: "Primary", Team: "Ops", Steps: []reconcile.Step{
+				{Entries: []reconcile.Entry{{Type: victorops.ExecutionTypeUser, User: "alice"}}},
+				{Timeout: 10, Entries: []reconcile.Entry{{Type: victorops.ExecutionTypePolicyRouting, Policy: &reconcile.PolicyRef{Team: "Ops", Name: "Secondary"}}}},
+			}},
+			{Name: "Secondary", Team: "Ops", Steps: []reconcile.Step{
+				{Entries: []reconcile.Entry{{Type: victorops.ExecutionTypeUser, User: "bob"}}},
+			}},
+		},
+		RoutingKeys: []reconcile.RoutingKey{{Name: "ops", Targets: []reconcile.PolicyRef{{Team: "Ops", Name: "Primary"}}}},
+	}
+
+	plan, _, err := reconcile.Reconcile(client, desired, reconcile.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.IsEmpty() {
+		t.Fatal("nothing was created")
+	}
+
+	// The fake keeps what was applied, so reconciling again has nothing to do
+	plan, _, err = reconcile.Reconcile(client, desired, reconcile.Options{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.IsEmpty() {
+		t.Errorf("second reconcile planned changes:\n%s", plan)
+	}
+}
@@ -0,0 +1,241 @@
+package victoropstest
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/victorops/go-victorops/victorops"
+)
+
+// team is a team with its members and admins, kept in the order they were added
+type team struct {
+	victorops.Team
+	members []string
+	admins  []string
+}
+
+func (t *team) apiTeam() victorops.Team {
+	apiTeam := t.Team
+	apiTeam.MemberCount = len(t.members)
+	return apiTeam
+}
+
+// SetDefaultTeam marks the team the org's users are added to by default, which can't be
+// deleted. It returns false if there is no such team.
+func (s *Server) SetDefaultTeam(slug string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, found := s.teams[slug]; !found {
+		return false
+	}
+	for _, t := range s.teams {
+		t.IsDefaultTeam = t.Slug == slug
+	}
+	return true
+}
+
+func (s *Server) sortedTeams() []*team {
+	teams := []*team{}
+	for _, t := range s.teams {
+		teams = append(teams, t)
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i].Name < teams[j].Name })
+	return teams
+}
+
+func (s *Server) listTeams(r *request) (int, interface{}) {
+	teams := []victorops.Team{}
+	for _, t := range s.sortedTeams() {
+		teams = append(teams, t.apiTeam())
+	}
+	return http.StatusOK, teams
+}
+
+func (s *Server) teamNamed(name string) *team {
+	for _, t := range s.teams {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+func (s *Server) createTeam(r *request) (int, interface{}) {
+	var body victorops.Team
+	err := r.decode(&body)
+	if err != nil {
+		return errorf(http.StatusBadRequest, "Invalid team: %v", err)
+	}
+	if body.Name == "" {
+		return errorf(http.StatusBadRequest, "A team name is required")
+	}
+	if s.teamNamed(body.Name) != nil {
+		return errorf(http.StatusConflict, "Team name %s is unavailable", body.Name)
+	}
+
+	t := &team{Team: victorops.Team{Name: body.Name, Slug: s.newID("team"), Version: 1}, members: []string{}, admins: []string{}}
+	s.teams[t.Slug] = t
+	return http.StatusOK, t.apiTeam()
+}
+
+// team returns the team named by the first path parameter. UpdateTeam addresses teams by
+// name rather than slug, so names are accepted too.
+func (s *Server) team(r *request) (*team, bool) {
+	if t, found := s.teams[r.params[0]]; found {
+		return t, true
+	}
+	t := s.teamNamed(r.params[0])
+	return t, t != nil
+}
+
+func (s *Server) getTeam(r *request) (int, interface{}) {
+	t, found := s.team(r)
+	if !found {
+		return errorf(http.StatusNotFound, "Team %s not found", r.params[0])
+	}
+	return http.StatusOK, t.apiTeam()
+}
+
+func (s *Server) updateTeam(r *request) (int, interface{}) {
+	t, found := s.team(r)
+	if !found {
+		return errorf(http.StatusNotFound, "Team %s not found", r.params[0])
+	}
+	var body victorops.Team
+	err := r.decode(&body)
+	if err != nil {
+		return errorf(http.StatusBadRequest, "Invalid team: %v", err)
+	}
+	if body.Name == "" {
+		return errorf(http.StatusBadRequest, "A team name is required")
+	}
+	if other := s.teamNamed(body.Name); other != nil && other != t {
+		return errorf(http.StatusConflict, "Team name %s is unavailable", body.Name)
+	}
+
+	t.Name = body.Name
+	t.Version++
+	return http.StatusOK, t.apiTeam()
+}
+
+func (s *Server) deleteTeam(r *request) (int, interface{}) {
+	t, found := s.team(r)
+	if !found {
+		return errorf(http.StatusNotFound, "Team %s not found", r.params[0])
+	}
+	if t.IsDefaultTeam {
+		return errorf(http.StatusBadRequest, "The default team can't be deleted")
+	}
+
+	for slug, policy := range s.policies {
+		if policy.TeamID == t.Slug {
+			s.removePolicy(slug)
+		}
+	}
+	delete(s.schedules, t.Slug)
+	delete(s.teams, t.Slug)
+	return http.StatusNoContent, nil
+}
+
+func (s *Server) getTeamMembers(r *request) (int, interface{}) {
+	t, found := s.team(r)
+	if !found {
+		return errorf(http.StatusNotFound, "Team %s not found", r.params[0])
+	}
+	members := victorops.TeamMembers{Members: []victorops.User{}}
+	for _, username := range t.members {
+		members.Members = append(members.Members, *s.users[username])
+	}
+	return http.StatusOK, members
+}
+
+func (s *Server) addTeamMember(r *request) (int, interface{}) {
+	t, found := s.team(r)
+	if !found {
+		return errorf(http.StatusNotFound, "Team %s not found", r.params[0])
+	}
+	var body struct {
+		Username string `json:"username"`
+	}
+	r.decode(&body)
+	if _, found := s.users[body.Username]; !found {
+		return errorf(http.StatusNotFound, "User %s not found", body.Username)
+	}
+
+	if !contains(t.members, body.Username) {
+		t.members = append(t.members, body.Username)
+	}
+	return http.StatusOK, map[string]string{}
+}
+
+func (s *Server) removeTeamMember(r *request) (int, interface{}) {
+	t, found := s.team(r)
+	if !found {
+		return errorf(http.StatusNotFound, "Team %s not found", r.params[0])
+	}
+	username := r.params[1]
+	if !contains(t.members, username) {
+		return errorf(http.StatusNotFound, "User %s is not a member of team %s", username, t.Slug)
+	}
+	var body struct {
+		Replacement string `json:"replacement"`
+	}
+	r.decode(&body)
+	if _, found := s.users[body.Replacement]; body.Replacement != "" && !found {
+		return errorf(http.StatusBadRequest, "Replacement user %s not found", body.Replacement)
+	}
+
+	t.members = without(t.members, username)
+	t.admins = without(t.admins, username)
+	return http.StatusNoContent, nil
+}
+
+func (s *Server) getTeamAdmins(r *request) (int, interface{}) {
+	t, found := s.team(r)
+	if !found {
+		return errorf(http.StatusNotFound, "Team %s not found", r.params[0])
+	}
+	admins := []victorops.Admin{}
+	for _, username := range t.admins {
+		user := s.users[username]
+		admins = append(admins, victorops.Admin{
+			Username:  user.Username,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			SelfUrl:   apiPrefix + "v1/user/" + url.QueryEscape(user.Username),
+		})
+	}
+	return http.StatusOK, map[string][]victorops.Admin{"teamAdmins": admins}
+}
+
+func (s *Server) addTeamAdmin(r *request) (int, interface{}) {
+	t, found := s.team(r)
+	if !found {
+		return errorf(http.StatusNotFound, "Team %s not found", r.params[0])
+	}
+	var body struct {
+		Username string `json:"username"`
+	}
+	r.decode(&body)
+	if !contains(t.members, body.Username) {
+		return errorf(http.StatusBadRequest, "User %s is not a member of team %s", body.Username, t.Slug)
+	}
+
+	if !contains(t.admins, body.Username) {
+		t.admins = append(t.admins, body.Username)
+	}
+	return http.StatusOK, map[string]string{}
+}
+
+func (s *Server) removeTeamAdmin(r *request) (int, interface{}) {
+	t, found := s.team(r)
+	if !found {
+		return errorf(http.StatusNotFound, "Team %s not found", r.params[0])
+	}
+	if !contains(t.admins, r.params[1]) {
+		return errorf(http.StatusNotFound, "User %s is not an admin of team %s", r.params[1], t.Slug)
+	}
+	t.admins = without(t.admins, r.params[1])
+	return http.StatusNoContent, nil
+}
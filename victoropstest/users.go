@@ -0,0 +1,257 @@
+package victoropstest
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/victorops/go-victorops/victorops"
+)
+
+// The contact method types, as named in the API paths
+var contactTypes = []string{"phones", "emails", "devices"}
+
+func (s *Server) sortedUsers() []victorops.User {
+	users := []victorops.User{}
+	for _, user := range s.users {
+		users = append(users, *user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+	return users
+}
+
+func (s *Server) listUsers(r *request) (int, interface{}) {
+	return http.StatusOK, victorops.UserList{Users: [][]victorops.User{s.sortedUsers()}}
+}
+
+func (s *Server) listUsersV2(r *request) (int, interface{}) {
+	users := []victorops.User{}
+	email := r.query.Get("email")
+	for _, user := range s.sortedUsers() {
+		if email == "" || user.Email == email {
+			users = append(users, user)
+		}
+	}
+	return http.StatusOK, victorops.UserListV2{Users: users}
+}
+
+func (s *Server) createUser(r *request) (int, interface{}) {
+	var user victorops.User
+	err := r.decode(&user)
+	if err != nil {
+		return errorf(http.StatusBadRequest, "Invalid user: %v", err)
+	}
+	if user.Username == "" || user.Email == "" {
+		return errorf(http.StatusBadRequest, "A username and email are required")
+	}
+	if _, found := s.users[user.Username]; found {
+		return errorf(http.StatusConflict, "Username %s is unavailable", user.Username)
+	}
+
+	user.CreatedAt = s.Now().UTC().Format(time.RFC3339)
+	user.PasswordLastUpdated = user.CreatedAt
+	user.Verified = false
+	s.users[user.Username] = &user
+
+	// The email the user was created with becomes their first email contact
+	id := s.newID("contact")
+	s.contacts[user.Username] = map[string][]victorops.Contact{
+		"emails": {{Value: user.Email, Label: "Default", ExtID: id, ID: s.nextID}},
+	}
+	return http.StatusOK, user
+}
+
+// user returns the user named by the first path parameter
+func (s *Server) user(r *request) (*victorops.User, bool) {
+	user, found := s.users[r.params[0]]
+	return user, found
+}
+
+func (s *Server) getUser(r *request) (int, interface{}) {
+	user, found := s.user(r)
+	if !found {
+		return errorf(http.StatusNotFound, "User %s not found", r.params[0])
+	}
+	return http.StatusOK, user
+}
+
+func (s *Server) updateUser(r *request) (int, interface{}) {
+	user, found := s.user(r)
+	if !found {
+		return errorf(http.StatusNotFound, "User %s not found", r.params[0])
+	}
+	var update victorops.User
+	err := r.decode(&update)
+	if err != nil {
+		return errorf(http.StatusBadRequest, "Invalid user: %v", err)
+	}
+
+	user.FirstName = update.FirstName
+	user.LastName = update.LastName
+	if update.Email != "" {
+		user.Email = update.Email
+	}
+	user.Admin = update.Admin
+	return http.StatusOK, user
+}
+
+func (s *Server) deleteUser(r *request) (int, interface{}) {
+	username := r.params[0]
+	if _, found := s.users[username]; !found {
+		return errorf(http.StatusNotFound, "User %s not found", username)
+	}
+	var body struct {
+		Replacement string `json:"replacement"`
+	}
+	r.decode(&body)
+	if _, found := s.users[body.Replacement]; body.Replacement != "" && !found {
+		return errorf(http.StatusBadRequest, "Replacement user %s not found", body.Replacement)
+	}
+
+	delete(s.users, username)
+	delete(s.contacts, username)
+	for _, t := range s.teams {
+		t.members = without(t.members, username)
+		t.admins = without(t.admins, username)
+	}
+	return http.StatusNoContent, nil
+}
+
+func (s *Server) getUserTeams(r *request) (int, interface{}) {
+	if _, found := s.user(r); !found {
+		return errorf(http.StatusNotFound, "User %s not found", r.params[0])
+	}
+	teams := []victorops.Team{}
+	for _, t := range s.sortedTeams() {
+		if contains(t.members, r.params[0]) {
+			teams = append(teams, t.apiTeam())
+		}
+	}
+	return http.StatusOK, map[string][]victorops.Team{"teams": teams}
+}
+
+// contactType returns the contact type named by the second path parameter
+func contactType(r *request) (string, bool) {
+	for _, t := range contactTypes {
+		if r.params[1] == t {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+func (s *Server) listContacts(r *request) (int, interface{}) {
+	if _, found := s.user(r); !found {
+		return errorf(http.StatusNotFound, "User %s not found", r.params[0])
+	}
+	contacts := s.contacts[r.params[0]]
+	group := func(t string) victorops.ContactGroup {
+		return victorops.ContactGroup{ContactMethods: append([]victorops.Contact{}, contacts[t]...)}
+	}
+	return http.StatusOK, victorops.AllContactResponse{Phones: group("phones"), Emails: group("emails"), Devices: group("devices")}
+}
+
+func (s *Server) listContactsOfType(r *request) (int, interface{}) {
+	t, ok := contactType(r)
+	if !ok {
+		return errorf(http.StatusNotFound, "Unknown contact type %s", r.params[1])
+	}
+	if _, found := s.user(r); !found {
+		return errorf(http.StatusNotFound, "User %s not found", r.params[0])
+	}
+	return http.StatusOK, victorops.GetAllContactResponse{ContactMethods: append([]victorops.Contact{}, s.contacts[r.params[0]][t]...)}
+}
+
+func (s *Server) createContact(r *request) (int, interface{}) {
+	t, ok := contactType(r)
+	if !ok || t == "devices" {
+		return errorf(http.StatusBadRequest, "Contact methods of type %s can't be created", r.params[1])
+	}
+	username := r.params[0]
+	if _, found := s.user(r); !found {
+		return errorf(http.StatusNotFound, "User %s not found", username)
+	}
+	var contact victorops.Contact
+	err := r.decode(&contact)
+	if err != nil {
+		return errorf(http.StatusBadRequest, "Invalid contact method: %v", err)
+	}
+
+	value := contact.Email
+	if t == "phones" {
+		value = contact.PhoneNumber
+	}
+	if value == "" {
+		return errorf(http.StatusBadRequest, "A value is required")
+	}
+	for _, existing := range s.contacts[username][t] {
+		if existing.Value == value {
+			return errorf(http.StatusConflict, "Contact method %s already exists", value)
+		}
+	}
+
+	created := victorops.Contact{
+		Label: contact.Label,
+		Rank:  contact.Rank,
+		ExtID: s.newID("contact"),
+		ID:    s.nextID,
+		Value: value,
+	}
+	if s.contacts[username] == nil {
+		s.contacts[username] = map[string][]victorops.Contact{}
+	}
+	s.contacts[username][t] = append(s.contacts[username][t], created)
+	return http.StatusOK, created
+}
+
+// contact returns the index of the contact method named by the path parameters
+func (s *Server) contact(r *request) (string, int, bool) {
+	t, ok := contactType(r)
+	if !ok {
+		return "", 0, false
+	}
+	for i, contact := range s.contacts[r.params[0]][t] {
+		if contact.ExtID == r.params[2] || strconv.Itoa(contact.ID) == r.params[2] {
+			return t, i, true
+		}
+	}
+	return "", 0, false
+}
+
+func (s *Server) getContact(r *request) (int, interface{}) {
+	t, i, found := s.contact(r)
+	if !found {
+		return errorf(http.StatusNotFound, "Contact method %s not found", r.params[2])
+	}
+	return http.StatusOK, s.contacts[r.params[0]][t][i]
+}
+
+func (s *Server) deleteContact(r *request) (int, interface{}) {
+	t, i, found := s.contact(r)
+	if !found {
+		return errorf(http.StatusNotFound, "Contact method %s not found", r.params[2])
+	}
+	contacts := s.contacts[r.params[0]][t]
+	s.contacts[r.params[0]][t] = append(contacts[:i:i], contacts[i+1:]...)
+	return http.StatusNoContent, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func without(values []string, value string) []string {
+	kept := []string{}
+	for _, v := range values {
+		if v != value {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}